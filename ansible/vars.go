@@ -18,6 +18,8 @@ import (
 
 // General Vars
 type GeneralVars struct {
+  Distribution string                     `yaml:"kube_distribution,omitempty"`
+  DistributionRelease string              `yaml:"kube_distribution_release,omitempty"`
   KubeVersion string                      `yaml:"kube_kube_version,omitempty"`
   HaCluster bool                          `yaml:"kube_ha_cluster,omitempty"`
   IsLeadNode bool                         `yaml:"kube_is_lead_control_plane_node,omitempty"`
@@ -29,6 +31,7 @@ type GeneralVars struct {
   StorageController string                `yaml:"kube_storage_controller,omitempty"`
   DisableDefaultMetrics bool              `yaml:"kube_disable_default_metrics,omitempty"`
   UseIps bool                             `yaml:"kube_use_ips,omitempty"`
+  BindNetwork string                      `yaml:"kube_bind_network,omitempty"`
 } 
 
 // control node type
@@ -62,6 +65,26 @@ type CalicoVars struct {
 type LonghornVars struct {
   Version string                    `yaml:"kube_longhorn_version,omitempty"`
   Install bool                      `yaml:"kube_install_longhorn,omitempty"`
+  DataPath string                   `yaml:"kube_longhorn_data_path,omitempty"`
+}
+
+// Node labels and taints, keyed by the node hostname
+type NodeVars struct {
+  NodeLabels map[string]map[string]string   `yaml:"kube_node_labels,omitempty"`
+  NodeTaints map[string][]string            `yaml:"kube_node_taints,omitempty"`
+}
+
+// Extra k3s arguments for the machines of the node type,
+// keyed by the node hostname
+type K3sArgsVars struct {
+  ExtraArgs map[string][]string     `yaml:"kube_k3s_extra_args,omitempty"`
+}
+
+// Airgap, k3s is staged from the offline bundle so the
+// install script is run without downloading anything
+type AirgapVars struct {
+  Install bool                      `yaml:"kube_airgap_install,omitempty"`
+  InstallScript string              `yaml:"kube_airgap_install_script,omitempty"`
 }
 
 // Merged variables that will hold all variables
@@ -72,10 +95,13 @@ type MergedVars struct {
   CiliumVars      `yaml:",inline,omitempty"`
   CalicoVars      `yaml:",inline,omitempty"`
   LonghornVars    `yaml:",inline,omitempty"`
+  NodeVars        `yaml:",inline,omitempty"`
+  K3sArgsVars     `yaml:",inline,omitempty"`
+  AirgapVars      `yaml:",inline,omitempty"`
 }
 
 func GenerateVarsFile(appDir string, clusterName string, clusterType string, 
-  nodeType string, appSettings settings.Settings) error {
+  nodeType string, appSettings settings.Settings, offline bool) error {
 
   varsFilePath := filepath.Join(appDir, clusterName, "ansible", "variables")
   
@@ -114,6 +140,12 @@ func GenerateVarsFile(appDir string, clusterName string, clusterType string,
   calicoVars := getCalicoVars(features)
   logger.LogDebug("Getting longhorn variables")
   longhornVars := getLonghornVars(features)
+  logger.LogDebug("Getting node label and taint variables")
+  nodeVars := getNodeVars(appSettings.Clusters[clusterName])
+  logger.LogDebug("Getting k3s argument variables")
+  k3sArgsVars := getK3sArgsVars(appSettings.Clusters[clusterName], nodeType)
+  logger.LogDebug("Getting airgap variables")
+  airgapVars := getAirgapVars(offline)
 
   logger.LogDebug("Merging all variables for marshaling to file")
   vars := MergedVars{
@@ -122,6 +154,9 @@ func GenerateVarsFile(appDir string, clusterName string, clusterType string,
     CiliumVars: ciliumVars,
     CalicoVars: calicoVars,
     LonghornVars: longhornVars,
+    NodeVars: nodeVars,
+    K3sArgsVars: k3sArgsVars,
+    AirgapVars: airgapVars,
   }
 
   yamlData, err := yaml.Marshal(&vars)
@@ -181,6 +216,9 @@ func getGeneralVars(appSettings settings.Settings, haCluster bool,
   }
 
   logger.LogDebug("Generating general variables")
+  distribution := features.GetDistribution()
+  vars.Distribution = distribution.Name
+  vars.DistributionRelease = getDistributionRelease(distribution, features.KubeVersion)
   vars.KubeVersion = features.KubeVersion
   vars.HaCluster = haCluster
   vars.IsLeadNode = isLeadNode
@@ -192,10 +230,27 @@ func getGeneralVars(appSettings settings.Settings, haCluster bool,
   vars.StorageController = features.StorageController
   vars.DisableDefaultMetrics = features.DisableDefaultMetrics
   vars.UseIps = useIps
+  vars.BindNetwork = appSettings.Clusters[clusterName].GetBindNetworkCidr()
 
   return vars
 }
 
+// Gets the distribution release for the kube version from the version
+// catalog, empty if the kube version is not in the catalog
+func getDistributionRelease(distribution settings.DistributionDefinition, kubeVersion string) string {
+  catalog, err := settings.GetVersionCatalog()
+  if err != nil {
+    return ""
+  }
+
+  compatibility, known := catalog.GetKubeVersion(kubeVersion)
+  if !known {
+    logger.LogDebug("Kube version is not in the version catalog, no distribution release", "version", kubeVersion)
+    return ""
+  }
+  return distribution.GetRelease(compatibility)
+}
+
 // Gets KubeVip vars if kubevip is not set it will return empty
 func getKubeVipVars(features settings.ClusterFeatures, vip string) KubeVipVars {
   var vars KubeVipVars 
@@ -259,10 +314,74 @@ func getLonghornVars(features settings.ClusterFeatures) LonghornVars {
   logger.LogDebug("StorageController is Longhorn, setting the longhorn settings")
   vars.Version = features.StorageControllerVersion
   vars.Install = features.ManagedStorageController
+  vars.DataPath = features.StorageDataPath
 
   return vars
 }
  
+// Gets the labels and taints for every node in the cluster, nodes
+// without any are left out
+func getNodeVars(cluster settings.Cluster) NodeVars {
+  var vars NodeVars
+
+  machines := append(append([]settings.Machine{}, cluster.Leaders...), cluster.Workers...)
+  for _, machine := range machines {
+    if len(machine.Labels) > 0 {
+      if vars.NodeLabels == nil {
+        vars.NodeLabels = make(map[string]map[string]string)
+      }
+      logger.LogDebug("Adding node labels", "node", machine.Name, "labels", machine.Labels)
+      vars.NodeLabels[machine.Name] = machine.Labels
+    }
+
+    if len(machine.Taints) > 0 {
+      if vars.NodeTaints == nil {
+        vars.NodeTaints = make(map[string][]string)
+      }
+      logger.LogDebug("Adding node taints", "node", machine.Name, "taints", machine.Taints)
+      vars.NodeTaints[machine.Name] = machine.Taints
+    }
+  }
+  return vars
+}
+ 
+/*
+  Gets the extra k3s arguments for each machine of a node type (the lead
+  node, control nodes, workers or the single node), machines with no
+  extra arguments are left out
+*/
+func getK3sArgsVars(cluster settings.Cluster, nodeType string) K3sArgsVars {
+  var vars K3sArgsVars
+  var machines []settings.Machine
+  server := true
+
+  switch nodeType {
+  case "lead":
+    machines = cluster.Leaders[:1]
+  case "control":
+    machines = cluster.Leaders[1:]
+  case "worker":
+    machines = cluster.Workers
+    server = false
+  default:
+    machines = cluster.Leaders
+  }
+
+  for _, machine := range machines {
+    args := cluster.GetMachineK3sArgs(machine, server)
+    if len(args) == 0 {
+      continue
+    }
+
+    if vars.ExtraArgs == nil {
+      vars.ExtraArgs = make(map[string][]string)
+    }
+    logger.LogDebug("Adding extra k3s args", "node", machine.Name, "args", args)
+    vars.ExtraArgs[machine.Name] = args
+  }
+  return vars
+}
+
 /*
   Gets a list of all the control node Ip addresses and the vip if
   kubevip is enabled, this is needed to add the TLS san setting in k3s
@@ -318,3 +437,19 @@ func GetControlPlaneList(leadNodes []settings.Machine, clusterType string) map[s
 
 
 
+
+// Gets airgap vars if the cluster is not offline it will return empty
+func getAirgapVars(offline bool) AirgapVars {
+  var vars AirgapVars
+
+  if !offline {
+    logger.LogDebug("Cluster is not offline, not setting airgap settings")
+    return vars
+  }
+
+  logger.LogDebug("Cluster is offline, setting airgap settings")
+  vars.Install = true
+  vars.InstallScript = "/usr/local/bin/k3s-install.sh"
+
+  return vars
+}
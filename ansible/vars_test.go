@@ -41,6 +41,56 @@ func TestGetGeneralVars(t *testing.T) {
 
 }
 
+func TestGetGeneralVarsBindNetwork(t *testing.T) {
+  appSettings := settings.Settings{
+    Clusters: map[string]settings.Cluster{
+      "dev": {
+        ClusterType: "single",
+        Leaders: []settings.Machine{
+          {Name: "dev", IpAddress: "192.168.1.10", Networks: []settings.Network{
+            {Name: "cluster", IpAddress: "192.168.20.10"},
+          }},
+        },
+        ClusterFeatures: &settings.ClusterFeatures{BindNetwork: "cluster"},
+      },
+    },
+  }
+
+  actual := getGeneralVars(appSettings, false, true, "", "dev")
+  assert.Equal(t, "192.168.20.0/24", actual.BindNetwork)
+
+  appSettings.Clusters["dev"].ClusterFeatures.BindNetwork = ""
+  actual = getGeneralVars(appSettings, false, true, "", "dev")
+  assert.Empty(t, actual.BindNetwork)
+}
+
+func TestGetGeneralVarsDistribution(t *testing.T) {
+  appSettings := settings.Settings{
+    Clusters: map[string]settings.Cluster{
+      "dev": {
+        ClusterType: "single",
+        Leaders: []settings.Machine{{Name: "dev", IpAddress: "192.168.1.10"}},
+        ClusterFeatures: &settings.ClusterFeatures{Distribution: "rke2", KubeVersion: "1.31.4"},
+      },
+    },
+  }
+
+  actual := getGeneralVars(appSettings, false, true, "", "dev")
+  assert.Equal(t, "rke2", actual.Distribution)
+  assert.Equal(t, "v1.31.4+rke2r1", actual.DistributionRelease)
+
+  appSettings.Clusters["dev"].ClusterFeatures.Distribution = ""
+  appSettings.Clusters["dev"].ClusterFeatures.KubeVersion = "1.20.0"
+  actual = getGeneralVars(appSettings, false, true, "", "dev")
+  assert.Equal(t, "k3s", actual.Distribution)
+  assert.Empty(t, actual.DistributionRelease)
+
+  // an unknown patch release does not get the release of another patch release
+  appSettings.Clusters["dev"].ClusterFeatures.KubeVersion = "1.31.1"
+  actual = getGeneralVars(appSettings, false, true, "", "dev")
+  assert.Empty(t, actual.DistributionRelease)
+}
+
 /*
       Tests for getKubeVipVars
 */
@@ -165,6 +215,23 @@ func TestGetLonghornVarsEnabled(t *testing.T) {
   assert.Equal(t, actual, expected)
 }
 
+func TestGetLonghornVarsDataPath(t *testing.T) {
+  features := settings.ClusterFeatures{
+    StorageController: "longhorn",
+    StorageControllerVersion: "0.5.0",
+    StorageDataPath: "/var/lib/longhorn",
+  }
+
+  expected := LonghornVars{
+    Version: "0.5.0",
+    DataPath: "/var/lib/longhorn",
+  }
+
+  actual := getLonghornVars(features)
+
+  assert.Equal(t, actual, expected)
+}
+
 func TestGetLonghornVarsNotEnabled(t *testing.T) {
   features := settings.ClusterFeatures{
     StorageController: "local-storage",
@@ -177,6 +244,106 @@ func TestGetLonghornVarsNotEnabled(t *testing.T) {
   assert.Equal(t, actual, expected)
 }
 
+/*
+      Tests for getNodeVars
+*/
+func TestGetNodeVars(t *testing.T) {
+  cluster := settings.Cluster{
+    Leaders: leadNodes,
+    Workers: []settings.Machine{
+      {Name: "gpu01", Labels: map[string]string{"gpu": "true"}, Taints: []string{"gpu=true:NoSchedule"}},
+      {Name: "worker01"},
+    },
+  }
+
+  expected := NodeVars{
+    NodeLabels: map[string]map[string]string{
+      "gpu01": {"gpu": "true"},
+    },
+    NodeTaints: map[string][]string{
+      "gpu01": {"gpu=true:NoSchedule"},
+    },
+  }
+
+  actual := getNodeVars(cluster)
+
+  assert.Equal(t, actual, expected)
+}
+
+func TestGetNodeVarsNone(t *testing.T) {
+  cluster := settings.Cluster{
+    Leaders: leadNodes,
+  }
+
+  actual := getNodeVars(cluster)
+
+  assert.Equal(t, actual, NodeVars{})
+}
+
+/*
+      Tests for getK3sArgsVars
+*/
+func TestGetK3sArgsVars(t *testing.T) {
+  leaders := append([]settings.Machine{}, leadNodes...)
+  leaders[2].K3sArgs = settings.K3sArgs{DisableComponents: []string{"metrics-server"}}
+
+  cluster := settings.Cluster{
+    Leaders: leaders,
+    Workers: []settings.Machine{
+      {Name: "worker01", IpAddress: "192.168.1.4"},
+      {Name: "worker02", IpAddress: "192.168.1.5", K3sArgs: settings.K3sArgs{AgentArgs: []string{"--node-ip=192.168.1.5"}}},
+    },
+    ClusterFeatures: &settings.ClusterFeatures{
+      K3sArgs: settings.K3sArgs{
+        ServerArgs: []string{"--secrets-encryption"},
+        DisableComponents: []string{"servicelb"},
+      },
+    },
+  }
+
+  assert.Equal(t, K3sArgsVars{ExtraArgs: map[string][]string{
+    "lead01": {"--secrets-encryption", "--disable=servicelb"},
+  }}, getK3sArgsVars(cluster, "lead"))
+
+  assert.Equal(t, K3sArgsVars{ExtraArgs: map[string][]string{
+    "lead02": {"--secrets-encryption", "--disable=servicelb"},
+    "lead03": {"--secrets-encryption", "--disable=metrics-server"},
+  }}, getK3sArgsVars(cluster, "control"))
+
+  // workers without agent or kubelet args are left out
+  assert.Equal(t, K3sArgsVars{ExtraArgs: map[string][]string{
+    "worker02": {"--node-ip=192.168.1.5"},
+  }}, getK3sArgsVars(cluster, "worker"))
+}
+
+func TestGetK3sArgsVarsNone(t *testing.T) {
+  cluster := settings.Cluster{
+    Leaders: leadNodes[:1],
+    ClusterFeatures: &settings.ClusterFeatures{},
+  }
+
+  assert.Equal(t, K3sArgsVars{}, getK3sArgsVars(cluster, ""))
+}
+
+/*
+      Tests for getAirgapVars
+*/
+func TestGetAirgapVarsOffline(t *testing.T) {
+  actual := getAirgapVars(true)
+
+  expected := AirgapVars{
+    Install: true,
+    InstallScript: "/usr/local/bin/k3s-install.sh",
+  }
+
+  assert.Equal(t, actual, expected)
+}
+
+func TestGetAirgapVarsOnline(t *testing.T) {
+  actual := getAirgapVars(false)
+
+  assert.Equal(t, actual, AirgapVars{})
+}
 
 /*
       Tests for GetTlsSanList
@@ -0,0 +1,320 @@
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/settings"
+)
+
+/*
+These are functions for the offline bundle, everything the machines
+would otherwise download during provisioning (k3s, the k3s airgap
+images, yq, the ansible python wheels and ansible collections) is
+collected into the bundle directory in the app cache and synced into
+the machines at /bundle for clusters brought up with --offline
+*/
+
+// the bundle directory relative to the app directory
+var bundleDir = filepath.Join("cache", "bundle")
+
+// the path the bundle is synced to on the machines
+const MachineBundleDir = "/bundle"
+
+// the machines are all amd64
+const bundleArch = "amd64"
+
+// versions of the tools in the bundle
+const yqVersion = "v4.44.6"
+const kubernetesCollectionVersion = "5.0.0"
+
+// python packages bootstrap.sh installs (along with ansible-core)
+var pythonPackages = []string{
+  "pip",
+  "python-debian",
+  "kubernetes",
+  "pyyaml",
+}
+
+// platforms to download python wheels for
+var pythonPlatforms = []string{
+  "manylinux2014_x86_64",
+  "manylinux_2_28_x86_64",
+  "manylinux_2_34_x86_64",
+}
+
+// base urls for downloads, these are vars so tests can point them at a test server
+var k3sReleaseUrl = "https://github.com/k3s-io/k3s/releases/download"
+var k3sInstallScriptUrl = "https://raw.githubusercontent.com/k3s-io/k3s"
+var yqReleaseUrl = "https://github.com/mikefarah/yq/releases/download"
+var galaxyDownloadUrl = "https://galaxy.ansible.com/download"
+
+// the command used to download python wheels
+var pipCommand = "pip3"
+
+/*
+  Artifact - A file in the bundle
+*/
+type Artifact struct {
+  Name string     // a display name for the artifact
+  Url string      // where the artifact is downloaded from
+  Path string     // the path relative to the bundle directory
+}
+
+/*
+GetBundleDir - Gets the path of the bundle directory
+*/
+func GetBundleDir(appDir string) string {
+  return filepath.Join(appDir, bundleDir)
+}
+
+/*
+GetK3sArtifacts - Gets the k3s artifacts for a k3s release, the
+binary, airgap images and the install script
+*/
+func GetK3sArtifacts(k3sRelease string) []Artifact {
+  releaseUrl := fmt.Sprintf("%s/%s", k3sReleaseUrl, url.PathEscape(k3sRelease))
+  releaseDir := filepath.Join("k3s", k3sRelease)
+  imagesName := fmt.Sprintf("k3s-airgap-images-%s.tar.zst", bundleArch)
+
+  return []Artifact{
+    {Name: "k3s " + k3sRelease, Url: releaseUrl + "/k3s", Path: filepath.Join(releaseDir, "k3s")},
+    {Name: "k3s airgap images " + k3sRelease, Url: releaseUrl + "/" + imagesName, Path: filepath.Join(releaseDir, imagesName)},
+    {Name: "k3s install script " + k3sRelease,
+      Url: fmt.Sprintf("%s/%s/install.sh", k3sInstallScriptUrl, url.PathEscape(k3sRelease)),
+      Path: filepath.Join(releaseDir, "install.sh")},
+  }
+}
+
+/*
+GetToolArtifacts - Gets the artifacts that don't depend on the
+cluster versions, yq and the ansible collections
+*/
+func GetToolArtifacts() []Artifact {
+  collectionName := fmt.Sprintf("kubernetes-core-%s.tar.gz", kubernetesCollectionVersion)
+
+  return []Artifact{
+    {Name: "yq " + yqVersion,
+      Url: fmt.Sprintf("%s/%s/yq_linux_%s", yqReleaseUrl, yqVersion, bundleArch),
+      Path: filepath.Join("yq", "yq")},
+    {Name: "kubernetes.core collection " + kubernetesCollectionVersion,
+      Url: galaxyDownloadUrl + "/" + collectionName,
+      Path: filepath.Join("collections", collectionName)},
+  }
+}
+
+/*
+GetWheelsDir - Gets the python wheels directory for an ansible
+version relative to the bundle directory
+*/
+func GetWheelsDir(ansibleVersion string) string {
+  return filepath.Join("wheels", ansibleVersion)
+}
+
+/*
+GetK3sRelease - Gets the k3s release for a kube version from the
+version catalog, the bundle can only have known releases
+*/
+func GetK3sRelease(kubeVersion string) (string, error) {
+  catalog, err := settings.GetVersionCatalog()
+  if err != nil {
+    return "", err
+  }
+
+  compatibility, known := catalog.GetKubeVersion(kubeVersion)
+  if !known {
+    logger.LogError("Error kube version is not in the version catalog", "version", kubeVersion)
+    return "", fmt.Errorf("kube version %s is not in the version catalog, there is no k3s release to bundle", kubeVersion)
+  }
+  return compatibility.K3sRelease, nil
+}
+
+/*
+DownloadArtifacts - Downloads artifacts into the bundle directory,
+artifacts that are already in the bundle are skipped unless force
+is set
+*/
+func DownloadArtifacts(appDir string, artifacts []Artifact, force bool) error {
+  for _, artifact := range artifacts {
+    path := filepath.Join(GetBundleDir(appDir), artifact.Path)
+
+    if !force && fileExists(path) {
+      logger.LogInfo("Already in the bundle, skipping", "artifact", artifact.Name)
+      continue
+    }
+
+    logger.LogInfo("Downloading", "artifact", artifact.Name)
+    err := downloadFile(artifact.Url, path)
+    if err != nil {
+      logger.LogError("Error downloading artifact", "artifact", artifact.Name, "url", artifact.Url)
+      return err
+    }
+  }
+  return nil
+}
+
+/*
+DownloadWheels - Downloads the python wheels for ansible and the
+bootstrap dependencies for the machines python version, this uses
+pip on the host
+*/
+func DownloadWheels(appDir string, ansibleVersion string, pythonVersion string, force bool) error {
+  wheelsDir := filepath.Join(GetBundleDir(appDir), GetWheelsDir(ansibleVersion))
+
+  if !force && hasWheels(wheelsDir) {
+    logger.LogInfo("Python wheels already in the bundle, skipping", "ansibleVersion", ansibleVersion)
+    return nil
+  }
+
+  err := os.RemoveAll(wheelsDir)
+  if err != nil {
+    logger.LogError("Error clearing the wheels directory")
+    return err
+  }
+
+  err = os.MkdirAll(wheelsDir, 0750)
+  if err != nil {
+    logger.LogError("Error creating the wheels directory")
+    return err
+  }
+
+  pipArgs := []string{"download", "--disable-pip-version-check", "--dest", wheelsDir,
+    "--only-binary=:all:", "--python-version", pythonVersion}
+  for _, platform := range pythonPlatforms {
+    pipArgs = append(pipArgs, "--platform", platform)
+  }
+  pipArgs = append(pipArgs, "ansible-core==" + ansibleVersion)
+  pipArgs = append(pipArgs, pythonPackages...)
+
+  logger.LogInfo("Downloading python wheels", "ansibleVersion", ansibleVersion, "pythonVersion", pythonVersion)
+  logger.LogDebug("pip args", "args", pipArgs)
+
+  cmd := exec.Command(pipCommand, pipArgs...)
+  output, err := cmd.CombinedOutput()
+  logger.LogDebug("pip output", "output", string(output))
+  if err != nil {
+    logger.LogError("Error downloading python wheels", "output", string(output))
+    return fmt.Errorf("downloading python wheels with %s failed: %w", pipCommand, err)
+  }
+  return nil
+}
+
+/*
+GetMissing - Gets the names of everything an offline cluster
+needs that is not in the bundle, empty if the bundle is complete
+*/
+func GetMissing(appDir string, k3sRelease string, ansibleVersion string) []string {
+  missing := []string{}
+
+  artifacts := append(GetK3sArtifacts(k3sRelease), GetToolArtifacts()...)
+  for _, artifact := range artifacts {
+    if !fileExists(filepath.Join(GetBundleDir(appDir), artifact.Path)) {
+      missing = append(missing, artifact.Name)
+    }
+  }
+
+  if !hasWheels(filepath.Join(GetBundleDir(appDir), GetWheelsDir(ansibleVersion))) {
+    missing = append(missing, "python wheels for ansible " + ansibleVersion)
+  }
+  return missing
+}
+
+/*
+CheckBundle - Checks that the bundle has everything needed to bring
+up a cluster offline
+*/
+func CheckBundle(appDir string, k3sRelease string, ansibleVersion string) error {
+  missing := GetMissing(appDir, k3sRelease, ansibleVersion)
+  if len(missing) > 0 {
+    for _, name := range missing {
+      logger.LogError("Missing from the offline bundle", "artifact", name)
+    }
+    return fmt.Errorf("the offline bundle is missing %s, run bundle-prepare first", strings.Join(missing, ", "))
+  }
+  return nil
+}
+
+/*
+GetOnlineRequirements - Gets the parts of a cluster that are not in
+the bundle and still need the network (cni and storage controllers
+the kube role installs and addons from chart repos)
+*/
+func GetOnlineRequirements(clusterSettings settings.Cluster) []string {
+  requirements := []string{}
+
+  if features := clusterSettings.ClusterFeatures; features != nil {
+    if features.ManagedCniController && features.CniController != features.GetDistribution().GetBuiltinCniController() {
+      requirements = append(requirements, "cni controller " + features.CniController)
+    }
+    if features.ManagedStorageController && features.StorageController != "local-storage" {
+      requirements = append(requirements, "storage controller " + features.StorageController)
+    }
+    if features.KubeVipEnable {
+      requirements = append(requirements, "kube-vip")
+    }
+  }
+
+  for _, addon := range clusterSettings.Addons {
+    if addon.Repo != "" {
+      requirements = append(requirements, "addon " + addon.Name)
+    }
+  }
+  return requirements
+}
+
+// downloads a url to a file, the file is only in place once it is complete
+func downloadFile(fileUrl string, path string) error {
+  err := os.MkdirAll(filepath.Dir(path), 0750)
+  if err != nil {
+    return err
+  }
+
+  response, err := http.Get(fileUrl)
+  if err != nil {
+    return err
+  }
+  defer response.Body.Close()
+
+  if response.StatusCode != http.StatusOK {
+    return fmt.Errorf("downloading %s failed: %s", fileUrl, response.Status)
+  }
+
+  tempFile, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+  if err != nil {
+    return err
+  }
+  defer os.Remove(tempFile.Name())
+
+  _, err = io.Copy(tempFile, response.Body)
+  if closeErr := tempFile.Close(); err == nil {
+    err = closeErr
+  }
+  if err != nil {
+    return err
+  }
+
+  err = os.Chmod(tempFile.Name(), 0755)
+  if err != nil {
+    return err
+  }
+  return os.Rename(tempFile.Name(), path)
+}
+
+// checks if a file exists
+func fileExists(path string) bool {
+  _, err := os.Stat(path)
+  return err == nil
+}
+
+// checks if a directory has python wheels in it
+func hasWheels(wheelsDir string) bool {
+  wheels, err := filepath.Glob(filepath.Join(wheelsDir, "*.whl"))
+  return err == nil && len(wheels) > 0
+}
@@ -0,0 +1,214 @@
+package bundle
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain is executed before running any tests
+func TestMain(m *testing.M) {
+	// Initialize the logger before running any tests
+	logger.InitLogging(false, true, false)
+	os.Exit(m.Run())
+}
+
+// points the download urls at a test server that serves the request path
+func setupTestServer(t *testing.T) *httptest.Server {
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if filepath.Base(r.URL.Path) == "missing" {
+      http.NotFound(w, r)
+      return
+    }
+    w.Write([]byte(r.URL.EscapedPath()))
+  }))
+
+  originalUrls := []string{k3sReleaseUrl, k3sInstallScriptUrl, yqReleaseUrl, galaxyDownloadUrl}
+  k3sReleaseUrl = server.URL + "/k3s"
+  k3sInstallScriptUrl = server.URL + "/k3s-raw"
+  yqReleaseUrl = server.URL + "/yq"
+  galaxyDownloadUrl = server.URL + "/galaxy"
+
+  t.Cleanup(func() {
+    server.Close()
+    k3sReleaseUrl, k3sInstallScriptUrl, yqReleaseUrl, galaxyDownloadUrl =
+      originalUrls[0], originalUrls[1], originalUrls[2], originalUrls[3]
+  })
+  return server
+}
+
+// adds a fake wheel to the bundle
+func addTestWheel(t *testing.T, ansibleVersion string) {
+  wheelsDir := filepath.Join(GetBundleDir(util.MockAppDir), GetWheelsDir(ansibleVersion))
+  assert.NoError(t, os.MkdirAll(wheelsDir, 0755))
+  assert.NoError(t, os.WriteFile(filepath.Join(wheelsDir, "pip-24.3.1-py3-none-any.whl"), []byte("wheel"), 0644))
+}
+
+/*
+      Tests for GetK3sArtifacts
+*/
+func TestGetK3sArtifacts(t *testing.T) {
+  artifacts := GetK3sArtifacts("v1.31.4+k3s1")
+
+  assert.Len(t, artifacts, 3)
+  assert.Equal(t, "https://github.com/k3s-io/k3s/releases/download/v1.31.4+k3s1/k3s", artifacts[0].Url)
+  assert.Equal(t, filepath.Join("k3s", "v1.31.4+k3s1", "k3s"), artifacts[0].Path)
+  assert.Equal(t, filepath.Join("k3s", "v1.31.4+k3s1", "k3s-airgap-images-amd64.tar.zst"), artifacts[1].Path)
+  assert.Equal(t, "https://raw.githubusercontent.com/k3s-io/k3s/v1.31.4+k3s1/install.sh", artifacts[2].Url)
+}
+
+/*
+      Tests for GetK3sRelease
+*/
+func TestGetK3sRelease(t *testing.T) {
+  release, err := GetK3sRelease("1.31.4")
+  assert.NoError(t, err)
+  assert.Equal(t, "v1.31.4+k3s1", release)
+}
+
+func TestGetK3sReleaseUnknown(t *testing.T) {
+  _, err := GetK3sRelease("1.20.0")
+  assert.Error(t, err)
+
+  // a patch release that is not in the catalog is not bundled as another patch release
+  _, err = GetK3sRelease("1.31.1")
+  assert.Error(t, err)
+}
+
+/*
+      Tests for DownloadArtifacts
+*/
+func TestDownloadArtifacts(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  setupTestServer(t)
+
+  artifacts := append(GetK3sArtifacts("v1.31.4+k3s1"), GetToolArtifacts()...)
+  err = DownloadArtifacts(util.MockAppDir, artifacts, false)
+  assert.NoError(t, err)
+
+  k3s, err := os.ReadFile(filepath.Join(GetBundleDir(util.MockAppDir), "k3s", "v1.31.4+k3s1", "k3s"))
+  assert.NoError(t, err)
+  assert.Equal(t, "/k3s/v1.31.4+k3s1/k3s", string(k3s))
+
+  yq, err := os.ReadFile(filepath.Join(GetBundleDir(util.MockAppDir), "yq", "yq"))
+  assert.NoError(t, err)
+  assert.Equal(t, "/yq/v4.44.6/yq_linux_amd64", string(yq))
+
+  // no temp files are left behind
+  leftovers, err := filepath.Glob(filepath.Join(GetBundleDir(util.MockAppDir), "*", ".download-*"))
+  assert.NoError(t, err)
+  assert.Empty(t, leftovers)
+}
+
+func TestDownloadArtifactsSkipsExisting(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  setupTestServer(t)
+
+  yqPath := filepath.Join(GetBundleDir(util.MockAppDir), "yq", "yq")
+  assert.NoError(t, os.MkdirAll(filepath.Dir(yqPath), 0755))
+  assert.NoError(t, os.WriteFile(yqPath, []byte("cached"), 0755))
+
+  err = DownloadArtifacts(util.MockAppDir, GetToolArtifacts(), false)
+  assert.NoError(t, err)
+
+  yq, err := os.ReadFile(yqPath)
+  assert.NoError(t, err)
+  assert.Equal(t, "cached", string(yq))
+
+  err = DownloadArtifacts(util.MockAppDir, GetToolArtifacts(), true)
+  assert.NoError(t, err)
+
+  yq, err = os.ReadFile(yqPath)
+  assert.NoError(t, err)
+  assert.Equal(t, "/yq/v4.44.6/yq_linux_amd64", string(yq))
+}
+
+func TestDownloadArtifactsError(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  server := setupTestServer(t)
+
+  artifacts := []Artifact{{Name: "missing", Url: server.URL + "/missing", Path: "missing"}}
+  err = DownloadArtifacts(util.MockAppDir, artifacts, false)
+  assert.ErrorContains(t, err, "404")
+  assert.NoFileExists(t, filepath.Join(GetBundleDir(util.MockAppDir), "missing"))
+}
+
+/*
+      Tests for CheckBundle
+*/
+func TestCheckBundle(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  setupTestServer(t)
+
+  err = CheckBundle(util.MockAppDir, "v1.31.4+k3s1", "2.17.6")
+  assert.ErrorContains(t, err, "run bundle-prepare")
+  assert.Len(t, GetMissing(util.MockAppDir, "v1.31.4+k3s1", "2.17.6"), 6)
+
+  artifacts := append(GetK3sArtifacts("v1.31.4+k3s1"), GetToolArtifacts()...)
+  assert.NoError(t, DownloadArtifacts(util.MockAppDir, artifacts, false))
+  assert.Equal(t, []string{"python wheels for ansible 2.17.6"}, GetMissing(util.MockAppDir, "v1.31.4+k3s1", "2.17.6"))
+
+  addTestWheel(t, "2.17.6")
+  assert.NoError(t, CheckBundle(util.MockAppDir, "v1.31.4+k3s1", "2.17.6"))
+
+  // a different k3s release is not in the bundle
+  assert.Error(t, CheckBundle(util.MockAppDir, "v1.32.0+k3s1", "2.17.6"))
+}
+
+/*
+      Tests for DownloadWheels
+*/
+func TestDownloadWheelsSkipsExisting(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  originalPipCommand := pipCommand
+  pipCommand = "local-kube-missing-pip"
+  defer func() { pipCommand = originalPipCommand }()
+
+  addTestWheel(t, "2.17.6")
+  assert.NoError(t, DownloadWheels(util.MockAppDir, "2.17.6", "3.12", false))
+
+  // pip is only run when the wheels are not in the bundle
+  assert.Error(t, DownloadWheels(util.MockAppDir, "2.17.5", "3.12", false))
+}
+
+/*
+      Tests for GetOnlineRequirements
+*/
+func TestGetOnlineRequirements(t *testing.T) {
+  clusterSettings := settings.Cluster{
+    ClusterFeatures: &settings.ClusterFeatures{
+      CniController: "cilium",
+      ManagedCniController: true,
+      StorageController: "local-storage",
+    },
+    Addons: []settings.Addon{
+      {Name: "cert-manager", Chart: "cert-manager", Repo: "https://charts.jetstack.io"},
+      {Name: "my-app", Path: "charts/my-app"},
+    },
+  }
+
+  assert.Equal(t, []string{"cni controller cilium", "addon cert-manager"}, GetOnlineRequirements(clusterSettings))
+  assert.Empty(t, GetOnlineRequirements(settings.Cluster{}))
+}
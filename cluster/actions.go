@@ -3,8 +3,8 @@ package cluster
 import (
 	"errors"
 	"fmt"
-	"os/exec"
 	"path/filepath"
+	"time"
 
 	vagrant "github.com/bmatcuk/go-vagrant"
 	"github.com/dgutierrez1287/local-kube/logger"
@@ -66,7 +66,8 @@ machineOutput bool) (map[string]*vagrant.VMInfo ,error) {
 
 /*
 This will ssh to the ansible(lead) node in the cluster and run a provision script
-that will run ansible for a given cluster type
+that will run ansible for a given cluster type, the distribution paths
+are passed to the script
 */
 func ClusterProvision(appDir string, clusterName string,
 appSettings settings.Settings, machineOutput bool, debug bool) error {
@@ -74,41 +75,21 @@ appSettings settings.Settings, machineOutput bool, debug bool) error {
   cmdStr := ""
 
   clusterType := appSettings.Clusters[clusterName].ClusterType
+  distribution := appSettings.Clusters[clusterName].GetDistribution()
   vagrantNodeName := appSettings.Clusters[clusterName].GetAnsibleNodeVagrantName()
   if debug {
-    cmdStr = fmt.Sprintf("bash /scripts/%s-provision.sh debug", clusterType)
+    cmdStr = fmt.Sprintf("bash /scripts/%s-provision.sh debug %s %s", clusterType,
+      distribution.KubeConfigPath, distribution.ManifestsDir)
   } else {
-    cmdStr = fmt.Sprintf("bash /scripts/%s-provision.sh info", clusterType)
+    cmdStr = fmt.Sprintf("bash /scripts/%s-provision.sh info %s %s", clusterType,
+      distribution.KubeConfigPath, distribution.ManifestsDir)
   }
 
-  sshConfig, err := GetSshConfigs(clusterDir, vagrantNodeName)
-  if err != nil {
-    logger.LogError("Error getting vagrant ssh config")
-    return err
-  }
-
-  sshArgs := []string {
-    "-i", sshConfig.IdentityFile,
-    "-p", fmt.Sprintf("%d", sshConfig.Port),
-    "-o", "StrictHostKeyChecking=no",
-    "-o", "UserKnownHostsFile=/dev/null",
-    fmt.Sprintf("%s@%s", sshConfig.User, sshConfig.HostName),
-    cmdStr,
-  }
-
-  sshArgs = append(sshArgs, cmdStr)
-
-  logger.LogDebug("ssh args", "args", sshArgs)
-
-  cmd := exec.Command("ssh", sshArgs...)
-
-  output, err := cmd.CombinedOutput()
+  _, err := RunSshCommand(clusterDir, vagrantNodeName, cmdStr)
   if err != nil {
     logger.LogError("Provision command failed")
     return err
   }
-
-  logger.LogDebug("Ssh output", "output", output)
   return nil
 }
 
@@ -159,3 +140,147 @@ machineOutput bool) error {
 }
 
 
+
+// the time between the checks for the api server
+const apiServerWaitInterval = 5 * time.Second
+
+/*
+This will start the machines of a stopped Cluster without
+running any provisioning, the control nodes are started
+before the workers one machine at a time
+*/
+func ClusterStart(appDir string, clusterName string,
+appSettings settings.Settings, machineOutput bool) error {
+  clusterDir := filepath.Join(appDir, clusterName)
+
+  logger.LogDebug("Getting vagrant client")
+  client, err := NewVagrantClient(clusterDir)
+  if err != nil {
+    logger.LogError("Error getting vagrant client")
+    return err
+  }
+
+  for _, machineName := range appSettings.Clusters[clusterName].GetVagrantMachineNames() {
+    logger.LogInfo("Starting machine", "cluster", clusterName, "machine", machineName)
+
+    upCmd := client.UpNoProvision()
+    if upCmd == nil {
+      logger.LogError("Error up command is nil")
+      return errors.New("up command is nil")
+    }
+    upCmd.MachineName = machineName
+    upCmd.DestroyOnError = false
+
+    logger.LogDebug("upCmd", upCmd)
+
+    err = upCmd.Start()
+    if err != nil {
+      logger.LogError("Error running the vagrant up command", "machine", machineName)
+      return err
+    }
+
+    err = upCmd.Wait()
+    if err != nil {
+      logger.LogError("Error waiting for vagrant up command", "machine", machineName)
+      return err
+    }
+
+    respErrors := upCmd.UpResponse.ErrorResponse
+    if respErrors.Error != nil {
+      logger.LogError("Error starting the machine", "machine", machineName)
+      return respErrors.Error
+    }
+  }
+  return nil
+}
+
+/*
+This will stop the machines of a Cluster without destroying
+them, the workers are stopped before the control nodes one
+machine at a time
+*/
+func ClusterStop(appDir string, clusterName string,
+appSettings settings.Settings, machineOutput bool) error {
+  clusterDir := filepath.Join(appDir, clusterName)
+
+  logger.LogDebug("Getting vagrant client")
+  client, err := NewVagrantClient(clusterDir)
+  if err != nil {
+    logger.LogError("Error getting vagrant client")
+    return err
+  }
+
+  machineNames := appSettings.Clusters[clusterName].GetVagrantMachineNames()
+
+  // a cluster with no machines in the settings (ex removed from the
+  // settings) halts every machine in its vagrantfile
+  if len(machineNames) == 0 {
+    machineNames = []string{""}
+  }
+
+  for index := len(machineNames) - 1; index >= 0; index-- {
+    machineName := machineNames[index]
+    logger.LogInfo("Stopping machine", "cluster", clusterName, "machine", machineName)
+
+    haltCmd := client.Halt()
+    if haltCmd == nil {
+      logger.LogError("Error halt command is nil")
+      return errors.New("halt command is nil")
+    }
+    haltCmd.MachineName = machineName
+
+    logger.LogDebug("haltCmd", haltCmd)
+
+    err = haltCmd.Start()
+    if err != nil {
+      logger.LogError("Error running the vagrant halt command", "machine", machineName)
+      return err
+    }
+
+    err = haltCmd.Wait()
+    if err != nil {
+      logger.LogError("Error waiting for the vagrant halt command", "machine", machineName)
+      return err
+    }
+
+    respErrors := haltCmd.ErrorResponse
+    if respErrors.Error != nil {
+      logger.LogError("Error stopping the machine", "machine", machineName)
+      return respErrors.Error
+    }
+  }
+  return nil
+}
+
+/*
+This will wait for the api server of a Cluster to be ready,
+the readyz endpoint is checked from the lead node until it
+answers or the timeout is reached
+*/
+func WaitForApiServer(appDir string, clusterName string,
+appSettings settings.Settings, timeout time.Duration) error {
+  clusterDir := filepath.Join(appDir, clusterName)
+  clusterSettings := appSettings.Clusters[clusterName]
+
+  cmdStr := getApiServerWaitCommand(clusterSettings.GetDistribution().KubectlCommand, timeout)
+
+  logger.LogInfo("Waiting for the api server", "cluster", clusterName, "timeout", timeout.String())
+  _, err := RunSshCommand(clusterDir, clusterSettings.GetAnsibleNodeVagrantName(), cmdStr)
+  if err != nil {
+    logger.LogError("Api server is not ready", "timeout", timeout.String())
+    return fmt.Errorf("api server was not ready after %s: %w", timeout, err)
+  }
+  return nil
+}
+
+// gets the command that checks the api server until it is ready,
+// the loop runs on the machine so there is only one ssh connection
+func getApiServerWaitCommand(kubectlCommand string, timeout time.Duration) string {
+  attempts := int(timeout / apiServerWaitInterval)
+  if attempts < 1 {
+    attempts = 1
+  }
+
+  return fmt.Sprintf("for i in $(seq 1 %d); do %s get --raw=/readyz > /dev/null 2>&1 && exit 0; sleep %d; done; exit 1",
+    attempts, kubectlCommand, int(apiServerWaitInterval.Seconds()))
+}
@@ -0,0 +1,138 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	vagrant "github.com/bmatcuk/go-vagrant"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// gets a vagrant client that runs a fake vagrant which records
+// its arguments, the commands from the client can be run without
+// vagrant installed. The recorded calls are returned by the func
+func getFakeVagrantClient(t *testing.T) (*vagrant.VagrantClient, func() []string) {
+  binDir := t.TempDir()
+  callsFile := filepath.Join(binDir, "calls")
+
+  fakeVagrant := "#!/bin/sh\necho \"$@\" >> " + callsFile + "\n"
+  assert.NoError(t, os.WriteFile(filepath.Join(binDir, "vagrant"), []byte(fakeVagrant), 0755))
+  t.Setenv("PATH", binDir + string(os.PathListSeparator) + os.Getenv("PATH"))
+
+  vagrantDir := t.TempDir()
+  assert.NoError(t, os.WriteFile(filepath.Join(vagrantDir, "Vagrantfile"), []byte(""), 0644))
+
+  client, err := vagrant.NewVagrantClient(vagrantDir)
+  assert.NoError(t, err)
+
+  return client, func() []string {
+    calls, err := os.ReadFile(callsFile)
+    assert.NoError(t, err)
+    return strings.Split(strings.TrimSpace(string(calls)), "\n")
+  }
+}
+
+// swaps the vagrant client factory for one that returns the
+// mock client, the returned func puts the factory back
+func useMockVagrantClient(mockClient *MockVagrantClient) func() {
+  originalNewClient := NewVagrantClient
+  NewVagrantClient = func(dir string) (VagrantClientInterface, error) {
+    return mockClient, nil
+  }
+  return func() { NewVagrantClient = originalNewClient }
+}
+
+/*
+      Tests for ClusterStart
+*/
+func TestClusterStartOrder(t *testing.T) {
+  fakeClient, getCalls := getFakeVagrantClient(t)
+  appSettings := getHooksSettings(nil)
+
+  mockClient := new(MockVagrantClient)
+  upCmds := []*vagrant.UpCommand{}
+  for range appSettings.Clusters["prod"].GetVagrantMachineNames() {
+    upCmd := fakeClient.Up()
+    upCmd.Provisioning = vagrant.DisableProvisioning
+    upCmds = append(upCmds, upCmd)
+    mockClient.On("UpNoProvision").Return(upCmd).Once()
+  }
+  defer useMockVagrantClient(mockClient)()
+
+  err := ClusterStart(util.MockAppDir, "prod", appSettings, false)
+  assert.NoError(t, err)
+  mockClient.AssertExpectations(t)
+
+  // control nodes are started before the workers
+  assert.Equal(t, "prod-cp01", upCmds[0].MachineName)
+  assert.Equal(t, "prod-cp02", upCmds[1].MachineName)
+  assert.Equal(t, "prod-w01", upCmds[2].MachineName)
+  assert.Equal(t, []string{
+    "up --machine-readable --no-provision --no-destroy-on-error prod-cp01",
+    "up --machine-readable --no-provision --no-destroy-on-error prod-cp02",
+    "up --machine-readable --no-provision --no-destroy-on-error prod-w01",
+  }, getCalls())
+}
+
+/*
+      Tests for ClusterStop
+*/
+func TestClusterStopOrder(t *testing.T) {
+  fakeClient, getCalls := getFakeVagrantClient(t)
+  appSettings := getHooksSettings(nil)
+
+  mockClient := new(MockVagrantClient)
+  haltCmds := []*vagrant.HaltCommand{}
+  for range appSettings.Clusters["prod"].GetVagrantMachineNames() {
+    haltCmd := fakeClient.Halt()
+    haltCmds = append(haltCmds, haltCmd)
+    mockClient.On("Halt").Return(haltCmd).Once()
+  }
+  defer useMockVagrantClient(mockClient)()
+
+  err := ClusterStop(util.MockAppDir, "prod", appSettings, false)
+  assert.NoError(t, err)
+  mockClient.AssertExpectations(t)
+
+  // workers are stopped before the control nodes
+  assert.Equal(t, "prod-w01", haltCmds[0].MachineName)
+  assert.Equal(t, "prod-cp02", haltCmds[1].MachineName)
+  assert.Equal(t, "prod-cp01", haltCmds[2].MachineName)
+  assert.Equal(t, []string{
+    "halt --machine-readable prod-w01",
+    "halt --machine-readable prod-cp02",
+    "halt --machine-readable prod-cp01",
+  }, getCalls())
+}
+
+func TestClusterStopNoMachines(t *testing.T) {
+  fakeClient, getCalls := getFakeVagrantClient(t)
+  appSettings := getHooksSettings(nil)
+  appSettings.Clusters["removed"] = settings.Cluster{}
+
+  mockClient := new(MockVagrantClient)
+  mockClient.On("Halt").Return(fakeClient.Halt()).Once()
+  defer useMockVagrantClient(mockClient)()
+
+  err := ClusterStop(util.MockAppDir, "removed", appSettings, false)
+  assert.NoError(t, err)
+  mockClient.AssertExpectations(t)
+  assert.Equal(t, []string{"halt --machine-readable"}, getCalls())
+}
+
+/*
+      Tests for getApiServerWaitCommand
+*/
+func TestGetApiServerWaitCommand(t *testing.T) {
+  cmdStr := getApiServerWaitCommand("sudo k3s kubectl", 2 * time.Minute)
+  assert.Equal(t, "for i in $(seq 1 24); do sudo k3s kubectl get --raw=/readyz > /dev/null 2>&1 && exit 0; sleep 5; done; exit 1", cmdStr)
+
+  // there is always one check
+  cmdStr = getApiServerWaitCommand("sudo k3s kubectl", time.Second)
+  assert.Contains(t, cmdStr, "$(seq 1 1)")
+}
@@ -0,0 +1,279 @@
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+  HelmChart - A k3s auto deploy HelmChart manifest, k3s
+  installs any of these found in its manifests directory
+*/
+type HelmChart struct {
+  ApiVersion string          `yaml:"apiVersion"`
+  Kind string                `yaml:"kind"`
+  Metadata HelmChartMetadata `yaml:"metadata"`
+  Spec HelmChartSpec         `yaml:"spec"`
+}
+
+type HelmChartMetadata struct {
+  Name string        `yaml:"name"`
+  Namespace string   `yaml:"namespace"`
+}
+
+type HelmChartSpec struct {
+  Chart string             `yaml:"chart,omitempty"`
+  Repo string              `yaml:"repo,omitempty"`
+  Version string           `yaml:"version,omitempty"`
+  TargetNamespace string   `yaml:"targetNamespace"`
+  CreateNamespace bool     `yaml:"createNamespace"`
+  ValuesContent string     `yaml:"valuesContent,omitempty"`
+  ChartContent string      `yaml:"chartContent,omitempty"`
+}
+
+/*
+GenerateAddonManifests - Renders a HelmChart manifest for each
+addon of the cluster into the cluster addons directory. Local
+charts are packaged into the manifest so nothing is downloaded
+*/
+func GenerateAddonManifests(appDir string, clusterName string, appSettings settings.Settings) error {
+  addonsDir := filepath.Join(appDir, clusterName, "addons")
+
+  for _, addon := range appSettings.Clusters[clusterName].Addons {
+    logger.LogDebug("Generating addon manifest", "addon", addon.Name)
+
+    helmChart, err := getHelmChart(appDir, addon)
+    if err != nil {
+      logger.LogError("Error generating addon manifest", "addon", addon.Name)
+      return err
+    }
+
+    yamlData, err := yaml.Marshal(&helmChart)
+    if err != nil {
+      logger.LogError("Error marshaling addon manifest", "addon", addon.Name)
+      return err
+    }
+
+    manifestFile := filepath.Join(addonsDir, addon.Name + ".yaml")
+    err = os.WriteFile(manifestFile, append([]byte("---\n"), yamlData...), 0644)
+    if err != nil {
+      logger.LogError("Error writing addon manifest", "file", manifestFile)
+      return err
+    }
+  }
+  return nil
+}
+
+// builds the HelmChart manifest for an addon
+func getHelmChart(appDir string, addon settings.Addon) (HelmChart, error) {
+  helmChart := HelmChart{
+    ApiVersion: "helm.cattle.io/v1",
+    Kind: "HelmChart",
+    Metadata: HelmChartMetadata{Name: addon.Name, Namespace: "kube-system"},
+    Spec: HelmChartSpec{
+      Chart: addon.Chart,
+      Repo: addon.Repo,
+      Version: addon.Version,
+      TargetNamespace: addon.GetNamespace(),
+      CreateNamespace: true,
+    },
+  }
+
+  if len(addon.Values) > 0 {
+    values, err := yaml.Marshal(addon.Values)
+    if err != nil {
+      return HelmChart{}, err
+    }
+    helmChart.Spec.ValuesContent = string(values)
+  }
+
+  if addon.Path != "" {
+    chartContent, err := packageChart(addon.GetChartPath(appDir))
+    if err != nil {
+      return HelmChart{}, err
+    }
+    helmChart.Spec.ChartContent = chartContent
+  }
+  return helmChart, nil
+}
+
+/*
+packages a local chart directory into a base64 encoded chart
+archive (a tgz with the chart in a top level directory) the way
+helm package does
+*/
+func packageChart(chartPath string) (string, error) {
+  _, err := os.Stat(filepath.Join(chartPath, "Chart.yaml"))
+  if err != nil {
+    logger.LogError("Error local chart directory has no Chart.yaml", "path", chartPath)
+    return "", fmt.Errorf("%s is not a chart directory: %w", chartPath, err)
+  }
+
+  var archive bytes.Buffer
+  gzipWriter := gzip.NewWriter(&archive)
+  tarWriter := tar.NewWriter(gzipWriter)
+  chartDir := filepath.Base(chartPath)
+
+  err = filepath.WalkDir(chartPath, func(path string, entry fs.DirEntry, err error) error {
+    if err != nil {
+      return err
+    }
+
+    relativePath, err := filepath.Rel(chartPath, path)
+    if err != nil {
+      return err
+    }
+
+    info, err := entry.Info()
+    if err != nil {
+      return err
+    }
+
+    header, err := tar.FileInfoHeader(info, "")
+    if err != nil {
+      return err
+    }
+    header.Name = filepath.ToSlash(filepath.Join(chartDir, relativePath))
+
+    err = tarWriter.WriteHeader(header)
+    if err != nil || !info.Mode().IsRegular() {
+      return err
+    }
+
+    file, err := os.Open(path)
+    if err != nil {
+      return err
+    }
+    defer file.Close()
+
+    _, err = io.Copy(tarWriter, file)
+    return err
+  })
+  if err != nil {
+    logger.LogError("Error packaging local chart", "path", chartPath)
+    return "", err
+  }
+
+  if err := tarWriter.Close(); err != nil {
+    return "", err
+  }
+  if err := gzipWriter.Close(); err != nil {
+    return "", err
+  }
+  return base64.StdEncoding.EncodeToString(archive.Bytes()), nil
+}
+
+/*
+GetAddonNames - Gets the names of the addons that were rendered
+for a cluster that has been brought up
+*/
+func GetAddonNames(appDir string, clusterName string) ([]string, error) {
+  names := []string{}
+
+  manifests, err := filepath.Glob(filepath.Join(appDir, clusterName, "addons", "*.yaml"))
+  if err != nil {
+    logger.LogError("Error listing addon manifests")
+    return nil, err
+  }
+
+  for _, manifest := range manifests {
+    names = append(names, strings.TrimSuffix(filepath.Base(manifest), ".yaml"))
+  }
+  return names, nil
+}
+
+/*
+GetAddonStatus - Gets the install status of the addons of a running
+cluster from the helm install jobs on the lead node, the status
+is deployed, failed, installing or pending
+*/
+func GetAddonStatus(appDir string, clusterName string) (map[string]string, error) {
+  addonNames, err := GetAddonNames(appDir, clusterName)
+  if err != nil {
+    return nil, err
+  }
+
+  if len(addonNames) == 0 {
+    return map[string]string{}, nil
+  }
+
+  scriptSettings, err := ReadScriptSettings(appDir, clusterName)
+  if err != nil {
+    return nil, err
+  }
+
+  // clusters deployed before the distribution was saved are k3s
+  distribution := settings.ClusterFeatures{Distribution: scriptSettings.Distribution}.GetDistribution()
+
+  // single node clusters use the default vagrant machine
+  vagrantNodeName := "default"
+  if len(scriptSettings.LeadNode) > 0 {
+    vagrantNodeName = scriptSettings.LeadNode[0].Name
+  }
+
+  jobsJson, err := RunSshCommand(filepath.Join(appDir, clusterName), vagrantNodeName,
+    distribution.KubectlCommand + " get jobs -n kube-system -o json")
+  if err != nil {
+    logger.LogError("Error getting the helm install jobs")
+    return nil, err
+  }
+  return parseAddonJobStatus(addonNames, jobsJson)
+}
+
+// gets the addon statuses from the kube-system jobs json
+func parseAddonJobStatus(addonNames []string, jobsJson string) (map[string]string, error) {
+  var jobs struct {
+    Items []struct {
+      Metadata struct {
+        Name string `json:"name"`
+      } `json:"metadata"`
+      Status struct {
+        Active int     `json:"active"`
+        Succeeded int  `json:"succeeded"`
+        Failed int     `json:"failed"`
+      } `json:"status"`
+    } `json:"items"`
+  }
+
+  err := json.Unmarshal([]byte(jobsJson), &jobs)
+  if err != nil {
+    logger.LogError("Error unmarshaling helm install jobs")
+    return nil, errors.New("unexpected output getting helm install jobs")
+  }
+
+  statuses := make(map[string]string)
+  for _, name := range addonNames {
+    statuses[name] = "pending"
+  }
+
+  for _, job := range jobs.Items {
+    name := strings.TrimPrefix(job.Metadata.Name, "helm-install-")
+    if _, exists := statuses[name]; !exists || name == job.Metadata.Name {
+      continue
+    }
+
+    switch {
+    case job.Status.Succeeded > 0:
+      statuses[name] = "deployed"
+    case job.Status.Active > 0:
+      statuses[name] = "installing"
+    case job.Status.Failed > 0:
+      statuses[name] = "failed"
+    }
+  }
+  return statuses, nil
+}
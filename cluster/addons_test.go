@@ -0,0 +1,159 @@
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func getAddonTestSettings(addons []settings.Addon) settings.Settings {
+  return settings.Settings{
+    Clusters: map[string]settings.Cluster{
+      "dev": {ClusterType: "single", Addons: addons},
+    },
+  }
+}
+
+/*
+      Tests for GenerateAddonManifests
+*/
+func TestGenerateAddonManifestsRepoChart(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  err = os.MkdirAll(filepath.Join(util.MockAppDir, "dev", "addons"), 0755)
+  assert.NoError(t, err)
+
+  appSettings := getAddonTestSettings([]settings.Addon{
+    {
+      Name: "cert-manager",
+      Chart: "cert-manager",
+      Repo: "https://charts.jetstack.io",
+      Version: "v1.16.2",
+      Namespace: "cert-manager",
+      Values: map[string]interface{}{"crds": map[string]interface{}{"enabled": true}},
+    },
+  })
+
+  err = GenerateAddonManifests(util.MockAppDir, "dev", appSettings)
+  assert.NoError(t, err)
+
+  content, err := os.ReadFile(filepath.Join(util.MockAppDir, "dev", "addons", "cert-manager.yaml"))
+  assert.NoError(t, err)
+
+  var helmChart HelmChart
+  assert.NoError(t, yaml.Unmarshal(content, &helmChart))
+
+  assert.Equal(t, "HelmChart", helmChart.Kind)
+  assert.Equal(t, "kube-system", helmChart.Metadata.Namespace)
+  assert.Equal(t, "cert-manager", helmChart.Spec.Chart)
+  assert.Equal(t, "https://charts.jetstack.io", helmChart.Spec.Repo)
+  assert.Equal(t, "v1.16.2", helmChart.Spec.Version)
+  assert.Equal(t, "cert-manager", helmChart.Spec.TargetNamespace)
+  assert.Equal(t, "crds:\n    enabled: true\n", helmChart.Spec.ValuesContent)
+  assert.Empty(t, helmChart.Spec.ChartContent)
+
+  names, err := GetAddonNames(util.MockAppDir, "dev")
+  assert.NoError(t, err)
+  assert.Equal(t, []string{"cert-manager"}, names)
+}
+
+func TestGenerateAddonManifestsLocalChart(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  err = os.MkdirAll(filepath.Join(util.MockAppDir, "dev", "addons"), 0755)
+  assert.NoError(t, err)
+
+  chartDir := filepath.Join(util.MockAppDir, "charts", "my-app")
+  err = os.MkdirAll(filepath.Join(chartDir, "templates"), 0755)
+  assert.NoError(t, err)
+  assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: my-app\nversion: 0.1.0\n"), 0644))
+  assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "templates", "cm.yaml"), []byte("kind: ConfigMap\n"), 0644))
+
+  // relative paths are from the app dir
+  appSettings := getAddonTestSettings([]settings.Addon{{Name: "my-app", Path: "charts/my-app"}})
+
+  err = GenerateAddonManifests(util.MockAppDir, "dev", appSettings)
+  assert.NoError(t, err)
+
+  content, err := os.ReadFile(filepath.Join(util.MockAppDir, "dev", "addons", "my-app.yaml"))
+  assert.NoError(t, err)
+
+  var helmChart HelmChart
+  assert.NoError(t, yaml.Unmarshal(content, &helmChart))
+  assert.Empty(t, helmChart.Spec.Repo)
+  assert.Equal(t, "default", helmChart.Spec.TargetNamespace)
+
+  // the chart archive has the chart in a top level directory
+  archive, err := base64.StdEncoding.DecodeString(helmChart.Spec.ChartContent)
+  assert.NoError(t, err)
+
+  gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+  assert.NoError(t, err)
+
+  files := []string{}
+  tarReader := tar.NewReader(gzipReader)
+  for {
+    header, err := tarReader.Next()
+    if err != nil {
+      break
+    }
+    if header.Typeflag == tar.TypeReg {
+      files = append(files, header.Name)
+    }
+  }
+  assert.ElementsMatch(t, []string{"my-app/Chart.yaml", "my-app/templates/cm.yaml"}, files)
+}
+
+func TestGenerateAddonManifestsMissingChart(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  err = os.MkdirAll(filepath.Join(util.MockAppDir, "dev", "addons"), 0755)
+  assert.NoError(t, err)
+
+  appSettings := getAddonTestSettings([]settings.Addon{{Name: "my-app", Path: "charts/missing"}})
+
+  err = GenerateAddonManifests(util.MockAppDir, "dev", appSettings)
+  assert.Error(t, err)
+}
+
+/*
+      Tests for parseAddonJobStatus
+*/
+func TestParseAddonJobStatus(t *testing.T) {
+  jobsJson := `{"items": [
+    {"metadata": {"name": "helm-install-cert-manager"}, "status": {"succeeded": 1}},
+    {"metadata": {"name": "helm-install-my-app"}, "status": {"failed": 3}},
+    {"metadata": {"name": "helm-install-metrics"}, "status": {"active": 1, "failed": 1}},
+    {"metadata": {"name": "helm-install-traefik"}, "status": {"succeeded": 1}}
+  ]}`
+
+  statuses, err := parseAddonJobStatus([]string{"cert-manager", "my-app", "metrics", "loki"}, jobsJson)
+  assert.NoError(t, err)
+
+  assert.Equal(t, map[string]string{
+    "cert-manager": "deployed",
+    "my-app": "failed",
+    "metrics": "installing",
+    "loki": "pending",
+  }, statuses)
+}
+
+func TestParseAddonJobStatusBadOutput(t *testing.T) {
+  _, err := parseAddonJobStatus([]string{"cert-manager"}, "The connection to the server was refused")
+  assert.Error(t, err)
+}
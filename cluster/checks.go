@@ -2,9 +2,12 @@ package cluster
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/settings"
 )
 
 /* This is going to be used by a few different
@@ -99,3 +102,68 @@ machineOutput bool) (bool, string, error){
   logger.LogDebug("Directory for cluster is created, but no machines are created")
   return true, "directory", nil
 }
+
+/*
+Checks that none of the ips for a cluster are in use by another
+cluster that has been brought up, this catches clusters that are
+running but have since been changed or removed in the settings
+*/
+func CheckDeployedIpConflicts(appDir string, clusterName string, clusterSettings settings.Cluster) error {
+  var conflicts []string
+
+  usedIps, err := GetDeployedClusterIps(appDir, clusterName)
+  if err != nil {
+    logger.LogError("Error getting ips of deployed clusters")
+    return err
+  }
+
+  machines := append(append([]settings.Machine{}, clusterSettings.Leaders...), clusterSettings.Workers...)
+  for _, machine := range machines {
+    if usedBy, used := usedIps[machine.IpAddress]; used {
+      logger.LogError("Error ip is used by a deployed cluster", "ip", machine.IpAddress, "usedBy", usedBy)
+      conflicts = append(conflicts, fmt.Sprintf("%s (%s) is used by %s", machine.IpAddress, machine.Name, usedBy))
+    }
+  }
+
+  if usedBy, used := usedIps[clusterSettings.Vip]; used && clusterSettings.Vip != "" {
+    logger.LogError("Error vip is used by a deployed cluster", "ip", clusterSettings.Vip, "usedBy", usedBy)
+    conflicts = append(conflicts, fmt.Sprintf("%s (vip) is used by %s", clusterSettings.Vip, usedBy))
+  }
+
+  if len(conflicts) > 0 {
+    return fmt.Errorf("cluster %s has ips that overlap deployed clusters: %s",
+      clusterName, strings.Join(conflicts, ", "))
+  }
+  return nil
+}
+
+/*
+Checks that none of the host ports a cluster forwards are forwarded
+by another cluster that has been brought up, the cluster forwarded
+ports should already be added to the lead machine
+*/
+func CheckDeployedPortConflicts(appDir string, clusterName string, clusterSettings settings.Cluster) error {
+  var conflicts []string
+
+  usedPorts, err := GetDeployedClusterPorts(appDir, clusterName)
+  if err != nil {
+    logger.LogError("Error getting ports of deployed clusters")
+    return err
+  }
+
+  machines := append(append([]settings.Machine{}, clusterSettings.Leaders...), clusterSettings.Workers...)
+  for _, machine := range machines {
+    for _, port := range machine.ForwardedPorts {
+      if usedBy, used := usedPorts[port.GetPortKey()]; used {
+        logger.LogError("Error host port is forwarded by a deployed cluster", "port", port.GetPortKey(), "usedBy", usedBy)
+        conflicts = append(conflicts, fmt.Sprintf("%s (%s) is used by %s", port.GetPortKey(), machine.Name, usedBy))
+      }
+    }
+  }
+
+  if len(conflicts) > 0 {
+    return fmt.Errorf("cluster %s has forwarded ports that overlap deployed clusters: %s",
+      clusterName, strings.Join(conflicts, ", "))
+  }
+  return nil
+}
@@ -1,15 +1,92 @@
 package cluster
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
-  //"os"
-  //"path/filepath"
 
+	"github.com/dgutierrez1287/local-kube/settings"
 	"github.com/dgutierrez1287/local-kube/util"
 	"github.com/stretchr/testify/assert"
   //vagrant "github.com/bmatcuk/go-vagrant"
 )
 
+/*
+      Tests for CheckDeployedIpConflicts
+*/
+func TestCheckDeployedIpConflicts(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  deployedSettingsDir := filepath.Join(util.MockAppDir, "deployed", "settings")
+  err = os.MkdirAll(deployedSettingsDir, 0755)
+  assert.NoError(t, err)
+
+  deployedSettings := "---\ncluster-name: deployed\ncluster-vip: 10.0.0.5\nlead-control-node: []\ncontrol-nodes: []\nworkers: []\n" +
+    "machine_settings:\n  name: deployed\n  ip: 10.0.0.10\n"
+  err = os.WriteFile(filepath.Join(deployedSettingsDir, "settings.yaml"), []byte(deployedSettings), 0644)
+  assert.NoError(t, err)
+
+  clusterSettings := settings.Cluster{
+    Leaders: []settings.Machine{{Name: "new", IpAddress: "10.0.0.11"}},
+  }
+
+  err = CheckDeployedIpConflicts(util.MockAppDir, "new", clusterSettings)
+  assert.NoError(t, err)
+
+  clusterSettings.Leaders[0].IpAddress = "10.0.0.10"
+  clusterSettings.Vip = "10.0.0.5"
+  err = CheckDeployedIpConflicts(util.MockAppDir, "new", clusterSettings)
+  assert.ErrorContains(t, err, "10.0.0.10 (new) is used by deployed/deployed")
+  assert.ErrorContains(t, err, "10.0.0.5 (vip) is used by deployed/vip")
+
+  // a cluster does not conflict with itself
+  err = CheckDeployedIpConflicts(util.MockAppDir, "deployed", clusterSettings)
+  assert.NoError(t, err)
+}
+
+/*
+      Tests for CheckDeployedPortConflicts
+*/
+func TestCheckDeployedPortConflicts(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  deployedSettingsDir := filepath.Join(util.MockAppDir, "deployed", "settings")
+  err = os.MkdirAll(deployedSettingsDir, 0755)
+  assert.NoError(t, err)
+
+  deployedSettings := "---\ncluster-name: deployed\nlead-control-node: []\ncontrol-nodes: []\nworkers: []\n" +
+    "machine_settings:\n  name: deployed\n  ip: 10.0.0.10\n  forwarded_ports:\n    - guest: 6443\n      host: 16443\n"
+  err = os.WriteFile(filepath.Join(deployedSettingsDir, "settings.yaml"), []byte(deployedSettings), 0644)
+  assert.NoError(t, err)
+
+  clusterSettings := settings.Cluster{
+    Leaders: []settings.Machine{{Name: "new", IpAddress: "10.0.0.11"}},
+    ForwardedPorts: &settings.ClusterForwardedPorts{ApiServer: 26443},
+  }
+
+  err = CheckDeployedPortConflicts(util.MockAppDir, "new", clusterSettings.WithForwardedPorts())
+  assert.NoError(t, err)
+
+  // the same port with another protocol is not a conflict
+  clusterSettings.Leaders[0].ForwardedPorts = []settings.ForwardedPort{{Guest: 53, Host: 16443, Protocol: "udp"}}
+  err = CheckDeployedPortConflicts(util.MockAppDir, "new", clusterSettings.WithForwardedPorts())
+  assert.NoError(t, err)
+
+  clusterSettings.ForwardedPorts.ApiServer = 16443
+  err = CheckDeployedPortConflicts(util.MockAppDir, "new", clusterSettings.WithForwardedPorts())
+  assert.ErrorContains(t, err, "16443/tcp (new) is used by deployed/deployed")
+
+  // a cluster does not conflict with itself
+  err = CheckDeployedPortConflicts(util.MockAppDir, "deployed", clusterSettings.WithForwardedPorts())
+  assert.NoError(t, err)
+}
+
 func TestCheckForExistingClusterNoDir(t *testing.T) {
   clusterName := "test-cluster"
 
@@ -2,7 +2,10 @@ package cluster
 
 import (
 	"errors"
+	"io"
 	"os"
+	"os/exec"
+	"strings"
   "fmt"
 
 	vagrant "github.com/bmatcuk/go-vagrant"
@@ -50,6 +53,95 @@ func GetSshConfigs(clusterDir string, nodeName string) (vagrant.SSHConfig, error
 
 }
 
+// gets the ssh args to connect to a machine from the
+// vagrant ssh config
+func getSshArgs(clusterDir string, nodeName string) ([]string, error) {
+  sshConfig, err := GetSshConfigs(clusterDir, nodeName)
+  if err != nil {
+    logger.LogError("Error getting vagrant ssh config")
+    return nil, err
+  }
+
+  return []string {
+    "-i", sshConfig.IdentityFile,
+    "-p", fmt.Sprintf("%d", sshConfig.Port),
+    "-o", "StrictHostKeyChecking=no",
+    "-o", "UserKnownHostsFile=/dev/null",
+    "-o", "LogLevel=ERROR",
+    fmt.Sprintf("%s@%s", sshConfig.User, sshConfig.HostName),
+  }, nil
+}
+
+/*
+Runs a command on a machine in a cluster over ssh and
+returns the combined output of the command
+*/
+func RunSshCommand(clusterDir string, nodeName string, command string) (string, error) {
+  sshArgs, err := getSshArgs(clusterDir, nodeName)
+  if err != nil {
+    return "", err
+  }
+  sshArgs = append(sshArgs, command)
+
+  logger.LogDebug("ssh args", "args", sshArgs)
+
+  cmd := exec.Command("ssh", sshArgs...)
+
+  output, err := cmd.CombinedOutput()
+  logger.LogDebug("Ssh output", "output", string(output))
+  if err != nil {
+    logger.LogError("Ssh command failed", "node", nodeName)
+    return string(output), err
+  }
+  return string(output), nil
+}
+
+/*
+Runs a script from the host on a machine in a cluster over
+ssh with bash, the environment variables are set for the
+script. The output of the script is written to output
+*/
+func RunSshScript(clusterDir string, nodeName string, script string,
+env map[string]string, output io.Writer) error {
+  sshArgs, err := getSshArgs(clusterDir, nodeName)
+  if err != nil {
+    return err
+  }
+
+  scriptFile, err := os.Open(script)
+  if err != nil {
+    logger.LogError("Error opening script", "script", script)
+    return err
+  }
+  defer scriptFile.Close()
+
+  command := []string{"env"}
+  for _, key := range sortedEnvKeys(env) {
+    command = append(command, fmt.Sprintf("%s=%s", key, shellQuote(env[key])))
+  }
+  command = append(command, "bash", "-s")
+  sshArgs = append(sshArgs, strings.Join(command, " "))
+
+  logger.LogDebug("ssh args", "args", sshArgs)
+
+  cmd := exec.Command("ssh", sshArgs...)
+  cmd.Stdin = scriptFile
+  cmd.Stdout = output
+  cmd.Stderr = output
+
+  err = cmd.Run()
+  if err != nil {
+    logger.LogError("Ssh script failed", "node", nodeName, "script", script)
+    return err
+  }
+  return nil
+}
+
+// quotes a value for the remote shell
+func shellQuote(value string) string {
+  return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
 /*
 opens an an ssh session
 */
@@ -29,6 +29,7 @@ var mainDirs = []string {
   "logs",
   "kubeconfig",
   "settings",
+  "addons",
 }
 
 /*
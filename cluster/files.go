@@ -1,10 +1,13 @@
 package cluster
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/dgutierrez1287/local-kube/ansible"
+	"github.com/dgutierrez1287/local-kube/bundle"
 	"github.com/dgutierrez1287/local-kube/logger"
 	"github.com/dgutierrez1287/local-kube/settings"
 	"github.com/dgutierrez1287/local-kube/static"
@@ -115,7 +118,7 @@ func GenerateAnsiblePlaybooks(appDir string, clusterName string, appSettings set
   This will generate all the variables files for the desired cluster. There 
   will be 3 variables files for an ha cluster and 1 for a single node cluster
 */
-func GenerateAnsibleVariables(appDir string, clusterName string, appSettings settings.Settings) error {
+func GenerateAnsibleVariables(appDir string, clusterName string, appSettings settings.Settings, offline bool) error {
 
   clusterType := appSettings.Clusters[clusterName].ClusterType
 
@@ -123,7 +126,7 @@ func GenerateAnsibleVariables(appDir string, clusterName string, appSettings set
     logger.LogDebug("Generating variables file for ha cluster")
 
     logger.LogDebug("Renderinng vars for lead node")
-    err := ansible.GenerateVarsFile(appDir, clusterName, clusterType, "lead", appSettings)
+    err := ansible.GenerateVarsFile(appDir, clusterName, clusterType, "lead", appSettings, offline)
     
     if err != nil {
       logger.LogError("Error rendering vars file for lead node")
@@ -131,7 +134,7 @@ func GenerateAnsibleVariables(appDir string, clusterName string, appSettings set
     }
 
     logger.LogDebug("Error Rendering vars for control nodes")
-    err = ansible.GenerateVarsFile(appDir, clusterName, clusterType, "control", appSettings)
+    err = ansible.GenerateVarsFile(appDir, clusterName, clusterType, "control", appSettings, offline)
 
     if err != nil {
       logger.LogError("Error rendering vars for control nodes")
@@ -139,7 +142,7 @@ func GenerateAnsibleVariables(appDir string, clusterName string, appSettings set
     }
 
     logger.LogDebug("Rendering vars for worker nodes") 
-    err = ansible.GenerateVarsFile(appDir, clusterName, clusterType, "worker", appSettings)
+    err = ansible.GenerateVarsFile(appDir, clusterName, clusterType, "worker", appSettings, offline)
 
     if err != nil {
       logger.LogError("Error rendering vars for worker nodes")
@@ -147,7 +150,7 @@ func GenerateAnsibleVariables(appDir string, clusterName string, appSettings set
     }
   } else {
     logger.LogDebug("Generating variables for single node cluster")
-    err := ansible.GenerateVarsFile(appDir, clusterName, clusterType, "", appSettings)
+    err := ansible.GenerateVarsFile(appDir, clusterName, clusterType, "", appSettings, offline)
 
     if err != nil {
       logger.LogError("Error rendering vars for single node cluster")
@@ -221,13 +224,28 @@ func SetupStaticScripts(appDir string, clusterName string) error {
 
 /*
   This will render gather needed data for a vagrant file template and will render 
-  the template and will write the result out to the cluster directory 
+  the template and will write the result out to the cluster directory, offline
+  clusters have the offline bundle synced into the machines
 */
-func RenderVagrantFile(appDir string, clusterName string, appSettings settings.Settings) error {
+func RenderVagrantFile(appDir string, clusterName string, appSettings settings.Settings, offline bool) error {
   providerName := appSettings.Clusters[clusterName].ProviderName
-  providerType := appSettings.Providers[providerName].ProviderType
+  provider := appSettings.Providers[providerName].WithDefaults()
+  providerType := provider.ProviderType
   clusterType := appSettings.Clusters[clusterName].ClusterType
 
+  providerDefinition, exists := settings.GetProviderDefinition(providerType)
+  if !exists {
+    logger.LogError("Error provider type is not supported", "providerType", providerType)
+    return fmt.Errorf("provider type %q is not supported, must be one of %s",
+      providerType, strings.Join(settings.GetProviderTypes(), ", "))
+  }
+
+  templateName, err := providerDefinition.GetTemplateName(clusterType)
+  if err != nil {
+    logger.LogError("Error provider type does not support the cluster type", "providerType", providerType, "clusterType", clusterType)
+    return err
+  }
+
   vagrantFilePath := filepath.Join(appDir, clusterName, "VagrantFile")
 
   logger.LogDebug("Getting data for VagrantFile rendering", "providerName", providerName, "providerType", providerType)
@@ -235,10 +253,26 @@ func RenderVagrantFile(appDir string, clusterName string, appSettings settings.S
 
   data := make(map[string]interface{})
 
-  data["Provider"] = appSettings.Providers[providerName]
+  data["Provider"] = provider
 
   logger.LogDebug("Provider settings", "settings", data["Provider"])
 
+  data["SyncedFolders"] = appSettings.Clusters[clusterName].SyncedFolders
+  data["Distribution"] = appSettings.Clusters[clusterName].GetDistribution()
+
+  if offline {
+    k3sRelease, err := bundle.GetK3sRelease(appSettings.Clusters[clusterName].ClusterFeatures.KubeVersion)
+    if err != nil {
+      return err
+    }
+
+    logger.LogDebug("Cluster is offline, syncing the offline bundle", "k3sRelease", k3sRelease)
+    data["Offline"] = map[string]interface{}{
+      "BundleDir": filepath.ToSlash(bundle.GetBundleDir(appDir)),
+      "K3sRelease": k3sRelease,
+    }
+  }
+
   if clusterType == "ha" {
     logger.LogDebug("Setting up vagrant template data for ha cluster")
 
@@ -259,7 +293,7 @@ func RenderVagrantFile(appDir string, clusterName string, appSettings settings.S
     logger.LogDebug("Node values are", "node", data["Node"])
   }
 
-  renderedVagrantFile, err := template.RenderVagrantfileTemplate(providerType, clusterType, data)
+  renderedVagrantFile, err := template.RenderVagrantfileTemplate(templateName, data)
 
   if err != nil {
     logger.LogError("Error rendering Vagrantfile")
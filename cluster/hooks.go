@@ -0,0 +1,155 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/settings"
+)
+
+/*
+RunHooks - Runs the hooks of a cluster for an event (ex postUp)
+in order, commands run on the host from the cluster directory and
+scripts run on the machines over ssh. The output of the hooks is
+appended to logs/hooks-<event>.txt in the cluster directory, postDown
+output goes to logs/<cluster>-hooks-postDown.txt in the app directory
+since the cluster directory is deleted after the cluster is destroyed.
+A failing hook stops the hooks unless it continues on error
+*/
+func RunHooks(appDir string, clusterName string, appSettings settings.Settings, event string) error {
+  clusterSettings := appSettings.Clusters[clusterName]
+  if clusterSettings.Hooks == nil || len(clusterSettings.Hooks.GetHooks(event)) == 0 {
+    logger.LogDebug("No hooks for event", "cluster", clusterName, "event", event)
+    return nil
+  }
+
+  clusterDir := filepath.Join(appDir, clusterName)
+  logPath := getHooksLogPath(appDir, clusterName, event)
+
+  err := os.MkdirAll(filepath.Dir(logPath), 0750)
+  if err != nil {
+    logger.LogError("Error creating the logs directory", "path", filepath.Dir(logPath))
+    return err
+  }
+
+  logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+  if err != nil {
+    logger.LogError("Error opening the hooks log", "path", logPath)
+    return err
+  }
+  defer logFile.Close()
+
+  env, err := getHookEnv(appDir, clusterName, appSettings, event)
+  if err != nil {
+    return err
+  }
+
+  for _, hook := range clusterSettings.Hooks.GetHooks(event) {
+    logger.LogInfo("Running hook", "cluster", clusterName, "event", event, "hook", hook.GetName())
+    fmt.Fprintf(logFile, "==> %s %s hook %s\n", time.Now().Format(time.RFC3339), event, hook.GetName())
+
+    if hook.Script != "" {
+      err = runHookScript(clusterDir, clusterSettings, hook, env, logFile)
+    } else {
+      err = runHookCommand(clusterDir, hook, env, logFile)
+    }
+
+    if err != nil {
+      fmt.Fprintf(logFile, "==> hook %s failed: %s\n", hook.GetName(), err)
+
+      if hook.ContinueOnError {
+        logger.LogWarn("Hook failed, continuing", "hook", hook.GetName(), "log", logPath, "error", err)
+        continue
+      }
+      logger.LogError("Hook failed", "hook", hook.GetName(), "log", logPath)
+      return fmt.Errorf("%s hook %s failed, see %s: %w", event, hook.GetName(), logPath, err)
+    }
+  }
+  return nil
+}
+
+// gets the log file for the hooks of an event
+func getHooksLogPath(appDir string, clusterName string, event string) string {
+  if event == "postDown" {
+    return filepath.Join(appDir, "logs", fmt.Sprintf("%s-hooks-%s.txt", clusterName, event))
+  }
+  return filepath.Join(appDir, clusterName, "logs", fmt.Sprintf("hooks-%s.txt", event))
+}
+
+// runs a hook command on the host from the cluster directory
+func runHookCommand(clusterDir string, hook settings.Hook, env map[string]string, logFile *os.File) error {
+  cmd := exec.Command("sh", "-c", hook.Command)
+  cmd.Dir = clusterDir
+  cmd.Env = os.Environ()
+  for _, key := range sortedEnvKeys(env) {
+    cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, env[key]))
+  }
+  cmd.Stdout = logFile
+  cmd.Stderr = logFile
+
+  logger.LogDebug("Running hook command", "command", hook.Command)
+  return cmd.Run()
+}
+
+// runs a hook script on each of the hook nodes over ssh
+func runHookScript(clusterDir string, clusterSettings settings.Cluster, hook settings.Hook,
+env map[string]string, logFile *os.File) error {
+  for _, nodeName := range clusterSettings.GetHookNodeNames(hook) {
+    nodeEnv := map[string]string{"LOCAL_KUBE_NODE_NAME": nodeName}
+    for key, value := range env {
+      nodeEnv[key] = value
+    }
+
+    fmt.Fprintf(logFile, "==> node %s\n", nodeName)
+    err := RunSshScript(clusterDir, nodeName, hook.Script, nodeEnv, logFile)
+    if err != nil {
+      return fmt.Errorf("node %s: %w", nodeName, err)
+    }
+  }
+  return nil
+}
+
+// gets the environment variables that describe the cluster to the hooks
+func getHookEnv(appDir string, clusterName string, appSettings settings.Settings, event string) (map[string]string, error) {
+  clusterSettings := appSettings.Clusters[clusterName]
+
+  clusterDir, err := filepath.Abs(filepath.Join(appDir, clusterName))
+  if err != nil {
+    logger.LogError("Error getting the cluster directory path")
+    return nil, err
+  }
+
+  leaderIps := clusterSettings.GetControlNodeIps()
+  workerIps := clusterSettings.GetWorkerNodeIps()
+
+  return map[string]string{
+    "LOCAL_KUBE_HOOK": event,
+    "LOCAL_KUBE_CLUSTER_NAME": clusterName,
+    "LOCAL_KUBE_CLUSTER_TYPE": clusterSettings.ClusterType,
+    "LOCAL_KUBE_CLUSTER_DIR": clusterDir,
+    "LOCAL_KUBE_DISTRIBUTION": clusterSettings.GetDistribution().Name,
+    "LOCAL_KUBE_KUBECONFIG": appSettings.KubeConfigPath,
+    "LOCAL_KUBE_KUBECONFIG_CONTEXT": clusterSettings.GetKubeConfigName(clusterName),
+    "LOCAL_KUBE_VIP": clusterSettings.Vip,
+    "LOCAL_KUBE_NODE_IPS": strings.Join(append(append([]string{}, leaderIps...), workerIps...), " "),
+    "LOCAL_KUBE_LEADER_IPS": strings.Join(leaderIps, " "),
+    "LOCAL_KUBE_WORKER_IPS": strings.Join(workerIps, " "),
+  }, nil
+}
+
+// gets the keys of the environment sorted so the
+// commands are the same every run
+func sortedEnvKeys(env map[string]string) []string {
+  keys := make([]string, 0, len(env))
+  for key := range env {
+    keys = append(keys, key)
+  }
+  sort.Strings(keys)
+  return keys
+}
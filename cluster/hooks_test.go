@@ -0,0 +1,125 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func getHooksSettings(hooks *settings.Hooks) settings.Settings {
+  return settings.Settings{
+    KubeConfigPath: "/home/user/.kube/config",
+    Clusters: map[string]settings.Cluster{
+      "prod": {
+        ClusterType: "ha",
+        Vip: "192.168.1.20",
+        KubeConfigName: "prod-local",
+        Leaders: []settings.Machine{
+          {Name: "prod-cp01", IpAddress: "192.168.1.21"},
+          {Name: "prod-cp02", IpAddress: "192.168.1.22"},
+        },
+        Workers: []settings.Machine{{Name: "prod-w01", IpAddress: "192.168.1.23"}},
+        ClusterFeatures: &settings.ClusterFeatures{},
+        Hooks: hooks,
+      },
+    },
+  }
+}
+
+/*
+      Tests for RunHooks
+*/
+func TestRunHooksCommand(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  appSettings := getHooksSettings(&settings.Hooks{
+    PostProvision: []settings.Hook{
+      {Name: "env", Command: "echo $LOCAL_KUBE_HOOK $LOCAL_KUBE_CLUSTER_NAME $LOCAL_KUBE_KUBECONFIG_CONTEXT"},
+      {Command: "echo \"$LOCAL_KUBE_NODE_IPS\" > nodes.txt"},
+    },
+  })
+
+  err = RunHooks(util.MockAppDir, "prod", appSettings, "postProvision")
+  assert.NoError(t, err)
+
+  // commands run from the cluster directory
+  nodes, err := os.ReadFile(filepath.Join(util.MockAppDir, "prod", "nodes.txt"))
+  assert.NoError(t, err)
+  assert.Equal(t, "192.168.1.21 192.168.1.22 192.168.1.23\n", string(nodes))
+
+  hooksLog, err := os.ReadFile(filepath.Join(util.MockAppDir, "prod", "logs", "hooks-postProvision.txt"))
+  assert.NoError(t, err)
+  assert.Contains(t, string(hooksLog), "postProvision hook env\n")
+  assert.Contains(t, string(hooksLog), "postProvision prod prod-local\n")
+}
+
+func TestRunHooksPostDownLog(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  assert.NoError(t, os.MkdirAll(filepath.Join(util.MockAppDir, "prod"), 0755))
+  appSettings := getHooksSettings(&settings.Hooks{
+    PostDown: []settings.Hook{{Name: "cleanup", Command: "echo cleaned up $LOCAL_KUBE_CLUSTER_NAME"}},
+  })
+
+  err = RunHooks(util.MockAppDir, "prod", appSettings, "postDown")
+  assert.NoError(t, err)
+
+  // the log is kept when the cluster directory is deleted
+  assert.NoError(t, DeleteClusterDir(util.MockAppDir, "prod"))
+
+  hooksLog, err := os.ReadFile(filepath.Join(util.MockAppDir, "logs", "prod-hooks-postDown.txt"))
+  assert.NoError(t, err)
+  assert.Contains(t, string(hooksLog), "cleaned up prod\n")
+}
+
+func TestRunHooksNoHooks(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  err = RunHooks(util.MockAppDir, "prod", getHooksSettings(nil), "preUp")
+  assert.NoError(t, err)
+
+  _, err = os.Stat(filepath.Join(util.MockAppDir, "prod", "logs"))
+  assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunHooksError(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  appSettings := getHooksSettings(&settings.Hooks{
+    PreDown: []settings.Hook{
+      {Name: "allowed", Command: "exit 1", ContinueOnError: true},
+      {Name: "failing", Command: "exit 2"},
+      {Name: "skipped", Command: "echo skipped"},
+    },
+  })
+
+  err = RunHooks(util.MockAppDir, "prod", appSettings, "preDown")
+  assert.Error(t, err)
+  assert.Contains(t, err.Error(), "preDown hook failing failed")
+
+  hooksLog, err := os.ReadFile(filepath.Join(util.MockAppDir, "prod", "logs", "hooks-preDown.txt"))
+  assert.NoError(t, err)
+  assert.Contains(t, string(hooksLog), "hook allowed failed")
+  assert.False(t, strings.Contains(string(hooksLog), "skipped"))
+}
+
+/*
+      Tests for shellQuote
+*/
+func TestShellQuote(t *testing.T) {
+  assert.Equal(t, "'192.168.1.21 192.168.1.22'", shellQuote("192.168.1.21 192.168.1.22"))
+  assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
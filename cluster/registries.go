@@ -0,0 +1,207 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+  The directory registry certificates are installed to
+  on the machines, under the distribution config directory
+*/
+const registryCertsDirName = "registry-certs"
+
+/*
+  K3sRegistries - The k3s registries.yaml file, see
+  https://docs.k3s.io/installation/private-registry
+*/
+type K3sRegistries struct {
+  Mirrors map[string]K3sRegistryMirror   `yaml:"mirrors,omitempty"`
+  Configs map[string]K3sRegistryConfig   `yaml:"configs,omitempty"`
+}
+
+type K3sRegistryMirror struct {
+  Endpoint []string              `yaml:"endpoint"`
+  Rewrite map[string]string      `yaml:"rewrite,omitempty"`
+}
+
+type K3sRegistryConfig struct {
+  Auth *K3sRegistryAuth   `yaml:"auth,omitempty"`
+  Tls *K3sRegistryTls     `yaml:"tls,omitempty"`
+}
+
+type K3sRegistryAuth struct {
+  Username string         `yaml:"username,omitempty"`
+  Password string         `yaml:"password,omitempty"`
+  IdentityToken string    `yaml:"identitytoken,omitempty"`
+}
+
+type K3sRegistryTls struct {
+  CaFile string              `yaml:"ca_file,omitempty"`
+  CertFile string            `yaml:"cert_file,omitempty"`
+  KeyFile string             `yaml:"key_file,omitempty"`
+  InsecureSkipVerify bool    `yaml:"insecure_skip_verify,omitempty"`
+}
+
+/*
+GenerateRegistriesFile - Renders the registries.yaml for a cluster
+into the cluster settings directory (synced to every machine) along
+with any registry certificates. Credentials are read from the env or
+files here so they never have to be in the settings. Nothing is written
+if the cluster has no registry settings
+*/
+func GenerateRegistriesFile(appDir string, clusterName string, appSettings settings.Settings) error {
+  registrySettings := appSettings.GetClusterRegistries(clusterName)
+  if registrySettings == nil {
+    logger.LogDebug("No registry settings for the cluster", "cluster", clusterName)
+    return nil
+  }
+
+  settingsDir := filepath.Join(appDir, clusterName, "settings")
+  certsMachineDir := appSettings.Clusters[clusterName].GetDistribution().ConfigDir + "/" + registryCertsDirName
+  registries := K3sRegistries{
+    Mirrors: make(map[string]K3sRegistryMirror),
+    Configs: make(map[string]K3sRegistryConfig),
+  }
+
+  for registry, mirror := range registrySettings.Mirrors {
+    registries.Mirrors[registry] = K3sRegistryMirror{Endpoint: mirror.Endpoints, Rewrite: mirror.Rewrite}
+  }
+
+  for host, config := range registrySettings.Configs {
+    k3sConfig := K3sRegistryConfig{}
+
+    if config.Auth != nil {
+      auth, err := getRegistryAuth(host, *config.Auth)
+      if err != nil {
+        return err
+      }
+      k3sConfig.Auth = &auth
+    }
+
+    if config.Tls != nil {
+      tls, err := copyRegistryCerts(settingsDir, certsMachineDir, host, *config.Tls)
+      if err != nil {
+        return err
+      }
+      k3sConfig.Tls = &tls
+    }
+    registries.Configs[host] = k3sConfig
+  }
+
+  yamlData, err := yaml.Marshal(&registries)
+  if err != nil {
+    logger.LogError("Error marshaling registries")
+    return err
+  }
+
+  // the file can have credentials in it
+  registriesFile := filepath.Join(settingsDir, "registries.yaml")
+  err = os.WriteFile(registriesFile, append([]byte("---\n"), yamlData...), 0600)
+  if err != nil {
+    logger.LogError("Error writing registries file", "file", registriesFile)
+    return err
+  }
+  return nil
+}
+
+// reads the registry credentials from the env or files
+func getRegistryAuth(host string, auth settings.RegistryAuth) (K3sRegistryAuth, error) {
+  k3sAuth := K3sRegistryAuth{Username: auth.Username}
+
+  readEnv := func(name string) (string, error) {
+    value, exists := os.LookupEnv(name)
+    if !exists || value == "" {
+      logger.LogError("Error registry credential environment variable is not set", "registry", host, "env", name)
+      return "", fmt.Errorf("environment variable %s for registry %s is not set", name, host)
+    }
+    return value, nil
+  }
+
+  var err error
+  if auth.UsernameEnv != "" {
+    k3sAuth.Username, err = readEnv(auth.UsernameEnv)
+    if err != nil {
+      return K3sRegistryAuth{}, err
+    }
+  }
+
+  if auth.PasswordEnv != "" {
+    k3sAuth.Password, err = readEnv(auth.PasswordEnv)
+    if err != nil {
+      return K3sRegistryAuth{}, err
+    }
+  }
+
+  if auth.PasswordFile != "" {
+    password, err := os.ReadFile(auth.PasswordFile)
+    if err != nil {
+      logger.LogError("Error reading registry password file", "registry", host, "file", auth.PasswordFile)
+      return K3sRegistryAuth{}, err
+    }
+    k3sAuth.Password = strings.TrimSpace(string(password))
+  }
+
+  if auth.TokenEnv != "" {
+    k3sAuth.IdentityToken, err = readEnv(auth.TokenEnv)
+    if err != nil {
+      return K3sRegistryAuth{}, err
+    }
+  }
+  return k3sAuth, nil
+}
+
+/*
+copies the tls files for a registry into the cluster settings directory,
+the returned tls settings have the paths the files are installed to on
+the machines
+*/
+func copyRegistryCerts(settingsDir string, certsMachineDir string, host string, tls settings.RegistryTls) (K3sRegistryTls, error) {
+  k3sTls := K3sRegistryTls{InsecureSkipVerify: tls.InsecureSkipVerify}
+
+  // host can have a port in it which is not allowed in windows paths
+  certDirName := strings.ReplaceAll(host, ":", "_")
+  certDir := filepath.Join(settingsDir, registryCertsDirName, certDirName)
+
+  files := []struct {
+    source string
+    name string
+    machinePath *string
+  }{
+    {tls.CaFile, "ca.crt", &k3sTls.CaFile},
+    {tls.CertFile, "client.crt", &k3sTls.CertFile},
+    {tls.KeyFile, "client.key", &k3sTls.KeyFile},
+  }
+
+  for _, file := range files {
+    if file.source == "" {
+      continue
+    }
+
+    content, err := os.ReadFile(file.source)
+    if err != nil {
+      logger.LogError("Error reading registry tls file", "registry", host, "file", file.source)
+      return K3sRegistryTls{}, err
+    }
+
+    err = os.MkdirAll(certDir, 0750)
+    if err != nil {
+      logger.LogError("Error creating registry certs directory")
+      return K3sRegistryTls{}, err
+    }
+
+    err = os.WriteFile(filepath.Join(certDir, file.name), content, 0600)
+    if err != nil {
+      logger.LogError("Error writing registry tls file", "registry", host, "file", file.name)
+      return K3sRegistryTls{}, err
+    }
+    *file.machinePath = certsMachineDir + "/" + certDirName + "/" + file.name
+  }
+  return k3sTls, nil
+}
@@ -0,0 +1,180 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func getRegistryTestSettings(registries *settings.RegistrySettings) settings.Settings {
+  return settings.Settings{
+    Registries: registries,
+    Clusters: map[string]settings.Cluster{
+      "dev": {ClusterType: "single"},
+    },
+  }
+}
+
+func readRegistriesFile(t *testing.T) K3sRegistries {
+  content, err := os.ReadFile(filepath.Join(util.MockAppDir, "dev", "settings", "registries.yaml"))
+  assert.NoError(t, err)
+
+  var registries K3sRegistries
+  assert.NoError(t, yaml.Unmarshal(content, &registries))
+  return registries
+}
+
+/*
+      Tests for GenerateRegistriesFile
+*/
+func TestGenerateRegistriesFileNoRegistries(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  err = os.MkdirAll(filepath.Join(util.MockAppDir, "dev", "settings"), 0755)
+  assert.NoError(t, err)
+
+  err = GenerateRegistriesFile(util.MockAppDir, "dev", getRegistryTestSettings(nil))
+  assert.NoError(t, err)
+  assert.NoFileExists(t, filepath.Join(util.MockAppDir, "dev", "settings", "registries.yaml"))
+}
+
+func TestGenerateRegistriesFile(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  err = os.MkdirAll(filepath.Join(util.MockAppDir, "dev", "settings"), 0755)
+  assert.NoError(t, err)
+
+  certsDir := filepath.Join(util.MockAppDir, "certs")
+  assert.NoError(t, os.MkdirAll(certsDir, 0755))
+  assert.NoError(t, os.WriteFile(filepath.Join(certsDir, "ca.pem"), []byte("ca"), 0644))
+  assert.NoError(t, os.WriteFile(filepath.Join(certsDir, "password"), []byte("file-secret\n"), 0600))
+
+  t.Setenv("TEST_REGISTRY_PASSWORD", "env-secret")
+  t.Setenv("TEST_REGISTRY_TOKEN", "token")
+
+  appSettings := getRegistryTestSettings(&settings.RegistrySettings{
+    Mirrors: map[string]settings.RegistryMirror{
+      "docker.io": {
+        Endpoints: []string{"https://registry.internal:5000"},
+        Rewrite: map[string]string{"^library/(.*)": "mirror/library/$1"},
+      },
+    },
+    Configs: map[string]settings.RegistryConfig{
+      "registry.internal:5000": {
+        Auth: &settings.RegistryAuth{Username: "admin", PasswordEnv: "TEST_REGISTRY_PASSWORD"},
+        Tls: &settings.RegistryTls{CaFile: filepath.Join(certsDir, "ca.pem")},
+      },
+      "other.internal": {
+        Auth: &settings.RegistryAuth{Username: "admin", PasswordFile: filepath.Join(certsDir, "password")},
+      },
+      "token.internal": {
+        Auth: &settings.RegistryAuth{TokenEnv: "TEST_REGISTRY_TOKEN"},
+        Tls: &settings.RegistryTls{InsecureSkipVerify: true},
+      },
+    },
+  })
+
+  err = GenerateRegistriesFile(util.MockAppDir, "dev", appSettings)
+  assert.NoError(t, err)
+
+  registries := readRegistriesFile(t)
+
+  assert.Equal(t, []string{"https://registry.internal:5000"}, registries.Mirrors["docker.io"].Endpoint)
+  assert.Equal(t, "mirror/library/$1", registries.Mirrors["docker.io"].Rewrite["^library/(.*)"])
+
+  internal := registries.Configs["registry.internal:5000"]
+  assert.Equal(t, K3sRegistryAuth{Username: "admin", Password: "env-secret"}, *internal.Auth)
+  assert.Equal(t, "/etc/rancher/k3s/registry-certs/registry.internal_5000/ca.crt", internal.Tls.CaFile)
+  assert.Empty(t, internal.Tls.CertFile)
+
+  assert.Equal(t, "file-secret", registries.Configs["other.internal"].Auth.Password)
+  assert.Equal(t, "token", registries.Configs["token.internal"].Auth.IdentityToken)
+  assert.True(t, registries.Configs["token.internal"].Tls.InsecureSkipVerify)
+
+  ca, err := os.ReadFile(filepath.Join(util.MockAppDir, "dev", "settings", "registry-certs", "registry.internal_5000", "ca.crt"))
+  assert.NoError(t, err)
+  assert.Equal(t, "ca", string(ca))
+
+  info, err := os.Stat(filepath.Join(util.MockAppDir, "dev", "settings", "registries.yaml"))
+  assert.NoError(t, err)
+  assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestGenerateRegistriesFileDistribution(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  err = os.MkdirAll(filepath.Join(util.MockAppDir, "dev", "settings"), 0755)
+  assert.NoError(t, err)
+
+  certsDir := filepath.Join(util.MockAppDir, "certs")
+  assert.NoError(t, os.MkdirAll(certsDir, 0755))
+  assert.NoError(t, os.WriteFile(filepath.Join(certsDir, "ca.pem"), []byte("ca"), 0644))
+
+  appSettings := getRegistryTestSettings(&settings.RegistrySettings{
+    Configs: map[string]settings.RegistryConfig{
+      "registry.internal": {Tls: &settings.RegistryTls{CaFile: filepath.Join(certsDir, "ca.pem")}},
+    },
+  })
+  appSettings.Clusters["dev"] = settings.Cluster{ClusterType: "single",
+    ClusterFeatures: &settings.ClusterFeatures{Distribution: "rke2"}}
+
+  err = GenerateRegistriesFile(util.MockAppDir, "dev", appSettings)
+  assert.NoError(t, err)
+
+  // the certs are installed under the rke2 config directory
+  registries := readRegistriesFile(t)
+  assert.Equal(t, "/etc/rancher/rke2/registry-certs/registry.internal/ca.crt",
+    registries.Configs["registry.internal"].Tls.CaFile)
+}
+
+func TestGenerateRegistriesFileMissingCredential(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  err = os.MkdirAll(filepath.Join(util.MockAppDir, "dev", "settings"), 0755)
+  assert.NoError(t, err)
+
+  appSettings := getRegistryTestSettings(&settings.RegistrySettings{
+    Configs: map[string]settings.RegistryConfig{
+      "registry.internal": {
+        Auth: &settings.RegistryAuth{Username: "admin", PasswordEnv: "TEST_REGISTRY_UNSET_PASSWORD"},
+      },
+    },
+  })
+
+  err = GenerateRegistriesFile(util.MockAppDir, "dev", appSettings)
+  assert.ErrorContains(t, err, "TEST_REGISTRY_UNSET_PASSWORD")
+  assert.NoFileExists(t, filepath.Join(util.MockAppDir, "dev", "settings", "registries.yaml"))
+}
+
+func TestGenerateRegistriesFileMissingCert(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  err = os.MkdirAll(filepath.Join(util.MockAppDir, "dev", "settings"), 0755)
+  assert.NoError(t, err)
+
+  appSettings := getRegistryTestSettings(&settings.RegistrySettings{
+    Configs: map[string]settings.RegistryConfig{
+      "registry.internal": {
+        Tls: &settings.RegistryTls{CaFile: filepath.Join(util.MockAppDir, "missing.pem")},
+      },
+    },
+  })
+
+  err = GenerateRegistriesFile(util.MockAppDir, "dev", appSettings)
+  assert.Error(t, err)
+}
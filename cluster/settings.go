@@ -1,8 +1,10 @@
 package cluster
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/dgutierrez1287/local-kube/logger"
 	"github.com/dgutierrez1287/local-kube/settings"
@@ -17,11 +19,23 @@ import (
 type ScriptSettings struct {
   ClusterName string                  `yaml:"cluster-name,omitempty"`
   ClusterVip string                   `yaml:"cluster-vip,omitempty"`
+  Distribution string                 `yaml:"distribution,omitempty"`
   LeadNode []settings.Machine         `yaml:"lead-control-node"`
   ControlNodes []settings.Machine     `yaml:"control-nodes"`
   WorkerNodes []settings.Machine      `yaml:"workers"`
   MachineSettings settings.Machine    `yaml:"machine_settings,omitempty"`
+
+  // network settings are flat strings so the scripts that run
+  // before yq is installed can read them
+  DnsServers string                   `yaml:"dns-servers,omitempty"`
+  HttpProxy string                    `yaml:"http-proxy,omitempty"`
+  HttpsProxy string                   `yaml:"https-proxy,omitempty"`
+  NoProxy string                      `yaml:"no-proxy,omitempty"`
+  CaCertificates string               `yaml:"ca-certificates,omitempty"`
 }
+
+// the directory in the cluster settings the ca certificates are copied to
+const caCertificatesDirName = "ca-certificates"
   
 /*
 GernerateScriptSettings - This generates a settings yaml file that will be used
@@ -34,6 +48,7 @@ func GenerateScriptSettings(appDir string, clusterName string, appSettings setti
   clusterSettings := appSettings.Clusters[clusterName]
 
   settings.ClusterName = clusterName
+  settings.Distribution = clusterSettings.GetDistribution().Name
 
   if clusterSettings.ClusterFeatures.KubeVipEnable {
     logger.LogDebug("Kube vip enabled setting vip in settings")
@@ -64,6 +79,22 @@ func GenerateScriptSettings(appDir string, clusterName string, appSettings setti
     settings.MachineSettings = clusterSettings.Leaders[0]
   }
 
+  settings.DnsServers = strings.Join(appSettings.GetClusterDnsServers(clusterName), " ")
+
+  if proxy := appSettings.GetClusterProxy(clusterName); proxy != nil {
+    logger.LogDebug("Proxy set, adding proxy to settings")
+    settings.HttpProxy = proxy.HttpProxy
+    settings.HttpsProxy = proxy.GetHttpsProxy()
+    settings.NoProxy = strings.Join(clusterSettings.GetNoProxy(*proxy), ",")
+  }
+
+  caCertificates, err := copyCaCertificates(filepath.Join(appDir, clusterName, "settings"),
+    appSettings.GetClusterCaCertificates(clusterName))
+  if err != nil {
+    return err
+  }
+  settings.CaCertificates = strings.Join(caCertificates, " ")
+
   yamlData, err := yaml.Marshal(&settings)
   if err != nil {
     logger.LogError("Error marshaling script settings to yaml")
@@ -91,3 +122,147 @@ func GenerateScriptSettings(appDir string, clusterName string, appSettings setti
   }
   return nil
 }
+
+/*
+copies the ca certificates into the cluster settings directory (synced
+to every machine), any certificates from a previous run are removed. The
+names of the copied certificates are returned
+*/
+func copyCaCertificates(settingsDir string, certificates []string) ([]string, error) {
+  certificatesDir := filepath.Join(settingsDir, caCertificatesDirName)
+  names := []string{}
+
+  err := os.RemoveAll(certificatesDir)
+  if err != nil {
+    logger.LogError("Error removing old ca certificates")
+    return nil, err
+  }
+
+  if len(certificates) == 0 {
+    return names, nil
+  }
+
+  err = os.MkdirAll(certificatesDir, 0750)
+  if err != nil {
+    logger.LogError("Error creating ca certificates directory")
+    return nil, err
+  }
+
+  for index, certificate := range certificates {
+    content, err := os.ReadFile(certificate)
+    if err != nil {
+      logger.LogError("Error reading ca certificate", "file", certificate)
+      return nil, err
+    }
+
+    // update-ca-certificates only picks up .crt files
+    name := fmt.Sprintf("local-kube-%d.crt", index)
+    err = os.WriteFile(filepath.Join(certificatesDir, name), content, 0644)
+    if err != nil {
+      logger.LogError("Error writing ca certificate", "file", name)
+      return nil, err
+    }
+    names = append(names, name)
+  }
+  return names, nil
+}
+
+/*
+ReadScriptSettings - Reads the script settings file for a cluster
+that has been brought up
+*/
+func ReadScriptSettings(appDir string, clusterName string) (ScriptSettings, error) {
+  settingsFile := filepath.Join(appDir, clusterName, "settings", "settings.yaml")
+  scriptSettings := ScriptSettings{}
+
+  yamlData, err := os.ReadFile(settingsFile)
+  if err != nil {
+    logger.LogError("Error reading the script settings file", "file", settingsFile)
+    return scriptSettings, err
+  }
+
+  err = yaml.Unmarshal(yamlData, &scriptSettings)
+  if err != nil {
+    logger.LogError("Error unmarshaling script settings", "file", settingsFile)
+    return scriptSettings, err
+  }
+  return scriptSettings, nil
+}
+
+/*
+GetDeployedClusterIps - Gets the ips in use by clusters that have
+been brought up (have a cluster directory with script settings),
+the ip is the key and the value is cluster/machine. The cluster
+being checked is left out
+*/
+func GetDeployedClusterIps(appDir string, clusterName string) (map[string]string, error) {
+  usedIps := make(map[string]string)
+
+  err := forEachDeployedMachine(appDir, clusterName,
+    func(deployedCluster string, scriptSettings ScriptSettings, machine settings.Machine) {
+      if machine.IpAddress != "" {
+        usedIps[machine.IpAddress] = deployedCluster + "/" + machine.Name
+      }
+
+      if scriptSettings.ClusterVip != "" {
+        usedIps[scriptSettings.ClusterVip] = deployedCluster + "/vip"
+      }
+    })
+  if err != nil {
+    return nil, err
+  }
+  return usedIps, nil
+}
+
+/*
+GetDeployedClusterPorts - Gets the host ports forwarded by clusters that
+have been brought up, the port key (ex 8443/tcp) is the key and the value
+is cluster/machine. The cluster being checked is left out
+*/
+func GetDeployedClusterPorts(appDir string, clusterName string) (map[string]string, error) {
+  usedPorts := make(map[string]string)
+
+  err := forEachDeployedMachine(appDir, clusterName,
+    func(deployedCluster string, scriptSettings ScriptSettings, machine settings.Machine) {
+      for _, port := range machine.ForwardedPorts {
+        usedPorts[port.GetPortKey()] = deployedCluster + "/" + machine.Name
+      }
+    })
+  if err != nil {
+    return nil, err
+  }
+  return usedPorts, nil
+}
+
+// runs a function on every machine of the clusters that have been
+// brought up (have a cluster directory with script settings), the
+// cluster being checked is left out
+func forEachDeployedMachine(appDir string, clusterName string,
+  visit func(deployedCluster string, scriptSettings ScriptSettings, machine settings.Machine)) error {
+  settingsFiles, err := filepath.Glob(filepath.Join(appDir, "*", "settings", "settings.yaml"))
+  if err != nil {
+    logger.LogError("Error listing deployed cluster settings")
+    return err
+  }
+
+  for _, settingsFile := range settingsFiles {
+    deployedCluster := filepath.Base(filepath.Dir(filepath.Dir(settingsFile)))
+    if deployedCluster == clusterName {
+      continue
+    }
+
+    scriptSettings, err := ReadScriptSettings(appDir, deployedCluster)
+    if err != nil {
+      return err
+    }
+
+    machines := append(append(append([]settings.Machine{}, scriptSettings.LeadNode...),
+      scriptSettings.ControlNodes...), scriptSettings.WorkerNodes...)
+    machines = append(machines, scriptSettings.MachineSettings)
+
+    for _, machine := range machines {
+      visit(deployedCluster, scriptSettings, machine)
+    }
+  }
+  return nil
+}
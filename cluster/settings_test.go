@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for GenerateScriptSettings
+*/
+func TestGenerateScriptSettingsProxy(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  settingsDir := filepath.Join(util.MockAppDir, "dev", "settings")
+  assert.NoError(t, os.MkdirAll(filepath.Join(settingsDir, "ca-certificates"), 0755))
+  assert.NoError(t, os.WriteFile(filepath.Join(settingsDir, "ca-certificates", "old.crt"), []byte("old"), 0644))
+
+  caFile := filepath.Join(util.MockAppDir, "root.pem")
+  assert.NoError(t, os.WriteFile(caFile, []byte("root ca"), 0644))
+
+  appSettings := settings.Settings{
+    Network: &settings.NetworkSettings{Dns: []string{"10.0.0.2", "10.0.0.3"}},
+    Proxy: &settings.ProxySettings{HttpProxy: "http://proxy.corp:3128"},
+    CaCertificates: []string{caFile},
+    Clusters: map[string]settings.Cluster{
+      "dev": {
+        ClusterType: "single",
+        Leaders: []settings.Machine{{Name: "dev", IpAddress: "192.168.1.10"}},
+        ClusterFeatures: &settings.ClusterFeatures{},
+      },
+    },
+  }
+
+  err = GenerateScriptSettings(util.MockAppDir, "dev", appSettings)
+  assert.NoError(t, err)
+
+  scriptSettings, err := ReadScriptSettings(util.MockAppDir, "dev")
+  assert.NoError(t, err)
+
+  assert.Equal(t, "10.0.0.2 10.0.0.3", scriptSettings.DnsServers)
+  assert.Equal(t, "http://proxy.corp:3128", scriptSettings.HttpProxy)
+  assert.Equal(t, "http://proxy.corp:3128", scriptSettings.HttpsProxy)
+  assert.Equal(t, "localhost,127.0.0.1,.svc,.cluster.local,dev,192.168.1.10,10.42.0.0/16,10.43.0.0/16",
+    scriptSettings.NoProxy)
+  assert.Equal(t, "local-kube-0.crt", scriptSettings.CaCertificates)
+
+  // certificates from an earlier run are removed
+  files, err := os.ReadDir(filepath.Join(settingsDir, "ca-certificates"))
+  assert.NoError(t, err)
+  assert.Len(t, files, 1)
+
+  content, err := os.ReadFile(filepath.Join(settingsDir, "ca-certificates", "local-kube-0.crt"))
+  assert.NoError(t, err)
+  assert.Equal(t, "root ca", string(content))
+}
+
+func TestGenerateScriptSettingsNoProxy(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+  defer util.MockAppDirCleanup()
+
+  assert.NoError(t, os.MkdirAll(filepath.Join(util.MockAppDir, "dev", "settings"), 0755))
+
+  appSettings := settings.Settings{
+    Clusters: map[string]settings.Cluster{
+      "dev": {
+        ClusterType: "single",
+        Leaders: []settings.Machine{{Name: "dev", IpAddress: "192.168.1.10"}},
+        ClusterFeatures: &settings.ClusterFeatures{},
+      },
+    },
+  }
+
+  err = GenerateScriptSettings(util.MockAppDir, "dev", appSettings)
+  assert.NoError(t, err)
+
+  scriptSettings, err := ReadScriptSettings(util.MockAppDir, "dev")
+  assert.NoError(t, err)
+
+  assert.Equal(t, "8.8.8.8 8.8.4.4", scriptSettings.DnsServers)
+  assert.Empty(t, scriptSettings.HttpProxy)
+  assert.Empty(t, scriptSettings.NoProxy)
+  assert.Empty(t, scriptSettings.CaCertificates)
+}
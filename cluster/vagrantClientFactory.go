@@ -7,11 +7,19 @@ import (
 type VagrantClientInterface interface {
   Status() *vagrant.StatusCommand
   Up() *vagrant.UpCommand
+  UpNoProvision() *vagrant.UpCommand
+  Halt() *vagrant.HaltCommand
   Destroy() *vagrant.DestroyCommand
   SshConfig() *vagrant.SSHConfigCommand
 }
 
-//type VagrantClientFactory func(vagrantDirPath string) (VagrantClientInterface, error)
+type VagrantClientFactory func(vagrantDirPath string) (VagrantClientInterface, error)
+
+/*
+Gets the vagrant client for a cluster directory, this is
+a variable so tests can swap in a mock client
+*/
+var NewVagrantClient VagrantClientFactory = newDefaultVagrantClient
 
 type DefaultVagrantClient struct {
   client *vagrant.VagrantClient
@@ -25,6 +33,16 @@ func (v *DefaultVagrantClient) Up() *vagrant.UpCommand {
   return v.client.Up()
 }
 
+func (v *DefaultVagrantClient) UpNoProvision() *vagrant.UpCommand {
+  upCmd := v.client.Up()
+  upCmd.Provisioning = vagrant.DisableProvisioning
+  return upCmd
+}
+
+func (v *DefaultVagrantClient) Halt() *vagrant.HaltCommand {
+  return v.client.Halt()
+}
+
 func (v *DefaultVagrantClient) SshConfig() *vagrant.SSHConfigCommand {
   return v.client.SSHConfig()
 }
@@ -33,7 +51,7 @@ func (v *DefaultVagrantClient) Destroy() *vagrant.DestroyCommand {
   return v.client.Destroy()
 }
 
-func NewVagrantClient(vagrantDirPath string) (VagrantClientInterface, error) {
+func newDefaultVagrantClient(vagrantDirPath string) (VagrantClientInterface, error) {
   client, err := vagrant.NewVagrantClient(vagrantDirPath)
   if err != nil {
     return nil, err
@@ -20,6 +20,16 @@ func (m *MockVagrantClient) Up() *vagrant.UpCommand {
 	return args.Get(0).(*vagrant.UpCommand)
 }
 
+func (m *MockVagrantClient) UpNoProvision() *vagrant.UpCommand {
+	args := m.Called()
+	return args.Get(0).(*vagrant.UpCommand)
+}
+
+func (m *MockVagrantClient) Halt() *vagrant.HaltCommand {
+	args := m.Called()
+	return args.Get(0).(*vagrant.HaltCommand)
+}
+
 func (m *MockVagrantClient) Destroy() *vagrant.DestroyCommand {
 	args := m.Called()
 	return args.Get(0).(*vagrant.DestroyCommand)
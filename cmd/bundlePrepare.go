@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/dgutierrez1287/local-kube/bundle"
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/spf13/cobra"
+)
+
+/*
+  the kube versions to bundle k3s for, by default this is
+  the kube version of the cluster or every cluster
+*/
+var bundleKubeVersions []string
+
+/*
+  the python version of the machines, the ansible wheels
+  are downloaded for this version
+*/
+var bundlePythonVersion string
+
+// download everything again even if it is already in the bundle
+var bundleForce bool
+
+var bundlePrepareCmd = &cobra.Command{
+  Use: "bundle-prepare",
+  Short: "Prepares the offline bundle",
+  Long: "Downloads k3s, the k3s airgap images, yq, the ansible python wheels and collections into the offline bundle so clusters can be brought up with cluster-up --offline",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    if !machineOutput {
+      fmt.Println(util.TitleText)
+    }
+
+    appDir := getAppDir()
+
+    logger.LogInfo("Reading settings file")
+    appSettings, err := settings.ReadSettingsFile(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error reading settings", 200, err)
+    }
+
+    kubeVersions := bundleKubeVersions
+    if len(kubeVersions) == 0 {
+      kubeVersions, err = getBundleKubeVersions(appSettings)
+      if err != nil {
+        logger.LogErrorExit("Error getting the kube versions to bundle", 200, err)
+      }
+    }
+
+    artifacts := bundle.GetToolArtifacts()
+    for _, kubeVersion := range kubeVersions {
+      k3sRelease, err := bundle.GetK3sRelease(kubeVersion)
+      if err != nil {
+        logger.LogErrorExit("Error getting the k3s release to bundle", 200, err)
+      }
+      logger.LogInfo("Bundling k3s", "kubeVersion", kubeVersion, "k3sRelease", k3sRelease)
+      artifacts = append(artifacts, bundle.GetK3sArtifacts(k3sRelease)...)
+    }
+
+    err = bundle.DownloadArtifacts(appDir, artifacts, bundleForce)
+    if err != nil {
+      logger.LogErrorExit("Error downloading to the offline bundle", 100, err)
+    }
+
+    err = bundle.DownloadWheels(appDir, appSettings.ProvisionSettings.AnsibleVersion, bundlePythonVersion, bundleForce)
+    if err != nil {
+      logger.LogErrorExit("Error downloading python wheels to the offline bundle", 100, err)
+    }
+
+    if machineOutput {
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.StatusMessage = "offline bundle prepared"
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+    logger.LogInfo("Offline bundle prepared", "path", bundle.GetBundleDir(appDir))
+  },
+}
+
+/*
+gets the kube versions of the cluster (or all clusters if no
+cluster is set) with extends resolved, clusters without a kube
+version use the default kube version
+*/
+func getBundleKubeVersions(appSettings settings.Settings) ([]string, error) {
+  catalog, err := settings.GetVersionCatalog()
+  if err != nil {
+    return nil, err
+  }
+
+  clusterNames := []string{clusterName}
+  if clusterName == "" {
+    clusterNames = []string{}
+    for name := range appSettings.Clusters {
+      clusterNames = append(clusterNames, name)
+    }
+    sort.Strings(clusterNames)
+  }
+
+  kubeVersions := []string{}
+  seen := make(map[string]bool)
+  for _, name := range clusterNames {
+    resolvedCluster, err := appSettings.ResolveCluster(name)
+    if err != nil {
+      return nil, err
+    }
+
+    kubeVersion := catalog.DefaultKubeVersion
+    if resolvedCluster.ClusterFeatures != nil && resolvedCluster.ClusterFeatures.KubeVersion != "" {
+      kubeVersion = resolvedCluster.ClusterFeatures.KubeVersion
+    }
+
+    if !seen[kubeVersion] {
+      seen[kubeVersion] = true
+      kubeVersions = append(kubeVersions, kubeVersion)
+    }
+  }
+
+  if len(kubeVersions) == 0 {
+    kubeVersions = append(kubeVersions, catalog.DefaultKubeVersion)
+  }
+  return kubeVersions, nil
+}
+
+func init() {
+  // command specific args
+  bundlePrepareCmd.PersistentFlags().StringSliceVarP(&bundleKubeVersions, "kube-version", "", []string{}, "Kube versions to bundle k3s for (default is the kube version of the cluster or every cluster)")
+  bundlePrepareCmd.PersistentFlags().StringVarP(&bundlePythonVersion, "python-version", "", "3.12", "The python version of the machines")
+  bundlePrepareCmd.PersistentFlags().BoolVarP(&bundleForce, "force", "", false, "Download everything again even if it is already in the bundle")
+
+  // add command
+  RootCmd.AddCommand(bundlePrepareCmd)
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/dgutierrez1287/local-kube/cluster"
 	"github.com/dgutierrez1287/local-kube/logger"
-	"github.com/dgutierrez1287/local-kube/settings"
 	"github.com/dgutierrez1287/local-kube/util"
 	"github.com/spf13/cobra"
 )
@@ -21,7 +20,7 @@ var clusterCleanCmd = &cobra.Command{
       fmt.Println(util.TitleText)
     }
 
-    appDir := settings.GetAppDirPath()
+    appDir := getAppDir()
 
     logger.LogInfo("Removing the cluster directory")
     err := cluster.DeleteClusterDir(appDir, clusterName)
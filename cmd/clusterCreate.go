@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+/*
+  create the cluster only from flags, without
+  prompting for anything (used for scripting)
+*/
+var createNonInteractive bool
+
+// cluster definition flags
+var createClusterType string
+var createProviderName string
+var createKubeConfigName string
+var createLeaderCount int
+var createWorkerCount int
+var createLeaderMemory int
+var createLeaderCpu int
+var createLeaderDisk string
+var createWorkerMemory int
+var createWorkerCpu int
+var createWorkerDisk string
+var createStartIp string
+var createVip string
+var createKubeVersion string
+var createDistribution string
+var createCni string
+var createStorage string
+var createKubeVip bool
+
+var clusterCreateCmd = &cobra.Command{
+  Use: "cluster-create",
+  Short: "Creates a new cluster definition",
+  Long: "Creates a new cluster definition in the settings file, by default this will prompt for the cluster settings",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && !createNonInteractive {
+      logger.Logger.Error("Error machine output can only be used with --non-interactive")
+      os.Exit(20)
+    }
+
+    if !machineOutput {
+      fmt.Println(util.TitleText)
+    }
+
+    appDir := getAppDir()
+
+    logger.LogInfo("Reading settings file")
+    appSettings, err := settings.ReadSettingsFile(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error reading settings", 200, err)
+    }
+
+    providerNames := appSettings.GetProviderNames()
+    if len(providerNames) == 0 {
+      logger.LogErrorExit("Error no providers are defined in settings, add a provider first", 200, nil)
+    }
+
+    if !createNonInteractive {
+      runClusterCreatePrompts(cmd, providerNames)
+    }
+
+    if clusterName == "" {
+      logger.LogErrorExit("Error a cluster name is required", 200, nil)
+    }
+
+    // single clusters are always one node
+    if createClusterType == "single" {
+      createLeaderCount = 1
+      createWorkerCount = 0
+    }
+
+    spec := settings.ClusterSpec{
+      ClusterType: createClusterType,
+      ProviderName: createProviderName,
+      KubeConfigName: createKubeConfigName,
+      LeaderCount: createLeaderCount,
+      WorkerCount: createWorkerCount,
+      LeaderSize: settings.MachineSize{
+        Memory: createLeaderMemory,
+        Cpu: createLeaderCpu,
+        DiskSize: createLeaderDisk,
+      },
+      WorkerSize: settings.MachineSize{
+        Memory: createWorkerMemory,
+        Cpu: createWorkerCpu,
+        DiskSize: createWorkerDisk,
+      },
+      StartIp: createStartIp,
+      Vip: createVip,
+      Features: settings.ClusterFeatures{
+        Distribution: createDistribution,
+        KubeVersion: createKubeVersion,
+        CniController: createCni,
+        StorageController: createStorage,
+        KubeVipEnable: createKubeVip,
+      },
+    }
+
+    logger.LogInfo("Building cluster definition", "cluster", clusterName)
+    newCluster, err := settings.NewCluster(clusterName, spec)
+    if err != nil {
+      logger.LogErrorExit("Error building the cluster definition", 200, err)
+    }
+
+    err = appSettings.AddCluster(clusterName, newCluster)
+    if err != nil {
+      logger.LogErrorExit("Error adding the cluster to settings", 200, err)
+    }
+
+    logger.LogInfo("Validating new cluster")
+    validationErrors := appSettings.ValidateCluster(clusterName)
+    if len(validationErrors) > 0 {
+      for _, validationError := range validationErrors {
+        logger.LogError(validationError.Error())
+      }
+      logger.LogErrorExit("Error the new cluster is not valid", 210, validationErrors)
+    }
+
+    logger.LogInfo("Writing settings file")
+    err = settings.WriteSettingsFile(appDir, appSettings)
+    if err != nil {
+      logger.LogErrorExit("Error writing settings file", 200, err)
+    }
+
+    if !machineOutput {
+      logger.LogInfo("Cluster created, use cluster-up to bring it up", "cluster", clusterName)
+      os.Exit(0)
+    } else {
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.StatusMessage = fmt.Sprintf("cluster %s created", clusterName)
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+  },
+}
+
+/*
+Prompts for all the cluster settings, flags that were
+set are used as the defaults for the prompts
+*/
+func runClusterCreatePrompts(cmd *cobra.Command, providerNames []string) {
+  clusterName = promptString("Cluster name", clusterName, validateNotEmpty)
+  createClusterType = promptSelect("Cluster type", []string{"single", "ha"}, createClusterType)
+  createProviderName = promptSelect("Provider", providerNames, createProviderName)
+
+  if createClusterType == "ha" {
+    createLeaderCount = promptInt("Number of leader (control plane) nodes", createLeaderCount)
+    createWorkerCount = promptInt("Number of worker nodes", createWorkerCount)
+  }
+
+  createLeaderMemory = promptInt("Leader memory (MB)", createLeaderMemory)
+  createLeaderCpu = promptInt("Leader cpus", createLeaderCpu)
+  createLeaderDisk = promptString("Leader disk size", createLeaderDisk, validateNotEmpty)
+
+  if createClusterType == "ha" && createWorkerCount > 0 {
+    createWorkerMemory = promptInt("Worker memory (MB)", createWorkerMemory)
+    createWorkerCpu = promptInt("Worker cpus", createWorkerCpu)
+    createWorkerDisk = promptString("Worker disk size", createWorkerDisk, validateNotEmpty)
+  }
+
+  createStartIp = promptString("First machine ip, machines get sequential ips (blank to use the provider ip pool)",
+    createStartIp, validateIpOrEmpty)
+
+  createDistribution = promptSelect("Kubernetes distribution", settings.GetDistributionNames(), createDistribution)
+  distribution := settings.ClusterFeatures{Distribution: createDistribution}.GetDistribution()
+
+  if createCni == "" {
+    createCni = distribution.Defaults.CniController
+  }
+  if createStorage == "" {
+    createStorage = distribution.Defaults.StorageController
+  }
+  createCni = promptSelect("Cni controller", distribution.CniControllers, createCni)
+  createStorage = promptSelect("Storage controller", distribution.StorageControllers, createStorage)
+
+  // ha clusters always need kubevip
+  if createClusterType == "ha" {
+    createKubeVip = true
+  } else if !cmd.Flags().Changed("kube-vip") {
+    createKubeVip = promptSelect("Enable kube-vip", []string{"no", "yes"}, "no") == "yes"
+  }
+
+  if createKubeVip {
+    createVip = promptString("Cluster vip (blank for the ip after the machines or the provider ip pool)", createVip, validateIpOrEmpty)
+  }
+}
+
+// prompts for a string value
+func promptString(label string, defaultValue string, validate promptui.ValidateFunc) string {
+  prompt := promptui.Prompt{
+    Label: label,
+    Default: defaultValue,
+    Validate: validate,
+  }
+
+  result, err := prompt.Run()
+  if err != nil {
+    logger.LogErrorExit("Error running prompt", 100, err)
+  }
+  return result
+}
+
+// prompts for a number
+func promptInt(label string, defaultValue int) int {
+  result := promptString(label, strconv.Itoa(defaultValue), validatePositiveInt)
+
+  value, _ := strconv.Atoi(result)
+  return value
+}
+
+// prompts to select from a list, the default is moved to the top
+func promptSelect(label string, items []string, defaultValue string) string {
+  cursorPosition := 0
+  for index, item := range items {
+    if item == defaultValue {
+      cursorPosition = index
+    }
+  }
+
+  prompt := promptui.Select{
+    Label: label,
+    Items: items,
+    CursorPos: cursorPosition,
+  }
+
+  _, result, err := prompt.Run()
+  if err != nil {
+    logger.LogErrorExit("Error running prompt", 100, err)
+  }
+  return result
+}
+
+func validateNotEmpty(input string) error {
+  if input == "" {
+    return errors.New("a value is required")
+  }
+  return nil
+}
+
+func validatePositiveInt(input string) error {
+  value, err := strconv.Atoi(input)
+  if err != nil || value < 0 {
+    return errors.New("must be a whole number")
+  }
+  return nil
+}
+
+func validateIpOrEmpty(input string) error {
+  if input != "" && net.ParseIP(input) == nil {
+    return errors.New("must be a valid ip address")
+  }
+  return nil
+}
+
+func init() {
+  // command specific args
+  clusterCreateCmd.PersistentFlags().BoolVarP(&createNonInteractive, "non-interactive", "", false, "Create the cluster from flags only without prompting")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createClusterType, "type", "", "single", "The cluster type (single or ha)")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createProviderName, "provider", "", "", "The name of the provider to use")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createKubeConfigName, "kubeconfig-name", "", "", "The name for the cluster in kubeconfig")
+  clusterCreateCmd.PersistentFlags().IntVarP(&createLeaderCount, "leaders", "", 3, "The number of leader nodes (ha only)")
+  clusterCreateCmd.PersistentFlags().IntVarP(&createWorkerCount, "workers", "", 2, "The number of worker nodes (ha only)")
+  clusterCreateCmd.PersistentFlags().IntVarP(&createLeaderMemory, "leader-memory", "", 4096, "Memory for leader nodes in MB")
+  clusterCreateCmd.PersistentFlags().IntVarP(&createLeaderCpu, "leader-cpus", "", 2, "Cpus for leader nodes")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createLeaderDisk, "leader-disk", "", "50GB", "Disk size for leader nodes")
+  clusterCreateCmd.PersistentFlags().IntVarP(&createWorkerMemory, "worker-memory", "", 4096, "Memory for worker nodes in MB")
+  clusterCreateCmd.PersistentFlags().IntVarP(&createWorkerCpu, "worker-cpus", "", 2, "Cpus for worker nodes")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createWorkerDisk, "worker-disk", "", "50GB", "Disk size for worker nodes")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createStartIp, "start-ip", "", "", "The ip of the first machine, machines get sequential ips (blank to use the provider ip pool)")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createVip, "vip", "", "", "The vip for the cluster (blank to use the ip after the machines, or the provider ip pool without --start-ip)")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createKubeVersion, "kube-version", "", "", "The kubernetes version (default is used if empty)")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createDistribution, "distribution", "", "k3s", "The kubernetes distribution (k3s or rke2)")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createCni, "cni", "", "", "The cni controller (flannel, canal, cilium or calico, default is built in to the distribution)")
+  clusterCreateCmd.PersistentFlags().StringVarP(&createStorage, "storage", "", "", "The storage controller (local-storage or longhorn, default is the distribution default)")
+  clusterCreateCmd.PersistentFlags().BoolVarP(&createKubeVip, "kube-vip", "", false, "Enable kube-vip (always enabled for ha clusters)")
+
+  // add command
+  RootCmd.AddCommand(clusterCreateCmd)
+}
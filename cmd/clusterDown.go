@@ -30,7 +30,7 @@ var clusterDownCmd = &cobra.Command {
       fmt.Println(util.TitleText)
     }
 
-    appDir := settings.GetAppDirPath()
+    appDir := getAppDir()
 
     logger.LogInfo("Reading settings file")
     // read the settings.json file
@@ -39,6 +39,14 @@ var clusterDownCmd = &cobra.Command {
       logger.LogErrorExit("Error reading settings", 200, err)
     }
 
+    appSettings.Clusters[clusterName] = resolveDeployedCluster(&appSettings, clusterName)
+
+    // ips from the provider ip pool are needed by the hooks
+    err = appSettings.ApplyIpAllocations(appDir, clusterName)
+    if err != nil {
+      logger.LogErrorExit("Error reading the cluster ip allocations", 200, err)
+    }
+
     // run a check to make sure the cluster is there and figure out how much action is 
     // needed
     created, createdStatus, err := cluster.CheckForExistingCluster(appDir, clusterName, machineOutput)
@@ -74,12 +82,24 @@ var clusterDownCmd = &cobra.Command {
     if created && createdStatus == "created" {
         logger.LogInfo("Machines for cluster exit, destroying the cluster")
 
+      err := cluster.RunHooks(appDir, clusterName, appSettings, "preDown")
+      if err != nil {
+        logger.LogErrorExit("Error running the preDown hooks", 110, err)
+      }
+
       // destroy the cluster
-      err := cluster.ClusterDown(appDir, clusterName, machineOutput)
+      err = cluster.ClusterDown(appDir, clusterName, machineOutput)
       if err != nil {
         logger.LogErrorExit("Error destroying the cluster machines", 110, err)
       }
 
+      // the postDown hooks log is in the app directory so it is
+      // kept when the cluster directory is deleted
+      err = cluster.RunHooks(appDir, clusterName, appSettings, "postDown")
+      if err != nil {
+        logger.LogErrorExit("Error running the postDown hooks", 110, err)
+      }
+
       // delete the cluster directory
       err = cluster.DeleteClusterDir(appDir, clusterName)
       if err != nil {
@@ -88,13 +108,7 @@ var clusterDownCmd = &cobra.Command {
 
       logger.LogInfo("Starting kubeconfig update")
       kubeConfigPath := appSettings.KubeConfigPath
-      var kubeConfigClusterName string
-
-      if appSettings.Clusters[clusterName].KubeConfigName == "" {
-        kubeConfigClusterName = clusterName
-      } else {
-        kubeConfigClusterName = appSettings.Clusters[clusterName].KubeConfigName
-      }
+      kubeConfigClusterName := appSettings.Clusters[clusterName].GetKubeConfigName(clusterName)
 
       logger.LogInfo("Backing up current kubeconfig")
       err = kubeconfig.BackupKubeConfig(appDir, kubeConfigPath)
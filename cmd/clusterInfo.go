@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/spf13/cobra"
+)
+
+/*
+  show the effective cluster settings with
+  everything the cluster extends merged in
+*/
+var infoResolved bool
+
+var clusterInfoCmd = &cobra.Command{
+  Use: "cluster-info",
+  Short: "Shows the settings for a cluster",
+  Long: "Shows the settings for a cluster as they are in the settings file, or with --resolved the effective settings after extends are applied",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    if !machineOutput {
+      fmt.Println(util.TitleText)
+    }
+
+    appDir := getAppDir()
+
+    logger.LogInfo("Reading settings file")
+    appSettings, err := settings.ReadSettingsFile(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error reading settings", 200, err)
+    }
+
+    if !appSettings.SettingsValid(clusterName) {
+      logger.LogErrorExit("Error cluster is not present in settings", 200, nil)
+    }
+
+    clusterSettings := appSettings.Clusters[clusterName]
+    if infoResolved {
+      logger.LogDebug("Resolving cluster settings", "cluster", clusterName)
+      clusterSettings, err = appSettings.ResolveCluster(clusterName)
+      if err != nil {
+        logger.LogErrorExit("Error resolving cluster settings", 200, err)
+      }
+    }
+
+    clusterJson, err := json.MarshalIndent(clusterSettings, "", " ")
+    if err != nil {
+      logger.LogErrorExit("Error encoding cluster settings", 200, err)
+    }
+
+    if !machineOutput {
+      fmt.Println(string(clusterJson))
+      os.Exit(0)
+    } else {
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.ClusterSettings = clusterJson
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+  },
+}
+
+func init() {
+  // command specific args
+  clusterInfoCmd.PersistentFlags().BoolVarP(&infoResolved, "resolved", "", false, "Show the effective settings with extends applied")
+
+  // required args for this command
+  clusterInfoCmd.MarkFlagRequired("cluster")
+
+  // add command
+  RootCmd.AddCommand(clusterInfoCmd)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dgutierrez1287/local-kube/cluster"
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/spf13/cobra"
+)
+
+/*
+  how long to wait for the api server to be ready after the
+  machines are started
+*/
+var apiTimeout time.Duration
+
+var clusterStartCmd = &cobra.Command {
+  Use: "cluster-start",
+  Short: "Starts a stopped cluster",
+  Long: "Starts the machines of a stopped cluster without provisioning, control nodes are started before workers",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    if !machineOutput {
+      fmt.Println(util.TitleText)
+    }
+
+    appDir := getAppDir()
+
+    logger.LogInfo("Reading settings file")
+    // read the settings.json file
+    appSettings, err := settings.ReadSettingsFile(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error reading settings", 200, err)
+    }
+
+    resolvedCluster, err := appSettings.ResolveCluster(clusterName)
+    if err != nil {
+      logger.LogErrorExit("Error resolving cluster settings", 200, err)
+    }
+    appSettings.Clusters[clusterName] = resolvedCluster
+
+    // ips from the provider ip pool
+    err = appSettings.ApplyIpAllocations(appDir, clusterName)
+    if err != nil {
+      logger.LogErrorExit("Error reading the cluster ip allocations", 200, err)
+    }
+
+    // the machines have to exist to be started
+    created, createdStatus, err := cluster.CheckForExistingCluster(appDir, clusterName, machineOutput)
+    if err != nil {
+      logger.LogErrorExit("Error checking cluster status", 110, err)
+    }
+
+    if !created || createdStatus != "created" {
+      logger.LogErrorExit("Error cluster machines do not exist, run cluster-up to create the cluster", 110,
+        errors.New("cluster machines not created"))
+    }
+
+    logger.LogInfo("Starting the cluster machines")
+    err = cluster.ClusterStart(appDir, clusterName, appSettings, machineOutput)
+    if err != nil {
+      logger.LogErrorExit("Error starting the cluster", 100, err)
+    }
+
+    err = cluster.WaitForApiServer(appDir, clusterName, appSettings, apiTimeout)
+    if err != nil {
+      logger.LogErrorExit("Error waiting for the api server", 100, err)
+    }
+
+    clusterStatus, statuses, err := cluster.GetDetailedClusterStatus(appDir, clusterName, machineOutput)
+    if err != nil {
+      logger.LogErrorExit("Error getting detailed cluster status", 110, err)
+    }
+
+    if !machineOutput {
+      logger.LogInfo("Cluster started and the api server is ready", "status", clusterStatus)
+      logger.Logger.Info("detailedStatuses", statuses)
+    } else {
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.DirectoryCreated = true
+      machineReadableOutput.ClusterStatus = clusterStatus
+      machineReadableOutput.DetailedMachineStatus = statuses
+      machineReadableOutput.StatusMessage = "cluster started and the api server is ready"
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+  },
+}
+
+func init() {
+  // command specific args
+  clusterStartCmd.PersistentFlags().DurationVarP(&apiTimeout, "api-timeout", "", 5 * time.Minute, "How long to wait for the api server to be ready")
+
+  // required args for this command
+  clusterStartCmd.MarkFlagRequired("cluster")
+
+  // add command
+  RootCmd.AddCommand(clusterStartCmd)
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/dgutierrez1287/local-kube/cluster"
 	"github.com/dgutierrez1287/local-kube/logger"
 	"github.com/dgutierrez1287/local-kube/output"
-	"github.com/dgutierrez1287/local-kube/settings"
 	"github.com/dgutierrez1287/local-kube/util"
 	"github.com/spf13/cobra"
 )
@@ -30,7 +29,7 @@ var clusterStatusCmd = &cobra.Command{
 
     logger.Logger.Debug("machineOutput flag is", "value", machineOutput)
 
-    appDir := settings.GetAppDirPath()
+    appDir := getAppDir()
     
     // Run an initial check if the cluster directory exists and see if any machines are present
     created, createdStatus, err := cluster.CheckForExistingCluster(appDir, clusterName, machineOutput)
@@ -63,16 +62,31 @@ var clusterStatusCmd = &cobra.Command{
         logger.LogErrorExit("Error getting detailed cluster status", 110, err)
       }
 
+      // addons can only be checked on a running cluster
+      var addonStatuses map[string]string
+      if clusterStatus == "running" {
+        logger.LogInfo("Getting addon status")
+
+        addonStatuses, err = cluster.GetAddonStatus(appDir, clusterName)
+        if err != nil {
+          logger.LogErrorExit("Error getting addon status", 110, err)
+        }
+      }
+
       // output status in the desired format 
       if !machineOutput {
         logger.Logger.Info("Cluster status is", "status", clusterStatus)
         logger.Logger.Info("detailedStatuses", statuses)
+        if len(addonStatuses) > 0 {
+          logger.Logger.Info("addonStatuses", addonStatuses)
+        }
         os.Exit(0)
       } else {
         machineReadableOutput.ExitCode = 0
         machineReadableOutput.DirectoryCreated = true
         machineReadableOutput.ClusterStatus = clusterStatus
         machineReadableOutput.DetailedMachineStatus = statuses
+        machineReadableOutput.AddonStatus = addonStatuses
         output, eCode := machineReadableOutput.GetMachineOutputJson()
         fmt.Println(output)
         os.Exit(eCode)
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dgutierrez1287/local-kube/cluster"
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/spf13/cobra"
+)
+
+var clusterStopCmd = &cobra.Command {
+  Use: "cluster-stop",
+  Short: "Stops a cluster",
+  Long: "Stops the machines of a cluster without destroying them, workers are stopped before control nodes",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    if !machineOutput {
+      fmt.Println(util.TitleText)
+    }
+
+    appDir := getAppDir()
+
+    logger.LogInfo("Reading settings file")
+    // read the settings.json file
+    appSettings, err := settings.ReadSettingsFile(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error reading settings", 200, err)
+    }
+
+    appSettings.Clusters[clusterName] = resolveDeployedCluster(&appSettings, clusterName)
+
+    // ips from the provider ip pool
+    err = appSettings.ApplyIpAllocations(appDir, clusterName)
+    if err != nil {
+      logger.LogErrorExit("Error reading the cluster ip allocations", 200, err)
+    }
+
+    created, createdStatus, err := cluster.CheckForExistingCluster(appDir, clusterName, machineOutput)
+    if err != nil {
+      logger.LogErrorExit("Error checking cluster status", 110, err)
+    }
+
+    // If there are no machines there is nothing to stop
+    if !created || createdStatus != "created" {
+      if !machineOutput {
+        logger.LogInfo("Cluster machines do not exist, nothing to stop")
+        os.Exit(0)
+      } else {
+        machineReadableOutput.ExitCode = 0
+        machineReadableOutput.DirectoryCreated = created
+        machineReadableOutput.ClusterStatus = "not created"
+        machineReadableOutput.StatusMessage = "Cluster machines do not exist, nothing to stop"
+        output, eCode := machineReadableOutput.GetMachineOutputJson()
+        fmt.Println(output)
+        os.Exit(eCode)
+      }
+    }
+
+    logger.LogInfo("Stopping the cluster machines")
+    err = cluster.ClusterStop(appDir, clusterName, appSettings, machineOutput)
+    if err != nil {
+      logger.LogErrorExit("Error stopping the cluster", 100, err)
+    }
+
+    clusterStatus, statuses, err := cluster.GetDetailedClusterStatus(appDir, clusterName, machineOutput)
+    if err != nil {
+      logger.LogErrorExit("Error getting detailed cluster status", 110, err)
+    }
+
+    if !machineOutput {
+      logger.LogInfo("Cluster stopped", "status", clusterStatus)
+      logger.Logger.Info("detailedStatuses", statuses)
+    } else {
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.DirectoryCreated = true
+      machineReadableOutput.ClusterStatus = clusterStatus
+      machineReadableOutput.DetailedMachineStatus = statuses
+      machineReadableOutput.StatusMessage = "cluster stopped"
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+  },
+}
+
+func init() {
+  // required args for this command
+  clusterStopCmd.MarkFlagRequired("cluster")
+
+  // add command
+  RootCmd.AddCommand(clusterStopCmd)
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/dgutierrez1287/local-kube/ansible"
+	"github.com/dgutierrez1287/local-kube/bundle"
 	"github.com/dgutierrez1287/local-kube/cluster"
 	"github.com/dgutierrez1287/local-kube/logger"
 	"github.com/dgutierrez1287/local-kube/output"
@@ -30,6 +31,12 @@ var noUp bool
 */
 var noProvision bool
 
+/*
+  to bring the cluster up without network access, everything the
+  machines need is installed from the offline bundle (see bundle-prepare)
+*/
+var offline bool
+
 var clusterUpCmd = &cobra.Command {
   Use: "cluster-up",
   Short: "Brings a cluster up",
@@ -41,14 +48,18 @@ var clusterUpCmd = &cobra.Command {
       fmt.Println(util.TitleText)
     }
 
-    appDir := settings.GetAppDirPath()
+    appDir := getAppDir()
 
     logger.LogInfo("Bringing up cluster", "name", clusterName)
     logger.LogInfo("Running preflight checks")
 
     // preflight just makes sure app directory is present
     // and there is a settings file present 
-    preflight := settings.PreflightCheck(appDir)
+    preflight, err := settings.PreflightCheck(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error running preflight checks", 100, err)
+    }
+
     if !preflight {
       logger.LogErrorExit("Error preflight checks failed", 200, nil)
     }
@@ -67,9 +78,88 @@ var clusterUpCmd = &cobra.Command {
       logger.LogErrorExit("Error settings could not be validated", 200, nil)
     }
 
+    validationErrors := appSettings.ValidateCluster(clusterName)
+    if len(validationErrors) > 0 {
+      for _, validationError := range validationErrors {
+        logger.LogError(validationError.Error())
+      }
+      logger.LogErrorExit("Error settings could not be validated", 200, validationErrors)
+    }
+
+    // use the effective settings for the cluster, with
+    // everything it extends merged in
+    resolvedCluster, err := appSettings.ResolveCluster(clusterName)
+    if err != nil {
+      logger.LogErrorExit("Error resolving cluster settings", 200, err)
+    }
+    appSettings.Clusters[clusterName] = resolvedCluster
+
+    // fill in any blank ips from the provider ip pool
+    logger.LogInfo("Allocating ips for the cluster")
+    err = appSettings.AllocateClusterIps(appDir, clusterName)
+    if err != nil {
+      logger.LogErrorExit("Error allocating ips for the cluster", 200, err)
+    }
+
+    logger.LogInfo("Checking for ip conflicts with other clusters")
+    err = appSettings.CheckClusterIpOverlap(appDir, clusterName)
+    if err != nil {
+      logger.LogErrorExit("Error cluster ips overlap another cluster", 200, err)
+    }
+
+    err = cluster.CheckDeployedIpConflicts(appDir, clusterName, appSettings.Clusters[clusterName])
+    if err != nil {
+      logger.LogErrorExit("Error cluster ips overlap a deployed cluster", 200, err)
+    }
+
+    // the api server and ingress ports are forwarded from the lead machine
+    appSettings.Clusters[clusterName] = appSettings.Clusters[clusterName].WithForwardedPorts()
+
+    logger.LogInfo("Checking for forwarded port conflicts with other clusters")
+    err = cluster.CheckDeployedPortConflicts(appDir, clusterName, appSettings.Clusters[clusterName])
+    if err != nil {
+      logger.LogErrorExit("Error cluster forwarded ports overlap a deployed cluster", 200, err)
+    }
+
+    // clusters without features use all the defaults
+    if appSettings.Clusters[clusterName].ClusterFeatures == nil {
+      logger.LogDebug("No cluster features set, using an empty feature set")
+      clusterSettings := appSettings.Clusters[clusterName]
+      clusterSettings.ClusterFeatures = &settings.ClusterFeatures{}
+      appSettings.Clusters[clusterName] = clusterSettings
+    }
+
     logger.LogDebug("setting defaults for cluster features")
-    appSettings.Clusters[clusterName].ClusterFeatures.SetDefaults(appSettings.Clusters[clusterName].ClusterType,
+    err = appSettings.Clusters[clusterName].ClusterFeatures.SetDefaults(appSettings.Clusters[clusterName].ClusterType,
       appSettings.Clusters[clusterName].Vip)
+    if err != nil {
+      logger.LogErrorExit("Error setting defaults for cluster features", 200, err)
+    }
+
+    distribution := appSettings.Clusters[clusterName].GetDistribution()
+    logger.LogDebug("Cluster distribution", "distribution", distribution.Name)
+
+    if offline && !distribution.Offline {
+      logger.LogErrorExit("Error the distribution can not be installed offline", 200,
+        fmt.Errorf("%s is not in the offline bundle", distribution.Name))
+    }
+
+    if offline {
+      logger.LogInfo("Checking the offline bundle")
+      k3sRelease, err := bundle.GetK3sRelease(appSettings.Clusters[clusterName].ClusterFeatures.KubeVersion)
+      if err != nil {
+        logger.LogErrorExit("Error getting the k3s release for the offline bundle", 200, err)
+      }
+
+      err = bundle.CheckBundle(appDir, k3sRelease, appSettings.ProvisionSettings.AnsibleVersion)
+      if err != nil {
+        logger.LogErrorExit("Error the offline bundle is incomplete", 200, err)
+      }
+
+      for _, requirement := range bundle.GetOnlineRequirements(appSettings.Clusters[clusterName]) {
+        logger.LogWarn("Not in the offline bundle, this will need network access", "component", requirement)
+      }
+    }
 
     logger.LogInfo("Checking to make sure a cluster isn't already present")
     clusterExists, existsType, err := cluster.CheckForExistingCluster(appDir, clusterName, false)
@@ -117,7 +207,7 @@ var clusterUpCmd = &cobra.Command {
 
     // Variables
     logger.LogInfo("Generating ansible variables")
-    err = cluster.GenerateAnsibleVariables(appDir, clusterName, appSettings)
+    err = cluster.GenerateAnsibleVariables(appDir, clusterName, appSettings, offline)
     if err != nil {
       logger.LogErrorExit("Error generating ansible variables", 100, err)
     }
@@ -136,9 +226,23 @@ var clusterUpCmd = &cobra.Command {
       logger.LogErrorExit("Error generating script settings", 100, err)
     }
 
+    // Registries (k3s registries.yaml and registry certs)
+    logger.LogInfo("Generating registries file")
+    err = cluster.GenerateRegistriesFile(appDir, clusterName, appSettings)
+    if err != nil {
+      logger.LogErrorExit("Error generating registries file", 100, err)
+    }
+
+    // Addons (helm charts the k3s helm controller installs)
+    logger.LogInfo("Generating addon manifests")
+    err = cluster.GenerateAddonManifests(appDir, clusterName, appSettings)
+    if err != nil {
+      logger.LogErrorExit("Error generating addon manifests", 100, err)
+    }
+
     // Vagrant file 
     logger.LogInfo("Generating vagrantFile")
-    err = cluster.RenderVagrantFile(appDir, clusterName, appSettings)
+    err = cluster.RenderVagrantFile(appDir, clusterName, appSettings, offline)
     if err != nil {
       logger.LogErrorExit("Error generating vagrantfile", 100, err)
     }
@@ -160,12 +264,22 @@ var clusterUpCmd = &cobra.Command {
       }
     }
 
+    err = cluster.RunHooks(appDir, clusterName, appSettings, "preUp")
+    if err != nil {
+      logger.LogErrorExit("Error running the preUp hooks", 100, err)
+    }
+
     logger.LogInfo("Bringing up the cluster")
     _, err = cluster.ClusterUp(appDir, clusterName, machineOutput)
     if err != nil {
       logger.LogErrorExit("Error bringing up the cluster", 100, err)
     }
 
+    err = cluster.RunHooks(appDir, clusterName, appSettings, "postUp")
+    if err != nil {
+      logger.LogErrorExit("Error running the postUp hooks", 100, err)
+    }
+
     if noProvision {
       if !machineOutput {
         logger.LogInfo("no-provision was set, VMs should be up but stopping before provision")
@@ -190,16 +304,10 @@ var clusterUpCmd = &cobra.Command {
 
     logger.LogInfo("Starting kubeconfig update")
     kubeConfigPath := appSettings.KubeConfigPath
-    var kubeConfigClusterName string
-
-    if appSettings.Clusters[clusterName].KubeConfigName == "" {
-      kubeConfigClusterName = clusterName
-    } else {
-      kubeConfigClusterName = appSettings.Clusters[clusterName].KubeConfigName
-    }
+    kubeConfigClusterName := appSettings.Clusters[clusterName].GetKubeConfigName(clusterName)
 
     serverUrl := appSettings.Clusters[clusterName].GetServerUrl()
-    sourceKubeConfigPath := filepath.Join(appDir, clusterName, "kubeconfig", "k3s.yaml")
+    sourceKubeConfigPath := filepath.Join(appDir, clusterName, "kubeconfig", distribution.GetKubeConfigFile())
 
     logger.LogDebug("kube config path", "path", kubeConfigPath)
     logger.LogDebug("source kube config path", "path", sourceKubeConfigPath)
@@ -229,7 +337,7 @@ var clusterUpCmd = &cobra.Command {
     }
 
     logger.LogInfo("Updating server url for new cluster")
-    err = sourceKubeConfig.UpdateServerUrl(serverUrl, "default")
+    err = sourceKubeConfig.UpdateServerUrl(serverUrl, distribution.KubeConfigName)
     if err != nil {
       logger.LogErrorExit("Error updating server url in kube config", 200, err)
     }
@@ -237,7 +345,7 @@ var clusterUpCmd = &cobra.Command {
     logger.LogDebug("Updated server url in source config", "url", sourceKubeConfig.Clusters[0].Cluster.Server)
 
     logger.LogInfo("Adding cluster to kubeconfig")
-    err = destKubeconfig.AddCluster(sourceKubeConfig, "default", kubeConfigClusterName)
+    err = destKubeconfig.AddCluster(sourceKubeConfig, distribution.KubeConfigName, kubeConfigClusterName)
     if err != nil {
       logger.LogErrorExit("Error adding cluster to kubeconfig", 200, err)
     }
@@ -254,6 +362,11 @@ var clusterUpCmd = &cobra.Command {
       logger.LogErrorExit("Error cleaning up kubeconfig backup", 200, err)
     }
 
+    err = cluster.RunHooks(appDir, clusterName, appSettings, "postProvision")
+    if err != nil {
+      logger.LogErrorExit("Error running the postProvision hooks", 100, err)
+    }
+
     logger.LogInfo("Cluster provisioning complete successfully")
   },
 }
@@ -262,6 +375,7 @@ func init() {
   // command specific args
   clusterUpCmd.PersistentFlags().BoolVarP(&noUp, "noup", "", false, "Only Generate files but do not create vms")
   clusterUpCmd.PersistentFlags().BoolVarP(&noProvision, "no-provision", "", false, "Create VMs but do not run provision")
+  clusterUpCmd.PersistentFlags().BoolVarP(&offline, "offline", "", false, "Provision from the offline bundle without network access")
 
   // required args for this command
   clusterUpCmd.MarkFlagRequired("cluster")
@@ -24,12 +24,19 @@ var initCmd = &cobra.Command {
     logger.LogInfo("Running initialization")
 
     logger.LogDebug("Getting app directory paths")
-    appDir := settings.GetAppDirPath()
+    appDir := getAppDir()
     ansibleRoleDir := filepath.Join(appDir, "ansible-roles")
 
     logger.LogDebug("Checking if the directories and settings exist")
-    appDirExists := settings.DirectoryExists(appDir)
-    ansibleRoleDirExists := settings.DirectoryExists(ansibleRoleDir)
+    appDirExists, err := settings.DirectoryExists(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error checking for the app directory", 120, err)
+    }
+
+    ansibleRoleDirExists, err := settings.DirectoryExists(ansibleRoleDir)
+    if err != nil {
+      logger.LogErrorExit("Error checking for the ansible role directory", 120, err)
+    }
     var alreadyInit bool
 
     // Top application directory
@@ -37,8 +44,11 @@ var initCmd = &cobra.Command {
       logger.LogDebug("App directory already exists")
       alreadyInit = true
     } else {
-      logger.LogInfo("Creating app directory which will be at ~/.local-kube")
-      settings.CreateDirectory(appDir)
+      logger.LogInfo("Creating app directory", "path", appDir)
+      err = settings.CreateDirectory(appDir)
+      if err != nil {
+        logger.LogErrorExit("Error creating the app directory", 123, err)
+      }
       alreadyInit = false
     }  
 
@@ -48,7 +58,10 @@ var initCmd = &cobra.Command {
       alreadyInit = true
     } else {
       logger.LogInfo("Creating ansible role directory")
-      settings.CreateDirectory(ansibleRoleDir)
+      err = settings.CreateDirectory(ansibleRoleDir)
+      if err != nil {
+        logger.LogErrorExit("Error creating the ansible role directory", 123, err)
+      }
       alreadyInit = false
     }
 
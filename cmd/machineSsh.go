@@ -20,7 +20,7 @@ var machineSshCmd = &cobra.Command {
   Run: func(cmd *cobra.Command, args []string) {
     fmt.Println(util.TitleText)
 
-    appDir := settings.GetAppDirPath()
+    appDir := getAppDir()
     clusterDir := filepath.Join(appDir, clusterName)
     machineName := ""
 
@@ -48,6 +48,12 @@ var machineSshCmd = &cobra.Command {
       logger.LogError("Error reading settings file", "error", err)
     }
 
+    resolvedCluster, err := appSettings.ResolveCluster(clusterName)
+    if err != nil {
+      logger.LogErrorExit("Error resolving cluster settings", 200, err)
+    }
+    appSettings.Clusters[clusterName] = resolvedCluster
+
     if appSettings.Clusters[clusterName].ClusterType == "single" {
       logger.LogInfo("Single node cluster connecting you to the default machine")
       machineName = "default"
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/spf13/cobra"
+)
+
+var providersListCmd = &cobra.Command{
+  Use: "providers-list",
+  Short: "Lists the supported provider types",
+  Long: "Lists the supported provider types with the cluster types, required settings, features and defaults for each",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    definitions := settings.GetProviderDefinitions()
+
+    if machineOutput {
+      providersJson, err := json.Marshal(definitions)
+      if err != nil {
+        logger.LogErrorExit("Error encoding providers", 200, err)
+      }
+
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.Providers = providersJson
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+
+    fmt.Println(util.TitleText)
+
+    for _, definition := range definitions {
+      fmt.Printf("%s (vagrant provider %s)\n", definition.ProviderType, definition.VagrantProvider)
+      fmt.Printf("  %s\n", definition.Description)
+      fmt.Printf("  cluster types:    %s\n", strings.Join(definition.GetClusterTypes(), ", "))
+      fmt.Printf("  required:         %s\n", strings.Join(definition.RequiredFields, ", "))
+      fmt.Printf("  features:         %s\n", strings.Join(setValues(definition.Features), ", "))
+      fmt.Printf("  defaults:         %s\n\n", strings.Join(setValues(definition.Defaults), ", "))
+    }
+    os.Exit(0)
+  },
+}
+
+/*
+gets the json fields of a struct that are set, bools
+are shown as the field name and anything else as name=value
+*/
+func setValues(value interface{}) []string {
+  values := []string{}
+
+  var fields map[string]interface{}
+  jsonBytes, _ := json.Marshal(value)
+  json.Unmarshal(jsonBytes, &fields)
+
+  for name, fieldValue := range fields {
+    switch typedValue := fieldValue.(type) {
+    case bool:
+      if typedValue {
+        values = append(values, name)
+      }
+    case string:
+      if typedValue != "" {
+        values = append(values, fmt.Sprintf("%s=%s", name, typedValue))
+      }
+    }
+  }
+  sort.Strings(values)
+  return values
+}
+
+func init() {
+  // add command
+  RootCmd.AddCommand(providersListCmd)
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/dgutierrez1287/local-kube/ansible"
 	"github.com/dgutierrez1287/local-kube/logger"
-	"github.com/dgutierrez1287/local-kube/settings"
 	"github.com/dgutierrez1287/local-kube/util"
 	"github.com/spf13/cobra"
 )
@@ -20,7 +19,7 @@ var roleCleanCmd = &cobra.Command{
       fmt.Println(util.TitleText)
     }
 
-    appDir := settings.GetAppDirPath()
+    appDir := getAppDir()
 
     logger.LogInfo("Cleaning all roles and reseting cache")
     cacheExists, err := ansible.RoleCacheFileExists(appDir)
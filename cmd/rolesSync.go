@@ -20,7 +20,7 @@ var roleSyncCmd = &cobra.Command{
       fmt.Println(util.TitleText)
     }
 
-    appDir := settings.GetAppDirPath()
+    appDir := getAppDir()
 
     logger.LogInfo("Syncing ansible roles")
 
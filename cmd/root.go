@@ -4,6 +4,7 @@ import (
 	"fmt"
 
   "github.com/dgutierrez1287/local-kube/logger"
+  "github.com/dgutierrez1287/local-kube/settings"
   "github.com/dgutierrez1287/local-kube/util"
 
 	"github.com/spf13/cobra"
@@ -13,6 +14,7 @@ var debug bool
 var logColorize bool
 var clusterName string
 var machineOutput bool
+var appHome string
 
 var RootCmd = &cobra.Command{
   Use: "local-kube",
@@ -20,6 +22,10 @@ var RootCmd = &cobra.Command{
   Long: "A program to create and manage a local kube cluster using vagrant",
   PersistentPreRun: func(cmd *cobra.Command, args []string) {
     logger.InitLogging(debug, logColorize, machineOutput)
+
+    if appHome != "" {
+      settings.SetAppHome(appHome)
+    }
   }, 
   Run: func(cmd *cobra.Command, args []string) {
     fmt.Println(util.TitleText)
@@ -31,7 +37,38 @@ func Execute() error {
   return RootCmd.Execute()
 }
 
+/*
+gets the app directory, this will exit if
+the path can't be worked out
+*/
+func getAppDir() string {
+  appDir, err := settings.GetAppDirPath()
+  if err != nil {
+    logger.LogErrorExit("Error getting the app directory", 120, err)
+  }
+  return appDir
+}
+
+/*
+gets the resolved settings for a cluster that is being stopped or
+destroyed, when the cluster can't be resolved (ex it was removed
+from the settings or its extends are broken) the cluster entry is
+used as is so a deployed cluster can still be stopped and destroyed
+*/
+func resolveDeployedCluster(appSettings *settings.Settings, clusterName string) settings.Cluster {
+  resolvedCluster, err := appSettings.ResolveCluster(clusterName)
+  if err != nil {
+    logger.LogWarn("Error resolving cluster settings, using the cluster settings as is",
+      "cluster", clusterName, "error", err)
+    return appSettings.Clusters[clusterName]
+  }
+  return resolvedCluster
+}
+
 func init() {
+  // app home
+  RootCmd.PersistentFlags().StringVarP(&appHome, "home", "", "", "The local-kube home directory (default is $LOCAL_KUBE_HOME or ~/.local-kube)")
+
   // cluster name
   RootCmd.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "", "The cluster to run the action on")
 
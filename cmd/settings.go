@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/spf13/cobra"
+)
+
+var settingsCmd = &cobra.Command{
+  Use: "settings",
+  Short: "Gets and sets values in the settings",
+  Long: "Gets and sets values in the settings using json paths ex clusters.dev.clusterFeatures.kubeVersion",
+}
+
+var settingsGetCmd = &cobra.Command{
+  Use: "get <path>",
+  Short: "Gets a value from the settings",
+  Long: "Gets a value from the settings, strings are printed as is and everything else is printed as json",
+  Args: cobra.ExactArgs(1),
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    appDir := getAppDir()
+
+    appSettings, err := settings.ReadSettingsFile(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error reading settings", 200, err)
+    }
+
+    value, err := appSettings.GetValue(args[0])
+    if err != nil {
+      logger.LogErrorExit("Error getting settings value", 200, err)
+    }
+
+    valueJson, err := json.MarshalIndent(value, "", " ")
+    if err != nil {
+      logger.LogErrorExit("Error encoding settings value", 200, err)
+    }
+
+    if !machineOutput {
+      if stringValue, isString := value.(string); isString {
+        fmt.Println(stringValue)
+      } else {
+        fmt.Println(string(valueJson))
+      }
+      os.Exit(0)
+    } else {
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.SettingsValue = valueJson
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+  },
+}
+
+var settingsSetCmd = &cobra.Command{
+  Use: "set <path> <value>",
+  Short: "Sets a value in the settings",
+  Long: "Sets a value in the settings, lists and objects are set with json. The settings are validated before they are saved and the original file is backed up",
+  Args: cobra.ExactArgs(2),
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    appDir := getAppDir()
+
+    logger.LogInfo("Setting settings value", "path", args[0])
+    err := settings.SetSettingsFileValue(appDir, args[0], args[1])
+
+    var validationErrors settings.ValidationErrors
+    if errors.As(err, &validationErrors) {
+      if !machineOutput {
+        logger.Logger.Error("Updated settings are not valid, nothing was saved", "errors", len(validationErrors))
+        for _, validationError := range validationErrors {
+          logger.Logger.Error(validationError.Message, "path", validationError.Path)
+        }
+        os.Exit(210)
+      } else {
+        machineReadableOutput.ExitCode = 210
+        machineReadableOutput.ErrorMessage = "the updated settings are not valid, nothing was saved"
+        for _, validationError := range validationErrors {
+          machineReadableOutput.ValidationErrors = append(machineReadableOutput.ValidationErrors, validationError.Error())
+        }
+        output, _ := machineReadableOutput.GetMachineOutputJson()
+        fmt.Println(output)
+        os.Exit(210)
+      }
+    } else if err != nil {
+      logger.LogErrorExit("Error setting settings value", 200, err)
+    }
+
+    if !machineOutput {
+      logger.LogInfo("Settings saved", "path", args[0])
+      os.Exit(0)
+    } else {
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.StatusMessage = fmt.Sprintf("%s set", args[0])
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+  },
+}
+
+func init() {
+  // sub commands
+  settingsCmd.AddCommand(settingsGetCmd)
+  settingsCmd.AddCommand(settingsSetCmd)
+
+  // add command
+  RootCmd.AddCommand(settingsCmd)
+}
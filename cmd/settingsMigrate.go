@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/spf13/cobra"
+)
+
+/*
+  only show the changes a migration would make
+  without writing the settings file
+*/
+var migrateDryRun bool
+
+var settingsMigrateCmd = &cobra.Command{
+  Use: "settings-migrate",
+  Short: "Migrates the settings file to the current schema",
+  Long: "Migrates the settings file to the current schema version, the original file is backed up first",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    if !machineOutput {
+      fmt.Println(util.TitleText)
+    }
+
+    appDir := getAppDir()
+
+    logger.LogInfo("Checking settings schema version", "current", settings.CurrentSchemaVersion)
+    diff, migrated, err := settings.MigrateSettingsFile(appDir, migrateDryRun)
+    if err != nil {
+      logger.LogErrorExit("Error migrating settings", 200, err)
+    }
+
+    var statusMessage string
+    if !migrated {
+      statusMessage = "settings are already at the current schema version"
+    } else if migrateDryRun {
+      statusMessage = "dry run, settings file was not changed"
+    } else {
+      statusMessage = "settings file migrated"
+    }
+
+    if !machineOutput {
+      if migrated {
+        fmt.Println(diff)
+      }
+      logger.LogInfo(statusMessage)
+      os.Exit(0)
+    } else {
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.StatusMessage = statusMessage
+      machineReadableOutput.SettingsDiff = diff
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+  },
+}
+
+func init() {
+  // command specific args
+  settingsMigrateCmd.PersistentFlags().BoolVarP(&migrateDryRun, "dry-run", "", false, "Print the changes a migration would make without writing them")
+
+  // add command
+  RootCmd.AddCommand(settingsMigrateCmd)
+}
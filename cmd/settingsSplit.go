@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/spf13/cobra"
+)
+
+var settingsSplitCmd = &cobra.Command{
+  Use: "settings-split",
+  Short: "Splits the clusters in the settings file into per-cluster files",
+  Long: "Moves every cluster in settings.json into its own file in clusters.d, the original settings file is backed up first",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    if !machineOutput {
+      fmt.Println(util.TitleText)
+    }
+
+    appDir := getAppDir()
+
+    logger.LogInfo("Splitting clusters out of the settings file")
+    splitClusters, err := settings.SplitSettingsFile(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error splitting settings", 200, err)
+    }
+
+    var statusMessage string
+    if len(splitClusters) == 0 {
+      statusMessage = "no clusters in settings.json to split"
+    } else {
+      statusMessage = fmt.Sprintf("clusters moved to %s: %s", settings.ClusterFilesDir, strings.Join(splitClusters, ", "))
+    }
+
+    if !machineOutput {
+      logger.LogInfo(statusMessage)
+      os.Exit(0)
+    } else {
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.StatusMessage = statusMessage
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+  },
+}
+
+func init() {
+  // add command
+  RootCmd.AddCommand(settingsSplitCmd)
+}
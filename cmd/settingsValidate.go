@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/spf13/cobra"
+)
+
+var settingsValidateCmd = &cobra.Command{
+  Use: "settings-validate",
+  Short: "Validates the settings file",
+  Long: "Validates the settings file and reports every problem found, if a cluster is given only the settings for that cluster are validated",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+    var validationErrors settings.ValidationErrors
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    if !machineOutput {
+      fmt.Println(util.TitleText)
+    }
+
+    appDir := getAppDir()
+
+    logger.LogInfo("Reading settings file")
+    appSettings, err := settings.ReadSettingsFile(appDir)
+    if err != nil {
+      logger.LogErrorExit("Error reading settings", 200, err)
+    }
+
+    if clusterName == "" {
+      logger.LogInfo("Validating all settings")
+      validationErrors = appSettings.Validate()
+    } else {
+      logger.LogInfo("Validating settings for cluster", "cluster", clusterName)
+      validationErrors = appSettings.ValidateCluster(clusterName)
+    }
+
+    if len(validationErrors) == 0 {
+      if !machineOutput {
+        logger.LogInfo("Settings are valid")
+        os.Exit(0)
+      } else {
+        machineReadableOutput.ExitCode = 0
+        machineReadableOutput.StatusMessage = "settings are valid"
+        output, eCode := machineReadableOutput.GetMachineOutputJson()
+        fmt.Println(output)
+        os.Exit(eCode)
+      }
+    }
+
+    if !machineOutput {
+      logger.Logger.Error("Settings are not valid", "errors", len(validationErrors))
+      for _, validationError := range validationErrors {
+        logger.Logger.Error(validationError.Message, "path", validationError.Path)
+      }
+      os.Exit(210)
+    } else {
+      machineReadableOutput.ExitCode = 210
+      machineReadableOutput.ErrorMessage = "settings are not valid"
+      for _, validationError := range validationErrors {
+        machineReadableOutput.ValidationErrors = append(machineReadableOutput.ValidationErrors, validationError.Error())
+      }
+      output, _ := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(210)
+    }
+  },
+}
+
+func init() {
+  // add command
+  RootCmd.AddCommand(settingsValidateCmd)
+}
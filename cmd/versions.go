@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/output"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/spf13/cobra"
+)
+
+var versionsCmd = &cobra.Command{
+  Use: "versions",
+  Short: "Shows the supported kubernetes and component versions",
+  Long: "Shows the supported kubernetes (k3s and rke2) versions and the cni, kube-vip and longhorn versions that work with each, the first version listed for a component is the default",
+  Run: func(cmd *cobra.Command, args []string) {
+    var machineReadableOutput output.MachineOutput
+
+    if machineOutput && debug {
+      logger.Logger.Error("Error you can't have machine output set and debug set")
+      os.Exit(20)
+    }
+
+    catalog, err := settings.GetVersionCatalog()
+    if err != nil {
+      logger.LogErrorExit("Error reading the version catalog", 200, err)
+    }
+
+    if machineOutput {
+      catalogJson, err := json.Marshal(catalog)
+      if err != nil {
+        logger.LogErrorExit("Error encoding the version catalog", 200, err)
+      }
+
+      machineReadableOutput.ExitCode = 0
+      machineReadableOutput.Versions = catalogJson
+      output, eCode := machineReadableOutput.GetMachineOutputJson()
+      fmt.Println(output)
+      os.Exit(eCode)
+    }
+
+    fmt.Println(util.TitleText)
+
+    table := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+    fmt.Fprintln(table, "KUBE\tK3S\tRKE2\tCILIUM\tCALICO\tKUBE-VIP\tLONGHORN")
+    for _, compatibility := range catalog.KubeVersions {
+      kubeVersion := compatibility.KubeVersion
+      if kubeVersion == catalog.DefaultKubeVersion {
+        kubeVersion += " (default)"
+      }
+
+      fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", kubeVersion, compatibility.K3sRelease, compatibility.Rke2Release,
+        strings.Join(compatibility.Cilium, ", "), strings.Join(compatibility.Calico, ", "),
+        strings.Join(compatibility.KubeVip, ", "), strings.Join(compatibility.Longhorn, ", "))
+    }
+    table.Flush()
+
+    fmt.Printf("\ncilium cli: %s\n", catalog.CiliumCliVersion)
+
+    if len(catalog.KnownBad) > 0 {
+      fmt.Println("\nknown bad combinations:")
+      for _, knownBad := range catalog.KnownBad {
+        kubeVersions := "all kube versions"
+        if len(knownBad.KubeVersions) > 0 {
+          kubeVersions = "kube " + strings.Join(knownBad.KubeVersions, ", ")
+        }
+        fmt.Printf("  %s %s with %s: %s\n", knownBad.Component, knownBad.Version, kubeVersions, knownBad.Reason)
+      }
+    }
+    os.Exit(0)
+  },
+}
+
+func init() {
+  // add command
+  RootCmd.AddCommand(versionsCmd)
+}
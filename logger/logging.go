@@ -61,6 +61,16 @@ func LogInfo(message string, args ...interface{}) {
   }
 }
 
+/*
+This will wrap warn logging to handle if it should be
+written to console based on machine output setting
+*/
+func LogWarn(message string, args ...interface{}) {
+  if !machineOutput {
+    Logger.Warn(message, args...)
+  }
+}
+
 /*
 This will wrap debug logging to handle any special 
 caes
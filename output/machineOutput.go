@@ -15,6 +15,13 @@ type MachineOutput struct {
   DirectoryCreated bool                     `json:"directoryCreated,omitempty"`
   ClusterStatus string                      `json:"clusterStatus,omitempty"`
   DetailedMachineStatus map[string]string   `json:"machineStatus,omitempty"`
+  AddonStatus map[string]string             `json:"addonStatus,omitempty"`
+  ValidationErrors []string                 `json:"validationErrors,omitempty"`
+  ClusterSettings json.RawMessage           `json:"clusterSettings,omitempty"`
+  SettingsValue json.RawMessage             `json:"settingsValue,omitempty"`
+  Providers json.RawMessage                 `json:"providers,omitempty"`
+  Versions json.RawMessage                  `json:"versions,omitempty"`
+  SettingsDiff string                       `json:"settingsDiff,omitempty"`
 }
 
 /*
@@ -0,0 +1,100 @@
+package settings
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+)
+
+/*
+  Addon - A helm chart that is installed on the cluster with
+  the k3s helm controller, the chart comes from a helm repo or
+  from a local chart directory (which works offline)
+*/
+type Addon struct {
+  Name string                       `json:"name"`                        // The helm release name
+  Chart string                      `json:"chart,omitempty"`             // The chart name in the repo
+  Repo string                       `json:"repo,omitempty"`              // The helm repo url
+  Path string                       `json:"path,omitempty"`              // A local chart directory, relative paths are from the app dir
+  Version string                    `json:"version,omitempty"`           // The chart version (latest if empty)
+  Namespace string                  `json:"namespace,omitempty"`         // The namespace to install into (default if empty)
+  Values map[string]interface{}     `json:"values,omitempty"`            // Values for the chart
+}
+
+// addon names are used for the helm release and the manifest name
+var addonNameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,51}[a-z0-9])?$`)
+
+/*
+Gets the namespace the addon is installed into
+*/
+func (addon Addon) GetNamespace() string {
+  if addon.Namespace == "" {
+    return "default"
+  }
+  return addon.Namespace
+}
+
+/*
+Gets the path to a local addon chart, relative
+paths are from the app dir
+*/
+func (addon Addon) GetChartPath(appDir string) string {
+  if addon.Path == "" || filepath.IsAbs(addon.Path) {
+    return addon.Path
+  }
+  return filepath.Join(appDir, addon.Path)
+}
+
+/*
+merges the addons of a cluster on top of the addons of what it
+extends, addons are matched by name and an override addon
+replaces the base addon with the same name
+*/
+func mergeAddons(base []Addon, override []Addon) []Addon {
+  if len(override) == 0 {
+    return base
+  }
+
+  merged := []Addon{}
+  overrides := make(map[string]Addon)
+  for _, addon := range override {
+    overrides[addon.Name] = addon
+  }
+
+  for _, addon := range base {
+    if _, exists := overrides[addon.Name]; !exists {
+      merged = append(merged, addon)
+    }
+  }
+  return append(merged, override...)
+}
+
+// validates the addons for a cluster
+func (validator *settingsValidator) validateAddons(clusterPath string, addons []Addon) {
+  names := make(map[string]bool)
+
+  for index, addon := range addons {
+    path := fmt.Sprintf("%s.addons[%d]", clusterPath, index)
+
+    if !addonNameRegex.MatchString(addon.Name) {
+      validator.add(path + ".name", "malformed addon name %q, must be lowercase letters, numbers and dashes", addon.Name)
+    } else if names[addon.Name] {
+      validator.add(path + ".name", "duplicate addon name %q", addon.Name)
+    }
+    names[addon.Name] = true
+
+    if addon.Path != "" && (addon.Chart != "" || addon.Repo != "") {
+      validator.add(path + ".path", "an addon can have a local path or a chart and repo, not both")
+    } else if addon.Path == "" && addon.Chart == "" {
+      validator.add(path + ".chart", "a chart or local path is required")
+    }
+
+    if addon.Path != "" && addon.Version != "" {
+      validator.add(path + ".version", "version is only used for charts from a repo")
+    }
+
+    if addon.Namespace != "" && !addonNameRegex.MatchString(addon.Namespace) {
+      validator.add(path + ".namespace", "malformed namespace %q", addon.Namespace)
+    }
+  }
+}
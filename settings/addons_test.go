@@ -0,0 +1,69 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for addon validation
+*/
+func TestValidateAddons(t *testing.T) {
+  appSettings := getValidSettings()
+
+  dev := appSettings.Clusters["dev"]
+  dev.Addons = []Addon{
+    {Name: "cert-manager", Chart: "cert-manager", Repo: "https://charts.jetstack.io", Namespace: "cert-manager"},
+    {Name: "my-app", Path: "charts/my-app"},
+  }
+  appSettings.Clusters["dev"] = dev
+  assert.Empty(t, appSettings.Validate())
+
+  dev.Addons = []Addon{
+    {Name: "Cert_Manager", Chart: "cert-manager"},
+    {Name: "my-app", Path: "charts/my-app", Chart: "my-app", Version: "1.0.0"},
+    {Name: "my-app"},
+  }
+  appSettings.Clusters["dev"] = dev
+
+  assert.Equal(t, []string{
+    "clusters.dev.addons[0].name",
+    "clusters.dev.addons[1].path",
+    "clusters.dev.addons[1].version",
+    "clusters.dev.addons[2].name",
+    "clusters.dev.addons[2].chart",
+  }, validationPaths(appSettings.Validate()))
+}
+
+/*
+      Tests for addon extends
+*/
+func TestResolveClusterMergesAddonsByName(t *testing.T) {
+  appSettings := getValidSettings()
+
+  base := appSettings.Clusters["dev"]
+  base.Addons = []Addon{
+    {Name: "cert-manager", Chart: "cert-manager", Repo: "https://charts.jetstack.io", Version: "v1.15.0"},
+    {Name: "metrics-server", Chart: "metrics-server", Repo: "https://kubernetes-sigs.github.io/metrics-server"},
+  }
+  appSettings.Clusters["dev"] = base
+
+  appSettings.Clusters["dev2"] = Cluster{
+    Extends: "dev",
+    Leaders: []Machine{{Name: "dev2", IpAddress: "192.168.1.30"}},
+    Addons: []Addon{
+      {Name: "cert-manager", Chart: "cert-manager", Repo: "https://charts.jetstack.io", Version: "v1.16.2"},
+      {Name: "my-app", Path: "charts/my-app"},
+    },
+  }
+
+  resolved, err := appSettings.ResolveCluster("dev2")
+  assert.NoError(t, err)
+
+  assert.Len(t, resolved.Addons, 3)
+  assert.Equal(t, "metrics-server", resolved.Addons[0].Name)
+  assert.Equal(t, "cert-manager", resolved.Addons[1].Name)
+  assert.Equal(t, "v1.16.2", resolved.Addons[1].Version)
+  assert.Equal(t, "my-app", resolved.Addons[2].Name)
+}
@@ -15,54 +15,80 @@ and also any other application directories which live longer and are
 independent from clusters
 */
 
+/*
+  The environment variable that can be used to set the
+  application directory instead of $HOME/.local-kube
+*/
+const AppHomeEnvVar = "LOCAL_KUBE_HOME"
+
+// app directory set with SetAppHome, this wins over everything else
+var appHomeOverride string
+
+/*
+Sets the application directory, this takes precedence over
+the LOCAL_KUBE_HOME environment variable and the default.
+An empty path clears the override
+*/
+func SetAppHome(appHome string) {
+  logger.LogDebug("Setting app home override", "path", appHome)
+  appHomeOverride = appHome
+}
+
 /* 
 Get the path for the application directory
-this will be $HOME/$Username/.local-kube and 
-the equilvalent on windows
+in order of precedence this will be the path set with 
+SetAppHome, the LOCAL_KUBE_HOME environment variable or
+$HOME/$Username/.local-kube (and the equilvalent on windows)
 */
-func GetAppDirPath() string {
+func GetAppDirPath() (string, error) {
+  if appHomeOverride != "" {
+    logger.LogDebug("Using app home override", "path", appHomeOverride)
+    return filepath.Abs(appHomeOverride)
+  }
+
+  if envAppHome := os.Getenv(AppHomeEnvVar); envAppHome != "" {
+    logger.LogDebug("Using app home from environment", "variable", AppHomeEnvVar, "path", envAppHome)
+    return filepath.Abs(envAppHome)
+  }
+
   userDir, err := os.UserHomeDir()
   if err != nil {
     logger.LogError("Error getting user home dir", "error", err)
-    os.Exit(120)
+    return "", err
   }
 
-  return filepath.Join(userDir, ".local-kube")
+  return filepath.Join(userDir, ".local-kube"), nil
 }
 
 /*
 This will check if an application level directory 
 exists
 */
-func DirectoryExists(dirPath string) bool {
-  var dirExists bool
-
+func DirectoryExists(dirPath string) (bool, error) {
   if _, err := os.Stat(dirPath); err != nil {
     if errors.Is(err, os.ErrNotExist) {
       logger.LogDebug("directory does not exist", "path", dirPath)
-      dirExists = false
-    } else {
-      logger.LogError("Error checking if directory exists", "path", dirPath, "error", err)
-      os.Exit(120)
+      return false, nil
     }
-  } else {
-    logger.LogDebug("Directory exists")
-    dirExists = true
+    logger.LogError("Error checking if directory exists", "path", dirPath, "error", err)
+    return false, err
   }
 
-  return dirExists
+  logger.LogDebug("Directory exists")
+  return true, nil
 }
 
 /*
-Creates an application level directory
+Creates an application level directory, any missing
+parent directories are created as well
 */
-func CreateDirectory(dirPath string) {
+func CreateDirectory(dirPath string) error {
 
-  err := os.Mkdir(dirPath, 0750)
+  err := os.MkdirAll(dirPath, 0750)
   if err != nil {
     logger.LogError("Error creating directory", "path", dirPath,"error", err)
-    os.Exit(123)
+    return err
   }
   logger.LogInfo("Directory created")
+  return nil
 }
-
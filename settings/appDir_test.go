@@ -2,7 +2,6 @@ package settings
 
 import (
 	"os"
-  "os/exec"
 	"path/filepath"
 	"testing"
 
@@ -23,11 +22,36 @@ func TestMain(m *testing.M) {
       Tests for GetAppDirPath
 */
 func TestGetAppDirPath(t *testing.T) {
+	t.Setenv(AppHomeEnvVar, "")
+
 	homeDir, err := os.UserHomeDir()
 	require.NoError(t, err)
 
 	expectedPath := filepath.Join(homeDir, ".local-kube")
-	assert.Equal(t, expectedPath, GetAppDirPath())
+	appDir, err := GetAppDirPath()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPath, appDir)
+}
+
+func TestGetAppDirPathEnv(t *testing.T) {
+	envHome := t.TempDir()
+	t.Setenv(AppHomeEnvVar, envHome)
+
+	appDir, err := GetAppDirPath()
+	assert.NoError(t, err)
+	assert.Equal(t, envHome, appDir)
+}
+
+func TestGetAppDirPathOverride(t *testing.T) {
+	t.Setenv(AppHomeEnvVar, t.TempDir())
+
+	overrideHome := t.TempDir()
+	SetAppHome(overrideHome)
+	defer SetAppHome("")
+
+	appDir, err := GetAppDirPath()
+	assert.NoError(t, err)
+	assert.Equal(t, overrideHome, appDir)
 }
 
 /*
@@ -37,14 +61,18 @@ func TestDirectoryExistsExists(t *testing.T) {
 	// Create temp directory
 	tempDir := t.TempDir()
 
-	assert.True(t, DirectoryExists(tempDir))
+	exists, err := DirectoryExists(tempDir)
+	assert.NoError(t, err)
+	assert.True(t, exists)
 }
 
 // Test DirectoryExists when directory does not exist
 func TestDirectoryExistsNotExists(t *testing.T) {
 	nonExistentDir := filepath.Join(os.TempDir(), "does-not-exist-12345")
 
-	assert.False(t, DirectoryExists(nonExistentDir))
+	exists, err := DirectoryExists(nonExistentDir)
+	assert.NoError(t, err)
+	assert.False(t, exists)
 }
 
 
@@ -55,30 +83,18 @@ func TestCreateDirectorySuccess(t *testing.T) {
 	tempDir := filepath.Join(os.TempDir(), "test-create-dir")
 	defer os.Remove(tempDir) // Cleanup
 
-	CreateDirectory(tempDir)
+	err := CreateDirectory(tempDir)
+	assert.NoError(t, err)
 
 	assert.DirExists(t, tempDir)
 }
 
 func TestCreateDirectory_Failure(t *testing.T) {
-	if os.Geteuid() == 0 {
-		t.Skip("Skipping test since it's running as root.")
-	}
-
-	// Run test in a subprocess
-	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
-	cmd.Env = append(os.Environ(), "TEST_CRASH=1")
-	err := cmd.Run()
-
-	// Assert that os.Exit(123) was triggered
-	exitError, ok := err.(*exec.ExitError)
-	assert.True(t, ok, "Process should have exited with an error")
-	assert.Equal(t, 123, exitError.ExitCode(), "Expected os.Exit(123) to be called on failure")
-}
+	// a directory can't be created under a regular file
+	parentFile := filepath.Join(t.TempDir(), "not-a-dir")
+	err := os.WriteFile(parentFile, []byte{}, 0644)
+	require.NoError(t, err)
 
-// TestHelperProcess is a helper function to trigger os.Exit in a subprocess
-func TestHelperProcess(t *testing.T) {
-	if os.Getenv("TEST_CRASH") == "1" {
-		CreateDirectory("/root/forbidden-dir") // Should trigger os.Exit(123)
-	}
+	err = CreateDirectory(filepath.Join(parentFile, "child"))
+	assert.Error(t, err)
 }
@@ -0,0 +1,195 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+)
+
+/*
+  MachineSize - The sizing used for a group of machines
+*/
+type MachineSize struct {
+  Memory int           // The memory for the machines
+  Cpu int              // The CPU setting for the machines
+  DiskSize string      // The size of the primary disk
+}
+
+/*
+  ClusterSpec - Everything needed to build a new cluster
+  definition, this is used when creating clusters from the
+  cli instead of editing the settings file
+*/
+type ClusterSpec struct {
+  ClusterType string           // (single or ha) the type of cluster
+  ProviderName string          // The name of the provider the cluster uses
+  KubeConfigName string        // The name for the cluster in kubeconfig
+  LeaderCount int              // The number of leader machines
+  WorkerCount int              // The number of worker machines
+  LeaderSize MachineSize       // The sizing for leader machines
+  WorkerSize MachineSize       // The sizing for worker machines
+  StartIp string               // The first ip, machines get sequential ips from here
+  Vip string                   // The vip for the cluster
+  Features ClusterFeatures     // The features for the cluster
+}
+
+/*
+Builds a new cluster definition from a spec, machines are named
+after the cluster and get sequential ips starting at the start ip
+(or blank ips if there is no start ip). Clusters with kube-vip and
+a start ip but no vip get the ip after the machines as the vip.
+Defaults are set for the cluster features
+*/
+func NewCluster(clusterName string, spec ClusterSpec) (Cluster, error) {
+  var leaders []Machine
+  workers := []Machine{}
+
+  if spec.ClusterType == "single" && (spec.LeaderCount != 1 || spec.WorkerCount != 0) {
+    logger.LogError("Error single clusters have one leader and no workers")
+    return Cluster{}, errors.New("single clusters have one leader and no workers")
+  }
+
+  if spec.LeaderCount < 1 {
+    logger.LogError("Error at least one leader is required")
+    return Cluster{}, errors.New("at least one leader is required")
+  }
+
+  // ha clusters always use kube-vip for the api server
+  features := spec.Features
+  if spec.ClusterType == "ha" {
+    features.KubeVipEnable = true
+  }
+
+  // with a start ip and no vip the vip is the ip after the machines
+  vip := spec.Vip
+  ipCount := spec.LeaderCount + spec.WorkerCount
+  deriveVip := features.KubeVipEnable && vip == "" && spec.StartIp != ""
+  if deriveVip {
+    ipCount++
+  }
+
+  // without a start ip the ips are left blank to be
+  // allocated from the provider ip pool
+  ips := make([]string, ipCount)
+  if spec.StartIp != "" {
+    var err error
+    ips, err = SequentialIps(spec.StartIp, ipCount)
+    if err != nil {
+      logger.LogError("Error getting ips for the machines")
+      return Cluster{}, err
+    }
+  }
+
+  if deriveVip {
+    vip = ips[ipCount - 1]
+    logger.LogDebug("Using the ip after the machines as the vip", "vip", vip)
+  }
+
+  for index := 0; index < spec.LeaderCount; index++ {
+    name := fmt.Sprintf("%s-cp%02d", clusterName, index + 1)
+    if spec.ClusterType == "single" {
+      name = clusterName
+    }
+
+    logger.LogDebug("Adding leader to new cluster", "name", name, "ip", ips[index])
+    leaders = append(leaders, Machine{
+      Name: name,
+      IpAddress: ips[index],
+      Memory: spec.LeaderSize.Memory,
+      Cpu: spec.LeaderSize.Cpu,
+      DiskSize: spec.LeaderSize.DiskSize,
+    })
+  }
+
+  for index := 0; index < spec.WorkerCount; index++ {
+    name := fmt.Sprintf("%s-w%02d", clusterName, index + 1)
+    ip := ips[spec.LeaderCount + index]
+
+    logger.LogDebug("Adding worker to new cluster", "name", name, "ip", ip)
+    workers = append(workers, Machine{
+      Name: name,
+      IpAddress: ip,
+      Memory: spec.WorkerSize.Memory,
+      Cpu: spec.WorkerSize.Cpu,
+      DiskSize: spec.WorkerSize.DiskSize,
+    })
+  }
+
+  // a blank vip will be allocated from the ip pool at cluster up,
+  // the defaults are set then instead
+  if !(features.KubeVipEnable && vip == "") {
+    err := features.SetDefaults(spec.ClusterType, vip)
+    if err != nil {
+      logger.LogError("Error setting defaults for the cluster features")
+      return Cluster{}, err
+    }
+  }
+
+  return Cluster{
+    KubeConfigName: spec.KubeConfigName,
+    Vip: vip,
+    ClusterType: spec.ClusterType,
+    ProviderName: spec.ProviderName,
+    Leaders: leaders,
+    Workers: workers,
+    ClusterFeatures: &features,
+  }, nil
+}
+
+/*
+Gets a list of count sequential ipv4 addresses starting
+with the start ip, the last address given out is .254
+*/
+func SequentialIps(startIp string, count int) ([]string, error) {
+  ips := []string{}
+
+  ip := net.ParseIP(startIp).To4()
+  if ip == nil {
+    logger.LogError("Error start ip is not a valid ipv4 address", "ip", startIp)
+    return nil, fmt.Errorf("%q is not a valid ipv4 address", startIp)
+  }
+
+  current := make(net.IP, len(ip))
+  copy(current, ip)
+
+  for index := 0; index < count; index++ {
+    if index > 0 {
+      current[3]++
+    }
+
+    // .255 is the broadcast address of the /24 networks the providers use
+    if current[3] == 0 || current[3] == 255 {
+      logger.LogError("Error ran out of addresses in the subnet", "start", startIp)
+      return nil, fmt.Errorf("not enough addresses after %s for %d machines", startIp, count)
+    }
+    ips = append(ips, current.String())
+  }
+  return ips, nil
+}
+
+/*
+Adds a new cluster to the settings, it is an error
+if the cluster already exists
+*/
+func (settings *Settings) AddCluster(clusterName string, cluster Cluster) error {
+  if _, exists := settings.Clusters[clusterName]; exists {
+    logger.LogError("Error cluster already exists in settings", "cluster", clusterName)
+    return fmt.Errorf("cluster %s already exists", clusterName)
+  }
+
+  if settings.Clusters == nil {
+    settings.Clusters = make(map[string]Cluster)
+  }
+
+  settings.Clusters[clusterName] = cluster
+  return nil
+}
+
+/*
+Gets a sorted list of the provider names in the settings
+*/
+func (settings *Settings) GetProviderNames() []string {
+  return sortedKeys(settings.Providers)
+}
@@ -0,0 +1,134 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for NewCluster
+*/
+func TestNewClusterSingle(t *testing.T) {
+  spec := ClusterSpec{
+    ClusterType: "single",
+    ProviderName: "vmware",
+    LeaderCount: 1,
+    LeaderSize: MachineSize{Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+    StartIp: "192.168.10.10",
+    Features: ClusterFeatures{CniController: "flannel", StorageController: "local-storage"},
+  }
+
+  cluster, err := NewCluster("dev", spec)
+  assert.NoError(t, err)
+
+  assert.Len(t, cluster.Leaders, 1)
+  assert.Len(t, cluster.Workers, 0)
+  assert.Equal(t, "dev", cluster.Leaders[0].Name)
+  assert.Equal(t, "192.168.10.10", cluster.Leaders[0].IpAddress)
+  assert.Equal(t, "1.31.4", cluster.ClusterFeatures.KubeVersion)
+}
+
+func TestNewClusterHa(t *testing.T) {
+  spec := ClusterSpec{
+    ClusterType: "ha",
+    ProviderName: "vmware",
+    LeaderCount: 3,
+    WorkerCount: 2,
+    LeaderSize: MachineSize{Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+    WorkerSize: MachineSize{Memory: 8192, Cpu: 4, DiskSize: "100GB"},
+    StartIp: "192.168.10.10",
+    Vip: "192.168.10.100",
+    Features: ClusterFeatures{CniController: "flannel", StorageController: "local-storage", KubeVipEnable: true},
+  }
+
+  cluster, err := NewCluster("prod", spec)
+  assert.NoError(t, err)
+
+  assert.Equal(t, "prod-cp01", cluster.Leaders[0].Name)
+  assert.Equal(t, "prod-cp03", cluster.Leaders[2].Name)
+  assert.Equal(t, "192.168.10.12", cluster.Leaders[2].IpAddress)
+  assert.Equal(t, "prod-w02", cluster.Workers[1].Name)
+  assert.Equal(t, "192.168.10.14", cluster.Workers[1].IpAddress)
+  assert.Equal(t, 8192, cluster.Workers[0].Memory)
+}
+
+func TestNewClusterHaWithoutKubeVip(t *testing.T) {
+  // the non interactive cluster-create without --kube-vip
+  spec := ClusterSpec{
+    ClusterType: "ha",
+    ProviderName: "vmware",
+    LeaderCount: 3,
+    WorkerCount: 2,
+    LeaderSize: MachineSize{Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+    WorkerSize: MachineSize{Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+    StartIp: "192.168.10.10",
+    Vip: "192.168.10.100",
+    Features: ClusterFeatures{},
+  }
+
+  cluster, err := NewCluster("prod", spec)
+  assert.NoError(t, err)
+  assert.True(t, cluster.ClusterFeatures.KubeVipEnable)
+
+  // without a vip the vip is the ip after the machines
+  spec.Vip = ""
+  cluster, err = NewCluster("prod", spec)
+  assert.NoError(t, err)
+  assert.Equal(t, "192.168.10.14", cluster.Workers[1].IpAddress)
+  assert.Equal(t, "192.168.10.15", cluster.Vip)
+  assert.NotEqual(t, "", cluster.ClusterFeatures.KubeVipVersion)
+
+  // with the ips and vip from the provider ip pool
+  spec.StartIp = ""
+  spec.Vip = ""
+  cluster, err = NewCluster("prod", spec)
+  assert.NoError(t, err)
+  assert.True(t, cluster.ClusterFeatures.KubeVipEnable)
+  assert.Equal(t, "", cluster.Leaders[0].IpAddress)
+}
+
+func TestNewClusterSingleWithWorkers(t *testing.T) {
+  spec := ClusterSpec{
+    ClusterType: "single",
+    LeaderCount: 1,
+    WorkerCount: 1,
+    StartIp: "192.168.10.10",
+  }
+
+  _, err := NewCluster("dev", spec)
+  assert.Error(t, err)
+}
+
+/*
+      Tests for SequentialIps
+*/
+func TestSequentialIps(t *testing.T) {
+  ips, err := SequentialIps("10.0.0.252", 3)
+  assert.NoError(t, err)
+  assert.Equal(t, []string{"10.0.0.252", "10.0.0.253", "10.0.0.254"}, ips)
+
+  // the broadcast address is never given out
+  _, err = SequentialIps("10.0.0.253", 3)
+  assert.Error(t, err)
+
+  _, err = SequentialIps("10.0.0.255", 1)
+  assert.Error(t, err)
+
+  _, err = SequentialIps("not-an-ip", 1)
+  assert.Error(t, err)
+}
+
+/*
+      Tests for AddCluster
+*/
+func TestAddClusterExisting(t *testing.T) {
+  settings := getValidSettings()
+
+  err := settings.AddCluster("dev", Cluster{})
+  assert.Error(t, err)
+
+  err = settings.AddCluster("new", Cluster{ClusterType: "single"})
+  assert.NoError(t, err)
+  assert.Equal(t, "single", settings.Clusters["new"].ClusterType)
+}
@@ -1,39 +1,46 @@
 package settings
 
 import (
-	"os"
 	"path/filepath"
 
 	"github.com/dgutierrez1287/local-kube/logger"
 )
 
 // Run basic checks to make sure init has been run and needed files
-// are there
-func PreflightCheck(appDir string) bool {
-  appDirExist := DirectoryExists(appDir)
-  ansibleRoleDirExist := DirectoryExists(filepath.Join(appDir, "ansible-roles"))
-  settingsExist, err := SettingsFileExists(appDir)
+// are there, an error is returned if the checks could not be run
+func PreflightCheck(appDir string) (bool, error) {
+  appDirExist, err := DirectoryExists(appDir)
+  if err != nil {
+    logger.LogError("Error checking if app directory exists", "error", err)
+    return false, err
+  }
+
+  ansibleRoleDirExist, err := DirectoryExists(filepath.Join(appDir, "ansible-roles"))
+  if err != nil {
+    logger.LogError("Error checking if ansible-role directory exists", "error", err)
+    return false, err
+  }
 
+  settingsExist, err := SettingsFileExists(appDir)
   if err != nil {
     logger.LogError("Error checking if settings file exists", "error", err)
-    os.Exit(100)
+    return false, err
   }
 
   if !appDirExist {
     logger.LogError("Preflight check failed, app directory does not exist")
-    return false
+    return false, nil
   }
 
   if !ansibleRoleDirExist {
     logger.LogError("Preflight check failed, ansible-role directory does not exist")
-    return false
+    return false, nil
   }
 
   if !settingsExist {
     logger.LogError("Preflight check failed, settings file does not exist")
-    return false 
+    return false, nil
   }
-  return true
+  return true, nil
 }
 
-
@@ -16,7 +16,8 @@ func TestPreflightPass(t *testing.T) {
 
   CreateDefaultSettingsFile(util.MockAppDir)
 
-  result := PreflightCheck(util.MockAppDir)
+  result, err := PreflightCheck(util.MockAppDir)
+  assert.NoError(t, err)
 
   assert.True(t, result)
 
@@ -28,7 +29,8 @@ func TestPreflightFail(t *testing.T) {
   err := util.MockAppDirSetup()
   assert.NoError(t, err)
 
-  result := PreflightCheck(util.MockAppDir)
+  result, err := PreflightCheck(util.MockAppDir)
+  assert.NoError(t, err)
 
   assert.False(t, result)
 
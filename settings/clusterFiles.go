@@ -0,0 +1,243 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+)
+
+/*
+  The directory in the app dir that holds cluster
+  definitions, one cluster per file named <cluster>.json
+*/
+const ClusterFilesDir = "clusters.d"
+
+/*
+Gets the path to the cluster file for a cluster
+*/
+func GetClusterFilePath(appDir string, clusterName string) string {
+  return filepath.Join(appDir, ClusterFilesDir, clusterName + ".json")
+}
+
+/*
+reads all the cluster files in the clusters.d directory, the
+cluster name is the file name without the .json extension. Cluster
+files on an old schema are migrated first. If the directory does
+not exist no clusters are returned
+*/
+func readClusterFiles(appDir string) (map[string]Cluster, error) {
+  clusters := make(map[string]Cluster)
+
+  clusterFiles, err := filepath.Glob(filepath.Join(appDir, ClusterFilesDir, "*.json"))
+  if err != nil {
+    logger.LogError("Error listing cluster files")
+    return nil, err
+  }
+
+  for _, clusterFile := range clusterFiles {
+    clusterName := strings.TrimSuffix(filepath.Base(clusterFile), ".json")
+    logger.LogDebug("Reading cluster file", "cluster", clusterName, "file", clusterFile)
+
+    bytes, err := os.ReadFile(clusterFile)
+    if err != nil {
+      logger.LogError("Error reading cluster file", "file", clusterFile)
+      return nil, err
+    }
+
+    bytes, err = migrateClusterFile(clusterName, clusterFile, bytes)
+    if err != nil {
+      return nil, fmt.Errorf("%s: %w", clusterFile, err)
+    }
+
+    var cluster Cluster
+    err = json.Unmarshal(bytes, &cluster)
+    if err != nil {
+      logger.LogError("Error unmarshaling cluster file", "file", clusterFile)
+      return nil, fmt.Errorf("%s: %w", clusterFile, err)
+    }
+    clusters[clusterName] = cluster
+  }
+  return clusters, nil
+}
+
+// migrates a cluster file on an old schema, the original file is
+// backed up to <cluster>.json.v<version>.bak before it is rewritten
+func migrateClusterFile(clusterName string, clusterFile string, original []byte) ([]byte, error) {
+  fromVersion, migrated, err := migrateClusterBytes(clusterName, original)
+  if err != nil {
+    logger.LogError("Error migrating cluster file", "file", clusterFile)
+    return nil, err
+  }
+
+  if fromVersion == CurrentSchemaVersion {
+    return original, nil
+  }
+
+  backupFile := fmt.Sprintf("%s.v%d.bak", clusterFile, fromVersion)
+  logger.LogInfo("Backing up cluster file before migration", "backup", backupFile)
+
+  err = os.WriteFile(backupFile, original, 0644)
+  if err != nil {
+    logger.LogError("Error backing up the cluster file")
+    return nil, err
+  }
+
+  err = os.WriteFile(clusterFile, migrated, 0644)
+  if err != nil {
+    logger.LogError("Error writing the migrated cluster file")
+    return nil, err
+  }
+
+  logger.LogInfo("Cluster file migrated", "cluster", clusterName, "from", fromVersion, "to", CurrentSchemaVersion)
+  return migrated, nil
+}
+
+/*
+merges the clusters from cluster files into the settings, a
+cluster defined in both settings.json and a cluster file (or
+in two places any other way) is an error
+*/
+func (settings *Settings) mergeClusterFiles(clusterFileClusters map[string]Cluster) error {
+  var collisions []string
+
+  if settings.Clusters == nil {
+    settings.Clusters = make(map[string]Cluster)
+  }
+
+  for _, clusterName := range sortedKeys(clusterFileClusters) {
+    if _, exists := settings.Clusters[clusterName]; exists {
+      logger.LogError("Error cluster is defined in settings.json and in a cluster file", "cluster", clusterName)
+      collisions = append(collisions, clusterName)
+      continue
+    }
+
+    settings.Clusters[clusterName] = clusterFileClusters[clusterName]
+    settings.markClusterFile(clusterName)
+  }
+
+  if len(collisions) > 0 {
+    return fmt.Errorf("clusters defined in both settings.json and %s: %s",
+      ClusterFilesDir, strings.Join(collisions, ", "))
+  }
+  return nil
+}
+
+// records that a cluster is stored in its own cluster file
+func (settings *Settings) markClusterFile(clusterName string) {
+  if settings.clusterFiles == nil {
+    settings.clusterFiles = make(map[string]bool)
+  }
+  settings.clusterFiles[clusterName] = true
+}
+
+/*
+Checks if a cluster is stored in its own file in
+clusters.d instead of settings.json
+*/
+func (settings *Settings) IsClusterFile(clusterName string) bool {
+  return settings.clusterFiles[clusterName]
+}
+
+/*
+writes a single cluster to its cluster file with
+the current schema version
+*/
+func writeClusterFile(appDir string, clusterName string, cluster Cluster) error {
+  clusterFile := GetClusterFilePath(appDir, clusterName)
+
+  err := os.MkdirAll(filepath.Dir(clusterFile), 0755)
+  if err != nil {
+    logger.LogError("Error creating cluster files directory")
+    return err
+  }
+
+  // cluster files keep their own schema version
+  versioned := struct {
+    SchemaVersion int   `json:"schemaVersion"`
+    Cluster
+  }{CurrentSchemaVersion, cluster}
+
+  var bytes []byte
+  bytes, err = json.MarshalIndent(versioned, "", " ")
+  if err != nil {
+    logger.LogError("Error encoding cluster", "cluster", clusterName)
+    return err
+  }
+
+  logger.LogDebug("Writing cluster file", "cluster", clusterName, "file", clusterFile)
+  err = writeFileAtomic(clusterFile, append(bytes, '\n'))
+  if err != nil {
+    logger.LogError("Error writing cluster file", "file", clusterFile)
+    return err
+  }
+  return nil
+}
+
+/*
+SplitSettingsFile()
+Moves every cluster in settings.json out to its own file in
+clusters.d, settings.json is backed up before it is rewritten
+without any clusters. The names of the clusters that were moved
+are returned
+*/
+func SplitSettingsFile(appDir string) ([]string, error) {
+  settings, err := ReadSettingsFile(appDir)
+  if err != nil {
+    return nil, err
+  }
+
+  var splitClusters []string
+  for clusterName := range settings.Clusters {
+    if !settings.IsClusterFile(clusterName) {
+      splitClusters = append(splitClusters, clusterName)
+    }
+  }
+  sort.Strings(splitClusters)
+
+  if len(splitClusters) == 0 {
+    logger.LogInfo("No clusters in settings.json to split")
+    return splitClusters, nil
+  }
+
+  // make sure nothing would be overwritten before writing anything
+  for _, clusterName := range splitClusters {
+    _, err := os.Stat(GetClusterFilePath(appDir, clusterName))
+    if err == nil {
+      logger.LogError("Error cluster file already exists", "cluster", clusterName)
+      return nil, fmt.Errorf("cluster file for %s already exists", clusterName)
+    } else if !errors.Is(err, os.ErrNotExist) {
+      return nil, err
+    }
+  }
+
+  settingsFile := filepath.Join(appDir, "settings.json")
+  original, err := os.ReadFile(settingsFile)
+  if err != nil {
+    logger.LogError("Error reading settings file")
+    return nil, err
+  }
+
+  backupFile := settingsFile + ".split.bak"
+  logger.LogInfo("Backing up settings file before split", "backup", backupFile)
+  err = os.WriteFile(backupFile, original, 0644)
+  if err != nil {
+    logger.LogError("Error backing up the settings file")
+    return nil, err
+  }
+
+  for _, clusterName := range splitClusters {
+    settings.markClusterFile(clusterName)
+  }
+
+  err = WriteSettingsFile(appDir, settings)
+  if err != nil {
+    return nil, err
+  }
+  return splitClusters, nil
+}
@@ -0,0 +1,191 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// writes a settings file and some cluster files to the mock app dir
+func writeClusterFilesFixture(t *testing.T, clusterFiles map[string]string) {
+  settings := getValidSettings()
+  settings.SchemaVersion = CurrentSchemaVersion
+  delete(settings.Clusters, "prod")
+
+  err := WriteSettingsFile(util.MockAppDir, settings)
+  assert.NoError(t, err)
+
+  err = os.MkdirAll(filepath.Join(util.MockAppDir, ClusterFilesDir), 0755)
+  assert.NoError(t, err)
+
+  for clusterName, content := range clusterFiles {
+    err = os.WriteFile(GetClusterFilePath(util.MockAppDir, clusterName), []byte(content), 0644)
+    assert.NoError(t, err)
+  }
+}
+
+/*
+      Tests for ReadSettingsFile with clusters.d
+*/
+func TestReadSettingsFileClusterFiles(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  writeClusterFilesFixture(t, map[string]string{
+    "staging": `{"clusterType": "single", "providerName": "vmware"}`,
+  })
+
+  settings, err := ReadSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+
+  assert.Contains(t, settings.Clusters, "dev")
+  assert.Contains(t, settings.Clusters, "staging")
+  assert.Equal(t, "single", settings.Clusters["staging"].ClusterType)
+  assert.True(t, settings.IsClusterFile("staging"))
+  assert.False(t, settings.IsClusterFile("dev"))
+}
+
+func TestReadSettingsFileClusterFileMigrates(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  oldClusterFile := "{\n \"clusterType\": \"single\",\n \"clusterFeatures\": {\n  \"cillumCliVersion\": \"0.16.0\"\n }\n}\n"
+  writeClusterFilesFixture(t, map[string]string{
+    "staging": oldClusterFile,
+    "current": `{"schemaVersion": 1, "clusterType": "single", "clusterFeatures": {"cillumCliVersion": "0.1.0"}}`,
+  })
+
+  settings, err := ReadSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+  assert.Equal(t, "0.16.0", settings.Clusters["staging"].ClusterFeatures.CiliumCliVersion)
+
+  content, err := os.ReadFile(GetClusterFilePath(util.MockAppDir, "staging"))
+  assert.NoError(t, err)
+  assert.Equal(t, "{\n \"schemaVersion\": 1,\n \"clusterType\": \"single\",\n \"clusterFeatures\": {\n  \"ciliumCliVersion\": \"0.16.0\"\n }\n}\n",
+    string(content))
+
+  backup, err := os.ReadFile(GetClusterFilePath(util.MockAppDir, "staging") + ".v0.bak")
+  assert.NoError(t, err)
+  assert.Equal(t, oldClusterFile, string(backup))
+
+  // cluster files on the current schema are not migrated again
+  content, err = os.ReadFile(GetClusterFilePath(util.MockAppDir, "current"))
+  assert.NoError(t, err)
+  assert.Equal(t, `{"schemaVersion": 1, "clusterType": "single", "clusterFeatures": {"cillumCliVersion": "0.1.0"}}`, string(content))
+  assert.NoFileExists(t, GetClusterFilePath(util.MockAppDir, "current") + ".v1.bak")
+
+  // migrating again changes nothing
+  settings, err = ReadSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+  assert.Equal(t, "0.16.0", settings.Clusters["staging"].ClusterFeatures.CiliumCliVersion)
+}
+
+func TestReadSettingsFileClusterFileNewerVersion(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  writeClusterFilesFixture(t, map[string]string{
+    "staging": `{"schemaVersion": 99, "clusterType": "single"}`,
+  })
+
+  _, err = ReadSettingsFile(util.MockAppDir)
+  assert.ErrorContains(t, err, "newer")
+}
+
+func TestReadSettingsFileClusterFileCollision(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  writeClusterFilesFixture(t, map[string]string{
+    "dev": `{"clusterType": "single"}`,
+  })
+
+  _, err = ReadSettingsFile(util.MockAppDir)
+  assert.ErrorContains(t, err, "dev")
+}
+
+func TestReadSettingsFileClusterFileInvalid(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  writeClusterFilesFixture(t, map[string]string{
+    "broken": `{"clusterType": `,
+  })
+
+  _, err = ReadSettingsFile(util.MockAppDir)
+  assert.ErrorContains(t, err, "broken.json")
+}
+
+/*
+      Tests for WriteSettingsFile with clusters.d
+*/
+func TestWriteSettingsFileKeepsClusterFiles(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  writeClusterFilesFixture(t, map[string]string{
+    "staging": `{"clusterType": "single"}`,
+  })
+
+  settings, err := ReadSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+
+  staging := settings.Clusters["staging"]
+  staging.Vip = "192.168.1.50"
+  settings.Clusters["staging"] = staging
+
+  err = WriteSettingsFile(util.MockAppDir, settings)
+  assert.NoError(t, err)
+
+  settingsContent, err := os.ReadFile(filepath.Join(util.MockAppDir, "settings.json"))
+  assert.NoError(t, err)
+  assert.NotContains(t, string(settingsContent), "staging")
+
+  clusterContent, err := os.ReadFile(GetClusterFilePath(util.MockAppDir, "staging"))
+  assert.NoError(t, err)
+  assert.Contains(t, string(clusterContent), "192.168.1.50")
+}
+
+/*
+      Tests for SplitSettingsFile
+*/
+func TestSplitSettingsFile(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  writeClusterFilesFixture(t, map[string]string{})
+
+  splitClusters, err := SplitSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+  assert.Equal(t, []string{"dev"}, splitClusters)
+
+  assert.FileExists(t, GetClusterFilePath(util.MockAppDir, "dev"))
+  assert.FileExists(t, filepath.Join(util.MockAppDir, "settings.json.split.bak"))
+
+  settings, err := ReadSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+  assert.True(t, settings.IsClusterFile("dev"))
+  assert.Equal(t, "vmware", settings.Clusters["dev"].ProviderName)
+
+  // nothing left to split
+  splitClusters, err = SplitSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+  assert.Empty(t, splitClusters)
+}
@@ -10,13 +10,23 @@ import (
   Cluster - Settings for a cluster
 */
 type Cluster struct {
+  Extends string                    `json:"extends,omitempty"`            // A cluster or built in preset this cluster inherits settings from (inherited bools can not be turned off)
   KubeConfigName string             `json:"kubeconfigName,omitempty"`     // The name for the cluster in kubeconfig (if empty cluster name is used)
   Vip string                        `json:"vip,omitempty"`                // The vip for the kubernetes cluster
   ClusterType string                `json:"clusterType,omitempty"`        // (single or ha) the type of cluster
   ProviderName string               `json:"providerName,omitempty"`       // The name of the provider that the cluster uses
   Leaders []Machine                 `json:"leaders"`                      // a list of leader machines
   Workers []Machine                 `json:"workers"`                      // a list of worker machines
+  WorkerPools []WorkerPool          `json:"workerPools,omitempty"`        // pools of identical workers that are expanded into workers
   ClusterFeatures *ClusterFeatures  `json:"clusterFeatures,omitempty"`    // feature configuration only used if autoConfigre is true
+  Addons []Addon                    `json:"addons,omitempty"`             // helm charts installed on the cluster
+  Registries *RegistrySettings      `json:"registries,omitempty"`         // registry mirrors and config, merged on top of the global registries
+  ForwardedPorts *ClusterForwardedPorts  `json:"forwardedPorts,omitempty"`  // host ports for the api server and ingress, forwarded from the lead machine
+  SyncedFolders []SyncedFolder      `json:"syncedFolders,omitempty"`      // host directories synced into every machine
+  Network *NetworkSettings          `json:"network,omitempty"`            // network settings, the cluster dns replaces the global dns
+  Proxy *ProxySettings              `json:"proxy,omitempty"`              // the proxy, merged on top of the global proxy
+  CaCertificates []string           `json:"caCertificates,omitempty"`     // ca certificate files the machines trust, added to the global certificates
+  Hooks *Hooks                      `json:"hooks,omitempty"`              // commands and scripts run around the cluster operations
 }
 
 /*
@@ -29,6 +39,29 @@ type Machine struct {
   Memory int          `json:"memory" yaml:"memory,omitempty"`       // The memory for the machine
   Cpu int             `json:"cpus" yaml:"cpus,omitempty"`             // The CPU setting for the machine
   DiskSize string     `json:"diskSize" yaml:"disk_size,omitempty"`  // The size of the primary disk
+  Labels map[string]string  `json:"labels,omitempty" yaml:"labels,omitempty"`  // Kubernetes node labels for the machine
+  Taints []string           `json:"taints,omitempty" yaml:"taints,omitempty"`  // Kubernetes node taints for the machine (key=value:Effect)
+  Disks []Disk              `json:"disks,omitempty" yaml:"disks,omitempty"`    // Additional data disks for the machine
+  Networks []Network        `json:"networks,omitempty" yaml:"networks,omitempty"`  // Additional network interfaces for the machine
+  ForwardedPorts []ForwardedPort  `json:"forwardedPorts,omitempty" yaml:"forwarded_ports,omitempty"`  // Ports forwarded from the host to the machine
+  K3sArgs                   `yaml:"-"`                                         // k3s arguments that override the cluster feature args
+}
+
+/*
+  WorkerPool - A group of identical worker machines, pools
+  are expanded into workers named <namePrefix>01, <namePrefix>02...
+*/
+type WorkerPool struct {
+  NamePrefix string              `json:"namePrefix"`              // The prefix for the machine names
+  Count int                      `json:"count"`                   // The number of machines in the pool
+  StartIp string                 `json:"startIp,omitempty"`       // The first ip for the pool (if empty ips come from the provider ip pool)
+  Memory int                     `json:"memory"`                  // The memory for each machine
+  Cpu int                        `json:"cpus"`                    // The CPU setting for each machine
+  DiskSize string                `json:"diskSize"`                // The size of the primary disk for each machine
+  Labels map[string]string       `json:"labels,omitempty"`        // Kubernetes node labels for each machine
+  Taints []string                `json:"taints,omitempty"`        // Kubernetes node taints for each machine (key=value:Effect)
+  Disks []Disk                   `json:"disks,omitempty"`         // Additional data disks for each machine
+  K3sArgs                                                         // k3s arguments that override the cluster feature args for each machine
 }
 
 /*
@@ -64,11 +97,17 @@ func (cluster Cluster) GetMachineNameList() []string {
 }
 
 /*
-Gets the server url for the cluster based on if 
-kubevip is enabled and if the cluster is ha or single
+Gets the server url for the cluster based on if the api
+server is forwarded, kubevip is enabled and if the cluster
+is ha or single
 */
 func (cluster Cluster) GetServerUrl() string {
 
+  if cluster.ForwardedPorts != nil && cluster.ForwardedPorts.ApiServer != 0 {
+    logger.LogDebug("The api server is forwarded returning the forwarded localhost url")
+    return fmt.Sprintf("https://%s:%d", defaultForwardHostIp, cluster.ForwardedPorts.ApiServer)
+  }
+
   if cluster.ClusterFeatures.KubeVipEnable {
     logger.LogDebug("KubeVip is enabled returning the server url using kubevip")
     return fmt.Sprintf("https://%s:6443", cluster.Vip)
@@ -79,6 +118,17 @@ func (cluster Cluster) GetServerUrl() string {
   return fmt.Sprintf("https://%s:6443", leaderIp)
 }
 
+/*
+Gets the name of the cluster in kubeconfig, this is the
+cluster name if no kubeconfig name is set
+*/
+func (cluster Cluster) GetKubeConfigName(clusterName string) string {
+  if cluster.KubeConfigName == "" {
+    return clusterName
+  }
+  return cluster.KubeConfigName
+}
+
 /*
 Gets the vagrant machine name for the node that is 
 setup with ansible for provisioning, in multi-node this
@@ -92,6 +142,18 @@ func (cluster Cluster) GetAnsibleNodeVagrantName() string {
   }
 }  
 
+/*
+Gets the vagrant machine names of the cluster, the control
+nodes first and then the workers. A single cluster only has
+the default machine
+*/
+func (cluster Cluster) GetVagrantMachineNames() []string {
+  if cluster.ClusterType == "single" {
+    return []string{"default"}
+  }
+  return cluster.GetMachineNameList()
+}
+
 /*
 Gets a list of just the worker node names 
 */
@@ -118,6 +180,18 @@ func (cluster Cluster) GetControlNodeIps() []string {
   return ips
 }
 
+/*
+Gets a list of worker node IPs
+*/
+func (cluster Cluster) GetWorkerNodeIps() []string {
+  ips := []string{}
+
+  for _, node := range cluster.Workers {
+    ips = append(ips, node.IpAddress)
+  }
+  return ips
+}
+
 /*
 check if the cluster is ha or not
 */
@@ -130,3 +204,62 @@ func (cluster Cluster) IsHA() bool {
 }
 
 
+
+/*
+Gets the machines for a worker pool, when the pool has
+no start ip the ips are left blank to be allocated
+from the provider ip pool
+*/
+func (pool WorkerPool) GetMachines() ([]Machine, error) {
+  machines := []Machine{}
+
+  ips := make([]string, pool.Count)
+  if pool.StartIp != "" {
+    var err error
+    ips, err = SequentialIps(pool.StartIp, pool.Count)
+    if err != nil {
+      logger.LogError("Error getting ips for worker pool", "pool", pool.NamePrefix)
+      return nil, err
+    }
+  }
+
+  for index := 0; index < pool.Count; index++ {
+    machines = append(machines, Machine{
+      Name: fmt.Sprintf("%s%02d", pool.NamePrefix, index + 1),
+      IpAddress: ips[index],
+      Memory: pool.Memory,
+      Cpu: pool.Cpu,
+      DiskSize: pool.DiskSize,
+      Labels: pool.Labels,
+      Taints: pool.Taints,
+      Disks: pool.Disks,
+      K3sArgs: pool.K3sArgs,
+    })
+  }
+  return machines, nil
+}
+
+/*
+Expands the worker pools for a cluster into workers, the pool
+machines are added after any workers defined on their own
+*/
+func (cluster Cluster) ExpandWorkerPools() (Cluster, error) {
+  if len(cluster.WorkerPools) == 0 {
+    return cluster, nil
+  }
+
+  workers := append([]Machine{}, cluster.Workers...)
+  for _, pool := range cluster.WorkerPools {
+    logger.LogDebug("Expanding worker pool", "pool", pool.NamePrefix, "count", pool.Count)
+
+    machines, err := pool.GetMachines()
+    if err != nil {
+      return Cluster{}, err
+    }
+    workers = append(workers, machines...)
+  }
+
+  cluster.Workers = workers
+  cluster.WorkerPools = nil
+  return cluster, nil
+}
@@ -81,6 +81,24 @@ func TestGetServerUrlVipEnabled(t *testing.T) {
   assert.Equal(t, expected, actual)
 } 
 
+func TestGetServerUrlForwarded(t *testing.T) {
+  cluster := Cluster{
+    Vip: "192.168.1.40",
+    Leaders: []Machine{
+      {Name: "leader1", IpAddress: "192.168.1.1", Memory: 4, Cpu: 2, DiskSize: "100GB"},
+    },
+    ClusterFeatures: &ClusterFeatures{
+      KubeVipEnable: true,
+    },
+    ForwardedPorts: &ClusterForwardedPorts{ApiServer: 16443},
+  }
+
+  expected := "https://127.0.0.1:16443"
+  actual := cluster.GetServerUrl()
+
+  assert.Equal(t, expected, actual)
+}
+
 func TestGetServerUrlNoVip(t *testing.T) {
   cluster := Cluster{
     Leaders: []Machine{
@@ -128,6 +146,36 @@ func TestGetAnsibleNodeVagrantNameHaCluster(t *testing.T) {
 }
 
 
+/*
+        Tests for GetVagrantMachineNames
+*/
+func TestGetVagrantMachineNamesSingleNode(t *testing.T) {
+  cluster := Cluster{
+    ClusterType: "single",
+    Leaders: []Machine{{Name: "dev", IpAddress: "192.168.1.10"}},
+  }
+
+  assert.Equal(t, []string{"default"}, cluster.GetVagrantMachineNames())
+}
+
+func TestGetVagrantMachineNamesHaCluster(t *testing.T) {
+  cluster := Cluster{
+    ClusterType: "ha",
+    Leaders: []Machine{
+      {Name: "leader1", IpAddress: "192.168.1.1"},
+      {Name: "leader2", IpAddress: "192.168.1.2"},
+    },
+    Workers: []Machine{{Name: "worker1", IpAddress: "192.168.2.1"}},
+  }
+
+  // control nodes are always before the workers
+  expected := []string{"leader1", "leader2", "worker1"}
+  actual := cluster.GetVagrantMachineNames()
+
+  assert.Equal(t, expected, actual)
+}
+
+
 /*
         Tests for GetWorkerNodeNames
 */
@@ -203,3 +251,52 @@ func TestClusterNotHa(t *testing.T) {
   isHa := cluster.IsHA()
   assert.False(t, isHa)
 }
+
+/*
+        Tests for ExpandWorkerPools
+*/
+func TestExpandWorkerPools(t *testing.T) {
+  cluster := Cluster{
+    ClusterType: "ha",
+    Workers: []Machine{
+      {Name: "prod-w01", IpAddress: "192.168.1.30"},
+    },
+    WorkerPools: []WorkerPool{
+      {
+        NamePrefix: "prod-gpu",
+        Count: 2,
+        StartIp: "192.168.1.40",
+        Memory: 8192,
+        Cpu: 4,
+        DiskSize: "100GB",
+        Labels: map[string]string{"gpu": "true"},
+        Taints: []string{"gpu=true:NoSchedule"},
+      },
+    },
+  }
+
+  expanded, err := cluster.ExpandWorkerPools()
+  assert.NoError(t, err)
+
+  assert.Nil(t, expanded.WorkerPools)
+  assert.Equal(t, []string{"prod-w01", "prod-gpu01", "prod-gpu02"}, expanded.GetWorkerNodeNames())
+  assert.Equal(t, "192.168.1.41", expanded.Workers[2].IpAddress)
+  assert.Equal(t, 8192, expanded.Workers[2].Memory)
+  assert.Equal(t, map[string]string{"gpu": "true"}, expanded.Workers[1].Labels)
+  assert.Equal(t, []string{"gpu=true:NoSchedule"}, expanded.Workers[1].Taints)
+
+  // the original cluster is not changed
+  assert.Len(t, cluster.Workers, 1)
+}
+
+func TestExpandWorkerPoolsBlankIps(t *testing.T) {
+  cluster := Cluster{
+    WorkerPools: []WorkerPool{
+      {NamePrefix: "w", Count: 2},
+    },
+  }
+
+  expanded, err := cluster.ExpandWorkerPools()
+  assert.NoError(t, err)
+  assert.Equal(t, "", expanded.Workers[1].IpAddress)
+}
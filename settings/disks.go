@@ -0,0 +1,105 @@
+package settings
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+/*
+  Disk - An additional data disk for a machine, the disk is
+  either formatted and mounted at the mount point or left as
+  a raw block device (ex for the longhorn v2 data engine)
+*/
+type Disk struct {
+  Name string          `json:"name" yaml:"name"`                                    // The name of the disk, used as the filesystem label
+  Size string          `json:"size" yaml:"size"`                                    // The size of the disk (ex 100GB)
+  MountPoint string    `json:"mountPoint,omitempty" yaml:"mount_point,omitempty"`   // Where the disk is mounted (ex /var/lib/longhorn)
+  Raw bool             `json:"raw,omitempty" yaml:"raw,omitempty"`                  // Leave the disk unformatted and unmounted
+}
+
+// disk names are used as ext4 labels which are at most 16 characters
+var diskNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,15}$`)
+
+// mount points that would hide the system
+var reservedMountPoints = []string{"/", "/boot", "/etc", "/usr", "/var", "/home", "/provision", "/vagrant", "/bundle"}
+
+/*
+GetMountPoints()
+Gets the mount points of the data disks that are not raw
+*/
+func (machine Machine) GetMountPoints() []string {
+  mountPoints := []string{}
+
+  for _, disk := range machine.Disks {
+    if !disk.Raw && disk.MountPoint != "" {
+      mountPoints = append(mountPoints, disk.MountPoint)
+    }
+  }
+  return mountPoints
+}
+
+// validates the data disks of a machine
+func (validator *settingsValidator) validateDisks(machinePath string, disks []Disk) {
+  names := make(map[string]string)
+  mountPoints := make(map[string]string)
+
+  for index, disk := range disks {
+    diskPath := fmt.Sprintf("%s.disks[%d]", machinePath, index)
+
+    if !diskNameRegex.MatchString(disk.Name) {
+      validator.add(diskPath + ".name", "malformed disk name %q, expected lowercase letters, numbers and dashes (at most 16)", disk.Name)
+    } else if otherPath, exists := names[disk.Name]; exists {
+      validator.add(diskPath + ".name", "disk name %q is already used by %s", disk.Name, otherPath)
+    } else {
+      names[disk.Name] = diskPath
+    }
+
+    if !diskSizeRegex.MatchString(disk.Size) {
+      validator.add(diskPath + ".size", "malformed disk size %q, expected a size like 50GB", disk.Size)
+    }
+
+    if disk.Raw {
+      if disk.MountPoint != "" {
+        validator.add(diskPath + ".mountPoint", "raw disks can not have a mount point")
+      }
+      continue
+    }
+
+    if disk.MountPoint == "" {
+      validator.add(diskPath + ".mountPoint", "mount point is required unless the disk is raw")
+    } else if !path.IsAbs(disk.MountPoint) || path.Clean(disk.MountPoint) != disk.MountPoint {
+      validator.add(diskPath + ".mountPoint", "mount point %q must be a clean absolute path", disk.MountPoint)
+    } else if contains(reservedMountPoints, disk.MountPoint) {
+      validator.add(diskPath + ".mountPoint", "mount point %s is reserved", disk.MountPoint)
+    } else if otherPath, exists := mountPoints[disk.MountPoint]; exists {
+      validator.add(diskPath + ".mountPoint", "mount point %s is already used by %s", disk.MountPoint, otherPath)
+    } else {
+      mountPoints[disk.MountPoint] = diskPath
+    }
+  }
+}
+
+// validates the storage data path is a disk mount point on the
+// machines that run workloads (the workers or the single leader)
+func (validator *settingsValidator) validateStorageDataPath(path string, cluster Cluster, features ClusterFeatures) {
+  if features.StorageDataPath == "" {
+    return
+  }
+
+  if features.StorageController != "longhorn" {
+    validator.add(path + ".storageDataPath", "storage data path is only used with the longhorn storage controller")
+    return
+  }
+
+  machines := cluster.Workers
+  if len(machines) == 0 {
+    machines = cluster.Leaders
+  }
+
+  for _, machine := range machines {
+    if !contains(machine.GetMountPoints(), features.StorageDataPath) {
+      validator.add(path + ".storageDataPath", "machine %s has no data disk mounted at %s", machine.Name, features.StorageDataPath)
+    }
+  }
+}
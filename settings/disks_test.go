@@ -0,0 +1,110 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for GetMountPoints
+*/
+func TestGetMountPoints(t *testing.T) {
+  machine := Machine{
+    Disks: []Disk{
+      {Name: "longhorn", Size: "200GB", MountPoint: "/var/lib/longhorn"},
+      {Name: "block", Size: "100GB", Raw: true},
+      {Name: "data", Size: "50GB", MountPoint: "/data"},
+    },
+  }
+
+  assert.Equal(t, []string{"/var/lib/longhorn", "/data"}, machine.GetMountPoints())
+  assert.Empty(t, Machine{}.GetMountPoints())
+}
+
+func TestWorkerPoolGetMachinesDisks(t *testing.T) {
+  pool := WorkerPool{NamePrefix: "storage", Count: 2, Memory: 4096, Cpu: 2, DiskSize: "50GB",
+    Disks: []Disk{{Name: "longhorn", Size: "200GB", MountPoint: "/var/lib/longhorn"}}}
+
+  machines, err := pool.GetMachines()
+  assert.NoError(t, err)
+
+  for _, machine := range machines {
+    assert.Equal(t, pool.Disks, machine.Disks)
+  }
+}
+
+/*
+      Tests for validateDisks
+*/
+func TestValidateDisks(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.Workers[0].Disks = []Disk{
+    {Name: "longhorn", Size: "200GB", MountPoint: "/var/lib/longhorn"},
+    {Name: "block", Size: "100GB", Raw: true},
+  }
+  appSettings.Clusters["prod"] = prod
+  assert.Empty(t, appSettings.Validate())
+
+  prod.Workers[0].Disks = []Disk{
+    {Name: "Longhorn", Size: "200", MountPoint: "var/lib/longhorn"},
+    {Name: "data", Size: "100GB"},
+    {Name: "data", Size: "100GB", MountPoint: "/data", Raw: true},
+    {Name: "root", Size: "100GB", MountPoint: "/var"},
+    {Name: "data2", Size: "100GB", MountPoint: "/data/"},
+    {Name: "data3", Size: "100GB", MountPoint: "/srv"},
+    {Name: "data4", Size: "100GB", MountPoint: "/srv"},
+  }
+  appSettings.Clusters["prod"] = prod
+
+  assert.Equal(t, []string{
+    "clusters.prod.workers[0].disks[0].name",
+    "clusters.prod.workers[0].disks[0].size",
+    "clusters.prod.workers[0].disks[0].mountPoint",
+    "clusters.prod.workers[0].disks[1].mountPoint",
+    "clusters.prod.workers[0].disks[2].name",
+    "clusters.prod.workers[0].disks[2].mountPoint",
+    "clusters.prod.workers[0].disks[3].mountPoint",
+    "clusters.prod.workers[0].disks[4].mountPoint",
+    "clusters.prod.workers[0].disks[6].mountPoint",
+  }, validationPaths(appSettings.Validate()))
+}
+
+/*
+      Tests for validateStorageDataPath
+*/
+func TestValidateStorageDataPath(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.ClusterFeatures.StorageController = "longhorn"
+  prod.ClusterFeatures.StorageDataPath = "/var/lib/longhorn"
+  prod.Workers[0].Disks = []Disk{{Name: "longhorn", Size: "200GB", MountPoint: "/var/lib/longhorn"}}
+  appSettings.Clusters["prod"] = prod
+  assert.Empty(t, appSettings.Validate())
+
+  // the workers need a disk at the data path
+  prod.Workers[0].Disks = []Disk{{Name: "longhorn", Size: "200GB", Raw: true}}
+  appSettings.Clusters["prod"] = prod
+  assert.Equal(t, []string{
+    "clusters.prod.clusterFeatures.storageDataPath",
+  }, validationPaths(appSettings.Validate()))
+
+  // single clusters need it on the leader
+  dev := appSettings.Clusters["dev"]
+  dev.ClusterFeatures = &ClusterFeatures{StorageController: "longhorn", StorageDataPath: "/var/lib/longhorn"}
+  dev.Leaders[0].Disks = []Disk{{Name: "longhorn", Size: "200GB", MountPoint: "/var/lib/longhorn"}}
+  appSettings.Clusters["dev"] = dev
+  prod.Workers[0].Disks = []Disk{{Name: "longhorn", Size: "200GB", MountPoint: "/var/lib/longhorn"}}
+  appSettings.Clusters["prod"] = prod
+  assert.Empty(t, appSettings.Validate())
+
+  // only longhorn uses a data path
+  prod.ClusterFeatures.StorageController = "local-storage"
+  appSettings.Clusters["prod"] = prod
+  assert.Equal(t, []string{
+    "clusters.prod.clusterFeatures.storageDataPath",
+  }, validationPaths(appSettings.Validate()))
+}
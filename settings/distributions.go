@@ -0,0 +1,146 @@
+package settings
+
+import (
+	"path"
+)
+
+/*
+  DistributionDefinition - Everything local-kube knows about a
+  kubernetes distribution, where it keeps its files on the machines,
+  the components it has built in and the defaults for the features
+*/
+type DistributionDefinition struct {
+  Name string                           `json:"name"`                // The distribution name used in the settings
+  Description string                    `json:"description"`         // A short description of the distribution
+  KubeConfigPath string                 `json:"kubeConfigPath"`      // The admin kubeconfig on the lead machine
+  KubeConfigName string                 `json:"kubeConfigName"`      // The cluster, user and context name in the admin kubeconfig
+  ConfigDir string                      `json:"configDir"`           // The config directory on the machines (registries.yaml is read from here)
+  ManifestsDir string                   `json:"manifestsDir"`        // The auto deploy manifests directory on the servers
+  KubectlCommand string                 `json:"kubectlCommand"`      // The command to run kubectl as admin on a server
+  CniControllers []string               `json:"cniControllers"`      // The cni controllers that work, the first is built in
+  StorageControllers []string           `json:"storageControllers"`  // The storage controllers that work
+  DisableComponents []string            `json:"disableComponents"`   // The packaged components that can be disabled
+  BuiltinComponents map[string]string   `json:"builtinComponents"`   // ingress or storage controller -> the packaged component it is
+  Offline bool                          `json:"offline"`             // Can be installed from the offline bundle
+  Defaults ClusterFeatures              `json:"defaults"`            // Defaults for blank features
+}
+
+const defaultDistribution = "k3s"
+
+// all the distributions that are supported
+var distributionRegistry = map[string]DistributionDefinition{
+  "k3s": {
+    Name: "k3s",
+    Description: "Lightweight kubernetes from rancher",
+    KubeConfigPath: "/etc/rancher/k3s/k3s.yaml",
+    KubeConfigName: "default",
+    ConfigDir: "/etc/rancher/k3s",
+    ManifestsDir: "/var/lib/rancher/k3s/server/manifests",
+    KubectlCommand: "sudo k3s kubectl",
+    CniControllers: []string{"flannel", "cilium", "calico"},
+    StorageControllers: []string{"local-storage", "longhorn"},
+    DisableComponents: []string{"coredns", "servicelb", "traefik", "local-storage", "metrics-server", "runtimes"},
+    BuiltinComponents: map[string]string{
+      "native-traefik": "traefik",
+      "local-storage": "local-storage",
+    },
+    Offline: true,
+    Defaults: ClusterFeatures{
+      CniController: "flannel",
+      IngressController: "native-traefik",
+      StorageController: "local-storage",
+    },
+  },
+  "rke2": {
+    Name: "rke2",
+    Description: "Security focused kubernetes from rancher (RKE2)",
+    KubeConfigPath: "/etc/rancher/rke2/rke2.yaml",
+    KubeConfigName: "default",
+    ConfigDir: "/etc/rancher/rke2",
+    ManifestsDir: "/var/lib/rancher/rke2/server/manifests",
+    KubectlCommand: "sudo /var/lib/rancher/rke2/bin/kubectl --kubeconfig /etc/rancher/rke2/rke2.yaml",
+    CniControllers: []string{"canal", "cilium", "calico"},
+    StorageControllers: []string{"longhorn"},
+    DisableComponents: []string{"rke2-coredns", "rke2-ingress-nginx", "rke2-metrics-server",
+      "rke2-snapshot-controller", "rke2-snapshot-controller-crd", "rke2-snapshot-validation-webhook"},
+    BuiltinComponents: map[string]string{
+      "native-nginx": "rke2-ingress-nginx",
+    },
+    Defaults: ClusterFeatures{
+      CniController: "canal",
+      IngressController: "native-nginx",
+    },
+  },
+}
+
+/*
+Gets the supported distributions sorted by name
+*/
+func GetDistributionNames() []string {
+  return sortedKeys(distributionRegistry)
+}
+
+/*
+Gets the definition for a distribution, a blank name is the
+default distribution. The bool is false if the distribution
+is not supported
+*/
+func GetDistributionDefinition(name string) (DistributionDefinition, bool) {
+  if name == "" {
+    name = defaultDistribution
+  }
+  definition, exists := distributionRegistry[name]
+  return definition, exists
+}
+
+/*
+GetDistribution()
+Gets the definition of the distribution the features use,
+the default distribution if it is blank or not supported
+*/
+func (features ClusterFeatures) GetDistribution() DistributionDefinition {
+  definition, exists := GetDistributionDefinition(features.Distribution)
+  if !exists {
+    definition, _ = GetDistributionDefinition(defaultDistribution)
+  }
+  return definition
+}
+
+/*
+GetDistribution()
+Gets the definition of the distribution the cluster uses
+*/
+func (cluster Cluster) GetDistribution() DistributionDefinition {
+  if cluster.ClusterFeatures == nil {
+    return ClusterFeatures{}.GetDistribution()
+  }
+  return cluster.ClusterFeatures.GetDistribution()
+}
+
+/*
+GetKubeConfigFile()
+Gets the file name of the admin kubeconfig (ex k3s.yaml), it is
+copied to the cluster kubeconfig directory with this name
+*/
+func (definition DistributionDefinition) GetKubeConfigFile() string {
+  return path.Base(definition.KubeConfigPath)
+}
+
+/*
+GetRelease()
+Gets the release of the distribution for a kube version
+*/
+func (definition DistributionDefinition) GetRelease(compatibility KubeVersionCompatibility) string {
+  if definition.Name == "rke2" {
+    return compatibility.Rke2Release
+  }
+  return compatibility.K3sRelease
+}
+
+/*
+GetBuiltinCniController()
+Gets the cni controller that is built in to the distribution
+*/
+func (definition DistributionDefinition) GetBuiltinCniController() string {
+  return definition.CniControllers[0]
+}
@@ -0,0 +1,112 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for GetDistributionDefinition
+*/
+func TestGetDistributionDefinition(t *testing.T) {
+  assert.Equal(t, []string{"k3s", "rke2"}, GetDistributionNames())
+
+  definition, exists := GetDistributionDefinition("")
+  assert.True(t, exists)
+  assert.Equal(t, "k3s", definition.Name)
+
+  definition, exists = GetDistributionDefinition("rke2")
+  assert.True(t, exists)
+  assert.Equal(t, "/etc/rancher/rke2/rke2.yaml", definition.KubeConfigPath)
+
+  _, exists = GetDistributionDefinition("k0s")
+  assert.False(t, exists)
+}
+
+func TestDistributionDefinitions(t *testing.T) {
+  // every distribution has a built in cni and defaults it supports
+  for _, name := range GetDistributionNames() {
+    definition, _ := GetDistributionDefinition(name)
+
+    assert.NotEmpty(t, definition.CniControllers, name)
+    assert.Equal(t, definition.GetBuiltinCniController(), definition.Defaults.CniController, name)
+    if definition.Defaults.StorageController != "" {
+      assert.Contains(t, definition.StorageControllers, definition.Defaults.StorageController, name)
+    }
+    for _, component := range definition.BuiltinComponents {
+      assert.Contains(t, definition.DisableComponents, component, name)
+    }
+  }
+}
+
+/*
+      Tests for GetDistribution
+*/
+func TestGetDistribution(t *testing.T) {
+  assert.Equal(t, "k3s", Cluster{}.GetDistribution().Name)
+  assert.Equal(t, "k3s", Cluster{ClusterFeatures: &ClusterFeatures{}}.GetDistribution().Name)
+  assert.Equal(t, "rke2", Cluster{ClusterFeatures: &ClusterFeatures{Distribution: "rke2"}}.GetDistribution().Name)
+
+  // unsupported distributions fall back to the default
+  assert.Equal(t, "k3s", ClusterFeatures{Distribution: "k0s"}.GetDistribution().Name)
+}
+
+/*
+      Tests for GetKubeConfigFile
+*/
+func TestGetKubeConfigFile(t *testing.T) {
+  k3s, _ := GetDistributionDefinition("k3s")
+  rke2, _ := GetDistributionDefinition("rke2")
+
+  assert.Equal(t, "k3s.yaml", k3s.GetKubeConfigFile())
+  assert.Equal(t, "rke2.yaml", rke2.GetKubeConfigFile())
+}
+
+/*
+      Tests for GetRelease
+*/
+func TestGetRelease(t *testing.T) {
+  catalog, err := GetVersionCatalog()
+  assert.NoError(t, err)
+
+  compatibility, known := catalog.GetKubeVersion("1.31.4")
+  assert.True(t, known)
+
+  k3s, _ := GetDistributionDefinition("k3s")
+  rke2, _ := GetDistributionDefinition("rke2")
+
+  assert.Equal(t, "v1.31.4+k3s1", k3s.GetRelease(compatibility))
+  assert.Equal(t, "v1.31.4+rke2r1", rke2.GetRelease(compatibility))
+}
+
+/*
+      Tests for validating the distribution
+*/
+func TestValidateDistribution(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.ClusterFeatures.Distribution = "rke2"
+  prod.ClusterFeatures.DisableComponents = []string{"rke2-metrics-server"}
+  appSettings.Clusters["prod"] = prod
+  assert.Empty(t, appSettings.Validate())
+
+  // components and controllers have to be from the distribution
+  prod.ClusterFeatures.CniController = "flannel"
+  prod.ClusterFeatures.StorageController = "local-storage"
+  prod.ClusterFeatures.DisableComponents = []string{"traefik", "rke2-ingress-nginx"}
+  appSettings.Clusters["prod"] = prod
+  assert.Equal(t, []string{
+    "clusters.prod.clusterFeatures.cniController",
+    "clusters.prod.clusterFeatures.storageController",
+    "clusters.prod.clusterFeatures.disableComponents[0]",
+    "clusters.prod.clusterFeatures.disableComponents",
+  }, validationPaths(appSettings.Validate()))
+
+  prod.ClusterFeatures = &ClusterFeatures{Distribution: "k0s", KubeVipEnable: true}
+  appSettings.Clusters["prod"] = prod
+  assert.Equal(t, []string{
+    "clusters.prod.clusterFeatures.distribution",
+  }, validationPaths(appSettings.Validate()))
+}
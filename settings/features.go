@@ -12,6 +12,9 @@ import (
   configuration
 */ 
 type ClusterFeatures struct {
+  // kubernetes distribution
+  Distribution string               `json:"distribution,omitempty"`               // (k3s or rke2) the kubernetes distribution, default is k3s
+
   // kube version
   KubeVersion string                `json:"kubeVersion,omitempty"`                // The kube version to use 
 
@@ -21,7 +24,7 @@ type ClusterFeatures struct {
   ManagedCniController bool         `json:"managedCniController,omitempty"`       // If Cni Controller should be installed
 
   //Cilium specific 
-  CiliumCliVersion string          `json:"cillumCliVersion,omitempty"`           // the cillum cli version
+  CiliumCliVersion string          `json:"ciliumCliVersion,omitempty"`           // the cilium cli version
 
   // Ingress Controller
   IngressController string          `json:"ingressController,omitempty"`          // The ingress controller
@@ -30,28 +33,30 @@ type ClusterFeatures struct {
   StorageController string          `json:"storageController,omitempty"`          // The storage controller
   StorageControllerVersion string   `json:"storageControllerVersion,omitempty"`   // The Version of the storage controller
   ManagedStorageController bool     `json:"managedStorageController,omitempty"`   // If the storage controller should be installed
+  StorageDataPath string            `json:"storageDataPath,omitempty"`            // Where the storage controller keeps data, a data disk mount point
 
   // kubeVip
   KubeVipEnable bool                `json:"kubeVipEnable,omitempty"`              // Enable KubeVip
   KubeVipVersion string             `json:"kubeVipVersion,omitempty"`             // KubeVip Version
 
+  // networking
+  BindNetwork string                `json:"bindNetwork,omitempty"`                // The machine network k3s binds to by name (if empty the primary network is used)
+
   // other settings
   DisableDefaultMetrics bool        `json:"disableDefaultMetrics,omitempty"`      // Disable default cluster metrics
-}
 
-var featuresDefaults = ClusterFeatures {
-  KubeVersion: "1.31.4",
-  CniController: "flannel",
-  IngressController: "native-traefik",
-  StorageController: "local-storage",
+  // extra k3s arguments for every machine, machines can override these
+  K3sArgs
 }
 
-var CiliumDefaultVersion = "1.16.4"
-var CiliumCliDefaultVersion = "0.16.22"
-var CalicoDefaultVersion = "3.25.0"
-var KubevipDefaultVersion = "0.5.0"
-var LonghornDefaultVersion = "1.8.0"
-
+/*
+SetDefaults()
+Sets defaults for any features that are not set, component
+versions are picked from the version catalog to match the kube
+version. Component versions known to not work with the kube
+version are an error, the cni, ingress and storage defaults come
+from the distribution
+*/
 func (features *ClusterFeatures) SetDefaults(clusterType string, vip string) error {
 
   // error checking
@@ -65,16 +70,56 @@ func (features *ClusterFeatures) SetDefaults(clusterType string, vip string) err
     return errors.New("kubevip enabled but no vip provided")
   }
 
+  if _, exists := GetDistributionDefinition(features.Distribution); !exists {
+    logger.LogError("Error distribution is not supported", "distribution", features.Distribution)
+    return errors.New("distribution is not supported")
+  }
+
+  // Distribution defaults
+  if features.Distribution == "" {
+    features.Distribution = defaultDistribution
+    logger.LogDebug("No distribution supplied, setting default", "distribution", defaultDistribution)
+  }
+  distribution := features.GetDistribution()
+  featuresDefaults := distribution.Defaults
+
+  catalog, err := GetVersionCatalog()
+  if err != nil {
+    return err
+  }
+
   // Kube Version defaults
   if features.KubeVersion == "" {
-    features.KubeVersion = featuresDefaults.KubeVersion
-    logger.LogDebug("No Kubenetes version supplied, setting default", "version", featuresDefaults.KubeVersion)
+    features.KubeVersion = catalog.DefaultKubeVersion
+    logger.LogDebug("No Kubenetes version supplied, setting default", "version", catalog.DefaultKubeVersion)
+  }
+
+  // component versions default to the first version that works with the kube version,
+  // if the kube version is unknown the versions for the same minor version are used
+  // and if the minor version is unknown too the versions for the default kube version
+  compatibility, known := catalog.GetKubeVersion(features.KubeVersion)
+  if !known {
+    compatibility, known = catalog.GetKubeMinorVersion(features.KubeVersion)
+    if known {
+      logger.LogWarn("Kube version is not in the version catalog, using the component defaults of the same minor version",
+        "kubeVersion", features.KubeVersion, "catalogVersion", compatibility.KubeVersion)
+    } else {
+      compatibility, _ = catalog.GetKubeVersion(catalog.DefaultKubeVersion)
+    }
+  }
+
+  defaultVersion := func(component string) string {
+    versions := compatibility.GetComponentVersions(component)
+    if len(versions) == 0 {
+      return ""
+    }
+    return versions[0]
   }
 
   //KubeVip defaults
   if features.KubeVipEnable && features.KubeVipVersion == "" {
-    logger.LogDebug("Kubevip enabled but no version supplied, using default", "version", KubevipDefaultVersion)
-    features.KubeVipVersion = KubevipDefaultVersion
+    logger.LogDebug("Kubevip enabled but no version supplied, using default", "version", defaultVersion("kubeVip"))
+    features.KubeVipVersion = defaultVersion("kubeVip")
   }
 
   // Cni Controller defaults
@@ -87,23 +132,26 @@ func (features *ClusterFeatures) SetDefaults(clusterType string, vip string) err
       logger.LogDebug("Cni controller supplied", "controller", features.CniController, "managed", features.ManagedCniController)
 
       if features.CniControllerVersion == "" {
-        logger.LogDebug("Cni Controller version not set, using default", "controller", features.CniController, "version", CiliumDefaultVersion)
-        features.CniControllerVersion = CiliumDefaultVersion
+        logger.LogDebug("Cni Controller version not set, using default", "controller", features.CniController, "version", defaultVersion("cilium"))
+        features.CniControllerVersion = defaultVersion("cilium")
       }     
 
       if features.CiliumCliVersion == "" {
-        logger.LogDebug("Cni is Cilium and cli version is not set, using default", "cliVersion", CiliumCliDefaultVersion)
-        features.CiliumCliVersion = CiliumCliDefaultVersion
+        logger.LogDebug("Cni is Cilium and cli version is not set, using default", "cliVersion", catalog.CiliumCliVersion)
+        features.CiliumCliVersion = catalog.CiliumCliVersion
       }
 
     } else if features.CniController == "calico" {
       logger.LogDebug("Cni controller supplied", "controller", features.CniController, "managed", features.ManagedCniController)
 
       if features.CniControllerVersion == "" {
-        logger.LogDebug("Cni Controller version not set, using default", "controller", features.CniController, "version", CalicoDefaultVersion)
-        features.CniControllerVersion = CalicoDefaultVersion
+        logger.LogDebug("Cni Controller version not set, using default", "controller", features.CniController, "version", defaultVersion("calico"))
+        features.CniControllerVersion = defaultVersion("calico")
       }
 
+    } else if features.CniController == distribution.GetBuiltinCniController() {
+      logger.LogDebug("Cni controller supplied", "controller", features.CniController)
+
     } else {
       logger.LogError("Error cni controller is not supported", "controller", features.CniController)
       return errors.New("cni controller is not supported")
@@ -125,8 +173,8 @@ func (features *ClusterFeatures) SetDefaults(clusterType string, vip string) err
 
     if features.StorageControllerVersion == "" && features.StorageController == "longhorn"{
       logger.LogDebug("No storage controller version supplied settings default")
-      features.StorageControllerVersion = LonghornDefaultVersion
+      features.StorageControllerVersion = defaultVersion("longhorn")
     }
   }
-  return nil
+  return catalog.CheckFeatureVersions(*features)
 }
@@ -53,6 +53,47 @@ func TestClusterFeaturesDefaultVersions(t *testing.T) {
   assert.Equal(t, features.KubeVipVersion, "0.5.0")
 }
 
+func TestClusterFeaturesDefaultVersionsMatchKubeVersion(t *testing.T) {
+  features := ClusterFeatures{
+    KubeVersion: "1.29.12",
+    CniController: "calico",
+    StorageController: "longhorn",
+    KubeVipEnable: true,
+  }
+
+  err := features.SetDefaults("single", "2.2.2.2")
+  assert.NoError(t, err)
+
+  // Verification
+  assert.Equal(t, "3.27.4", features.CniControllerVersion)
+  assert.Equal(t, "1.7.2", features.StorageControllerVersion)
+  assert.Equal(t, "0.8.7", features.KubeVipVersion)
+}
+
+func TestClusterFeaturesDefaultsUnknownKubeVersion(t *testing.T) {
+  features := ClusterFeatures{
+    KubeVersion: "1.20.0",
+    CniController: "cilium",
+  }
+
+  err := features.SetDefaults("single", "")
+  assert.NoError(t, err)
+
+  // versions for the default kube version are used
+  assert.Equal(t, "1.16.4", features.CniControllerVersion)
+}
+
+func TestClusterFeaturesDefaultsKnownBadVersion(t *testing.T) {
+  features := ClusterFeatures{
+    KubeVersion: "1.31.4",
+    CniController: "calico",
+    CniControllerVersion: "3.25.0",
+  }
+
+  err := features.SetDefaults("single", "")
+  assert.Error(t, err)
+}
+
 func TestClusterFeaturesDefaultsKubeVipError(t *testing.T) {
   features := ClusterFeatures{}
 
@@ -68,3 +109,26 @@ func TestClusterFeaturesDefaultsMissingVip(t *testing.T) {
   err := features.SetDefaults("single", "")
   assert.Error(t, err)
 }
+
+func TestClusterFeaturesDefaultsDistribution(t *testing.T) {
+  features := ClusterFeatures{Distribution: "rke2"}
+
+  err := features.SetDefaults("single", "")
+  assert.NoError(t, err)
+
+  assert.Equal(t, "canal", features.CniController)
+  assert.Equal(t, "native-nginx", features.IngressController)
+  assert.Empty(t, features.StorageController)
+
+  features = ClusterFeatures{}
+  err = features.SetDefaults("single", "")
+  assert.NoError(t, err)
+  assert.Equal(t, "k3s", features.Distribution)
+}
+
+func TestClusterFeaturesDefaultsDistributionError(t *testing.T) {
+  features := ClusterFeatures{Distribution: "k0s"}
+
+  err := features.SetDefaults("single", "")
+  assert.Error(t, err)
+}
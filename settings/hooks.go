@@ -0,0 +1,134 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+/*
+  Hooks - Steps run around the cluster operations, the
+  hooks of an event are run in order
+*/
+type Hooks struct {
+  PreUp []Hook            `json:"preUp,omitempty"`           // Before the machines are created, host commands only
+  PostUp []Hook           `json:"postUp,omitempty"`          // After the machines are up, before provisioning
+  PostProvision []Hook    `json:"postProvision,omitempty"`   // After provisioning and the kubeconfig update
+  PreDown []Hook          `json:"preDown,omitempty"`         // Before the machines are destroyed
+  PostDown []Hook         `json:"postDown,omitempty"`        // After the machines are destroyed, host commands only
+}
+
+/*
+  Hook - A command run on the host or a script run on the
+  machines over ssh, set one of command or script
+*/
+type Hook struct {
+  Name string               `json:"name,omitempty"`              // A name for the hook in the logs
+  Command string            `json:"command,omitempty"`           // A command run on the host from the cluster directory
+  Script string             `json:"script,omitempty"`            // A script file on the host run on the machines with bash
+  Nodes string              `json:"nodes,omitempty"`             // (lead, leaders, workers or all) the machines the script runs on, default is lead
+  ContinueOnError bool      `json:"continueOnError,omitempty"`   // A failing hook is a warning instead of stopping the operation
+}
+
+var supportedHookEvents = []string{"preUp", "postUp", "postProvision", "preDown", "postDown"}
+var supportedHookNodes = []string{"lead", "leaders", "workers", "all"}
+
+// the events that happen when there are no machines
+var hostOnlyHookEvents = []string{"preUp", "postDown"}
+
+/*
+GetHooks()
+Gets the hooks for an event (ex postUp)
+*/
+func (hooks Hooks) GetHooks(event string) []Hook {
+  switch event {
+  case "preUp":
+    return hooks.PreUp
+  case "postUp":
+    return hooks.PostUp
+  case "postProvision":
+    return hooks.PostProvision
+  case "preDown":
+    return hooks.PreDown
+  case "postDown":
+    return hooks.PostDown
+  default:
+    return nil
+  }
+}
+
+/*
+GetName()
+Gets the name of the hook for the logs, the command or
+script if the hook has no name
+*/
+func (hook Hook) GetName() string {
+  if hook.Name != "" {
+    return hook.Name
+  }
+  if hook.Script != "" {
+    return hook.Script
+  }
+  return hook.Command
+}
+
+/*
+GetHookNodeNames()
+Gets the vagrant machine names a hook script runs on
+*/
+func (cluster Cluster) GetHookNodeNames(hook Hook) []string {
+  if cluster.ClusterType == "single" {
+    if hook.Nodes == "workers" {
+      return []string{}
+    }
+    return []string{"default"}
+  }
+
+  switch hook.Nodes {
+  case "leaders":
+    return cluster.GetMachineNameList()[:len(cluster.Leaders)]
+  case "workers":
+    return cluster.GetWorkerNodeNames()
+  case "all":
+    return cluster.GetMachineNameList()
+  default:
+    return []string{cluster.GetAnsibleNodeVagrantName()}
+  }
+}
+
+// validates the hooks of a cluster, hook scripts have to
+// exist on this host
+func (validator *settingsValidator) validateHooks(path string, hooks *Hooks) {
+  if hooks == nil {
+    return
+  }
+
+  for _, event := range supportedHookEvents {
+    for index, hook := range hooks.GetHooks(event) {
+      hookPath := fmt.Sprintf("%s.%s[%d]", path, event, index)
+
+      if (hook.Command == "") == (hook.Script == "") {
+        validator.add(hookPath, "a hook needs either a command or a script")
+      }
+
+      if hook.Script != "" {
+        if contains(hostOnlyHookEvents, event) {
+          validator.add(hookPath + ".script", "%s hooks can only run host commands, there are no machines", event)
+        } else if info, err := os.Stat(hook.Script); err != nil {
+          validator.add(hookPath + ".script", "script %s does not exist", hook.Script)
+        } else if info.IsDir() {
+          validator.add(hookPath + ".script", "script %s is a directory", hook.Script)
+        }
+      }
+
+      if hook.Nodes != "" {
+        if !contains(supportedHookNodes, hook.Nodes) {
+          validator.add(hookPath + ".nodes", "unsupported hook nodes %q, must be one of %s",
+            hook.Nodes, strings.Join(supportedHookNodes, ", "))
+        } else if hook.Script == "" {
+          validator.add(hookPath + ".nodes", "nodes are only used with hook scripts")
+        }
+      }
+    }
+  }
+}
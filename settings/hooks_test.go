@@ -0,0 +1,63 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for GetHookNodeNames
+*/
+func TestGetHookNodeNames(t *testing.T) {
+  appSettings := getValidSettings()
+  dev := appSettings.Clusters["dev"]
+  prod := appSettings.Clusters["prod"]
+
+  assert.Equal(t, []string{"default"}, dev.GetHookNodeNames(Hook{Script: "hook.sh"}))
+  assert.Equal(t, []string{"default"}, dev.GetHookNodeNames(Hook{Script: "hook.sh", Nodes: "all"}))
+  assert.Empty(t, dev.GetHookNodeNames(Hook{Script: "hook.sh", Nodes: "workers"}))
+
+  assert.Equal(t, []string{"prod-cp01"}, prod.GetHookNodeNames(Hook{Script: "hook.sh"}))
+  assert.Equal(t, []string{"prod-cp01", "prod-cp02"}, prod.GetHookNodeNames(Hook{Script: "hook.sh", Nodes: "leaders"}))
+  assert.Equal(t, []string{"prod-w01"}, prod.GetHookNodeNames(Hook{Script: "hook.sh", Nodes: "workers"}))
+  assert.Equal(t, []string{"prod-cp01", "prod-cp02", "prod-w01"}, prod.GetHookNodeNames(Hook{Script: "hook.sh", Nodes: "all"}))
+}
+
+/*
+      Tests for validateHooks
+*/
+func TestValidateHooks(t *testing.T) {
+  script := filepath.Join(t.TempDir(), "hook.sh")
+  assert.NoError(t, os.WriteFile(script, []byte("echo hook\n"), 0644))
+
+  appSettings := getValidSettings()
+  dev := appSettings.Clusters["dev"]
+  dev.Hooks = &Hooks{
+    PreUp: []Hook{{Command: "echo pre up"}},
+    PostProvision: []Hook{{Name: "smoke test", Script: script, Nodes: "all", ContinueOnError: true}},
+    PostDown: []Hook{{Command: "echo post down"}},
+  }
+  appSettings.Clusters["dev"] = dev
+  assert.Empty(t, appSettings.Validate())
+
+  dev.Hooks = &Hooks{
+    PreUp: []Hook{{Script: script}},
+    PostUp: []Hook{{}, {Command: "echo", Script: script}},
+    PostProvision: []Hook{{Script: filepath.Join(filepath.Dir(script), "missing.sh")}, {Script: filepath.Dir(script)}},
+    PreDown: []Hook{{Script: script, Nodes: "masters"}, {Command: "echo", Nodes: "all"}},
+  }
+  appSettings.Clusters["dev"] = dev
+
+  assert.Equal(t, []string{
+    "clusters.dev.hooks.preUp[0].script",
+    "clusters.dev.hooks.postUp[0]",
+    "clusters.dev.hooks.postUp[1]",
+    "clusters.dev.hooks.postProvision[0].script",
+    "clusters.dev.hooks.postProvision[1].script",
+    "clusters.dev.hooks.preDown[0].nodes",
+    "clusters.dev.hooks.preDown[1].nodes",
+  }, validationPaths(appSettings.Validate()))
+}
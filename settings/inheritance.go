@@ -0,0 +1,239 @@
+package settings
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+)
+
+/*
+  Built in cluster presets that clusters can extend, these
+  only set sizing and features so machine names and ips still
+  have to be set by the cluster extending them
+*/
+//go:embed presets/*.json
+var presetsFS embed.FS
+
+/*
+Gets the names of all the built in presets
+*/
+func GetPresetNames() ([]string, error) {
+  names := []string{}
+
+  presetFiles, err := fs.Glob(presetsFS, "presets/*.json")
+  if err != nil {
+    logger.LogError("Error listing presets")
+    return nil, err
+  }
+
+  for _, presetFile := range presetFiles {
+    names = append(names, strings.TrimSuffix(strings.TrimPrefix(presetFile, "presets/"), ".json"))
+  }
+  return names, nil
+}
+
+/*
+Gets a built in preset by name, the bool is false
+if there is no preset with that name
+*/
+func GetPreset(presetName string) (Cluster, bool, error) {
+  content, err := presetsFS.ReadFile("presets/" + presetName + ".json")
+  if err != nil {
+    logger.LogDebug("No preset found", "preset", presetName)
+    return Cluster{}, false, nil
+  }
+
+  var preset Cluster
+  err = json.Unmarshal(content, &preset)
+  if err != nil {
+    logger.LogError("Error unmarshaling preset", "preset", presetName)
+    return Cluster{}, false, err
+  }
+  return preset, true, nil
+}
+
+/*
+ResolveCluster()
+Gets the effective settings for a cluster, following extends
+through other clusters and the built in presets. Clusters in the
+settings take precedence over presets with the same name. Worker
+pools are expanded into workers
+*/
+func (settings *Settings) ResolveCluster(clusterName string) (Cluster, error) {
+  cluster, exists := settings.Clusters[clusterName]
+  if !exists {
+    logger.LogError("Error cluster is not present in settings", "cluster", clusterName)
+    return Cluster{}, fmt.Errorf("cluster %s is not present in settings", clusterName)
+  }
+
+  chain := []string{clusterName}
+  resolved, err := settings.resolveExtends(cluster, chain)
+  if err != nil {
+    return Cluster{}, err
+  }
+
+  resolved, err = resolved.ExpandWorkerPools()
+  if err != nil {
+    return Cluster{}, fmt.Errorf("%s worker pools: %w", clusterName, err)
+  }
+  return resolved, nil
+}
+
+// merges a cluster on top of what it extends, chain is used to find cycles
+func (settings *Settings) resolveExtends(cluster Cluster, chain []string) (Cluster, error) {
+  if cluster.Extends == "" {
+    return cluster, nil
+  }
+
+  for _, name := range chain {
+    if name == cluster.Extends {
+      logger.LogError("Error cluster extends cycle found", "chain", chain)
+      return Cluster{}, fmt.Errorf("extends cycle: %s -> %s", strings.Join(chain, " -> "), cluster.Extends)
+    }
+  }
+
+  base, exists := settings.Clusters[cluster.Extends]
+  if !exists {
+    preset, presetExists, err := GetPreset(cluster.Extends)
+    if err != nil {
+      return Cluster{}, err
+    }
+
+    if !presetExists {
+      logger.LogError("Error cluster extends an unknown cluster or preset", "extends", cluster.Extends)
+      return Cluster{}, fmt.Errorf("%s extends %q which is not a cluster or preset",
+        chain[len(chain)-1], cluster.Extends)
+    }
+    base = preset
+  }
+
+  logger.LogDebug("Resolving cluster extends", "cluster", chain[len(chain)-1], "extends", cluster.Extends)
+  resolvedBase, err := settings.resolveExtends(base, append(chain, cluster.Extends))
+  if err != nil {
+    return Cluster{}, err
+  }
+
+  resolved := mergeClusters(resolvedBase, cluster)
+  resolved.Extends = ""
+  return resolved, nil
+}
+
+/*
+deep merges an override cluster on top of a base cluster. Values
+set in the override win, bools are merged the same way so a bool
+can only be turned on, an override can not turn off a feature
+(ex kubeVipEnable) that the base turns on. Machines are merged by
+position but only the sizing (memory, cpus and diskSize) is
+inherited, names, ips, networks and everything else on a machine
+come from the override. An override with more machines than the
+base gets the sizing of the last base machine, an empty machine
+list keeps just the sizing of the base machines. Addons are
+merged by name, any other list (ex hooks and syncedFolders) set
+in the override replaces the list of the base
+*/
+func mergeClusters(base Cluster, override Cluster) Cluster {
+  merged := mergeValues(reflect.ValueOf(base), reflect.ValueOf(override)).Interface().(Cluster)
+  merged.Leaders = mergeMachines(base.Leaders, override.Leaders)
+  merged.Workers = mergeMachines(base.Workers, override.Workers)
+  merged.Addons = mergeAddons(base.Addons, override.Addons)
+  return merged
+}
+
+// merges override machines on top of base machines by position,
+// only the sizing is taken from the base machines so clusters
+// never share machine names or ips
+func mergeMachines(base []Machine, override []Machine) []Machine {
+  if len(override) == 0 {
+    override = make([]Machine, len(base))
+  }
+
+  merged := make([]Machine, len(override))
+  for index, machine := range override {
+    if len(base) > 0 {
+      baseMachine := base[min(index, len(base) - 1)]
+
+      if machine.Memory == 0 {
+        machine.Memory = baseMachine.Memory
+      }
+      if machine.Cpu == 0 {
+        machine.Cpu = baseMachine.Cpu
+      }
+      if machine.DiskSize == "" {
+        machine.DiskSize = baseMachine.DiskSize
+      }
+    }
+    merged[index] = machine
+  }
+  return merged
+}
+
+// merges two values of the same type, see mergeClusters for the rules
+func mergeValues(base reflect.Value, override reflect.Value) reflect.Value {
+  switch base.Kind() {
+  case reflect.Struct:
+    merged := reflect.New(base.Type()).Elem()
+    for index := 0; index < base.NumField(); index++ {
+      if !merged.Field(index).CanSet() {
+        continue
+      }
+      merged.Field(index).Set(mergeValues(base.Field(index), override.Field(index)))
+    }
+    return merged
+
+  case reflect.Ptr:
+    if base.IsNil() && override.IsNil() {
+      return base
+    }
+
+    // always a new pointer so resolved clusters don't share
+    // values with the clusters they extend
+    merged := reflect.New(base.Type().Elem())
+    if base.IsNil() {
+      merged.Elem().Set(override.Elem())
+    } else if override.IsNil() {
+      merged.Elem().Set(base.Elem())
+    } else {
+      merged.Elem().Set(mergeValues(base.Elem(), override.Elem()))
+    }
+    return merged
+
+  case reflect.Slice:
+    // lists are replaced, merging items by position would give
+    // extra override items the fields of the last base item
+    if override.Len() == 0 {
+      return base
+    }
+    return override
+
+  case reflect.Map:
+    if override.Len() == 0 {
+      return base
+    }
+    if base.Len() == 0 {
+      return override
+    }
+    merged := reflect.MakeMap(base.Type())
+    for _, key := range base.MapKeys() {
+      merged.SetMapIndex(key, base.MapIndex(key))
+    }
+    for _, key := range override.MapKeys() {
+      value := override.MapIndex(key)
+      if baseValue := base.MapIndex(key); baseValue.IsValid() {
+        value = mergeValues(baseValue, value)
+      }
+      merged.SetMapIndex(key, value)
+    }
+    return merged
+
+  default:
+    if override.IsZero() {
+      return base
+    }
+    return override
+  }
+}
@@ -0,0 +1,196 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for presets
+*/
+func TestPresetsAreValid(t *testing.T) {
+  presetNames, err := GetPresetNames()
+  assert.NoError(t, err)
+  assert.Contains(t, presetNames, "single-small")
+  assert.Contains(t, presetNames, "ha-3x2-cilium")
+
+  for _, presetName := range presetNames {
+    _, exists, err := GetPreset(presetName)
+    assert.NoError(t, err, presetName)
+    assert.True(t, exists, presetName)
+  }
+
+  _, exists, err := GetPreset("does-not-exist")
+  assert.NoError(t, err)
+  assert.False(t, exists)
+}
+
+/*
+      Tests for ResolveCluster
+*/
+func TestResolveClusterPreset(t *testing.T) {
+  settings := getValidSettings()
+  settings.Clusters["dev"] = Cluster{
+    Extends: "single-small",
+    ProviderName: "vmware",
+    Leaders: []Machine{
+      {Name: "dev", IpAddress: "192.168.1.10"},
+    },
+  }
+
+  resolved, err := settings.ResolveCluster("dev")
+  assert.NoError(t, err)
+
+  assert.Equal(t, "", resolved.Extends)
+  assert.Equal(t, "single", resolved.ClusterType)
+  assert.Equal(t, Machine{Name: "dev", IpAddress: "192.168.1.10", Memory: 2048, Cpu: 2, DiskSize: "30GB"}, resolved.Leaders[0])
+  assert.Empty(t, settings.Validate())
+}
+
+func TestResolveClusterPresetChain(t *testing.T) {
+  settings := getValidSettings()
+  settings.Clusters["prod"] = Cluster{
+    Extends: "ha-3x2-cilium",
+    ProviderName: "vmware",
+    Vip: "192.168.1.20",
+    Leaders: []Machine{
+      {Name: "prod-cp01", IpAddress: "192.168.1.21"},
+      {Name: "prod-cp02", IpAddress: "192.168.1.22"},
+      {Name: "prod-cp03", IpAddress: "192.168.1.23"},
+    },
+    Workers: []Machine{
+      {Name: "prod-w01", IpAddress: "192.168.1.24", Memory: 16384},
+    },
+    ClusterFeatures: &ClusterFeatures{KubeVersion: "1.30.0"},
+  }
+
+  resolved, err := settings.ResolveCluster("prod")
+  assert.NoError(t, err)
+
+  assert.Equal(t, "ha", resolved.ClusterType)
+  assert.Len(t, resolved.Leaders, 3)
+  assert.Equal(t, 4096, resolved.Leaders[2].Memory)
+  assert.Len(t, resolved.Workers, 1)
+  assert.Equal(t, 16384, resolved.Workers[0].Memory)
+  assert.Equal(t, "50GB", resolved.Workers[0].DiskSize)
+  assert.Equal(t, "cilium", resolved.ClusterFeatures.CniController)
+  assert.Equal(t, "1.30.0", resolved.ClusterFeatures.KubeVersion)
+  assert.True(t, resolved.ClusterFeatures.KubeVipEnable)
+}
+
+func TestResolveClusterExtendsCluster(t *testing.T) {
+  settings := getValidSettings()
+  settings.Clusters["prod-big"] = Cluster{
+    Extends: "prod",
+    Vip: "192.168.1.30",
+    Leaders: []Machine{
+      {Name: "big-cp01", IpAddress: "192.168.1.31", Memory: 8192},
+      {Name: "big-cp02", IpAddress: "192.168.1.32"},
+    },
+    Workers: []Machine{
+      {Name: "big-w01", IpAddress: "192.168.1.33"},
+      {Name: "big-w02", IpAddress: "192.168.1.34"},
+    },
+  }
+
+  resolved, err := settings.ResolveCluster("prod-big")
+  assert.NoError(t, err)
+
+  assert.Equal(t, "vmware", resolved.ProviderName)
+  assert.Equal(t, 8192, resolved.Leaders[0].Memory)
+  assert.Equal(t, 4096, resolved.Leaders[1].Memory)
+  assert.Len(t, resolved.Workers, 2)
+  assert.Equal(t, "50GB", resolved.Workers[1].DiskSize)
+
+  // the cluster extended from is not changed
+  resolved.ClusterFeatures.KubeVersion = "changed"
+  assert.Equal(t, "", settings.Clusters["prod"].ClusterFeatures.KubeVersion)
+}
+
+func TestResolveClusterExtendsClusterBlankIps(t *testing.T) {
+  settings := getValidSettings()
+  prod := settings.Clusters["prod"]
+  prod.Leaders[0].Networks = []Network{{Name: "storage", IpAddress: "10.10.0.21"}}
+  prod.Leaders[0].ForwardedPorts = []ForwardedPort{{Guest: 80, Host: 8080}}
+  settings.Clusters["prod"] = prod
+
+  // names and ips are left blank for the ip pool
+  settings.Clusters["prod-pool"] = Cluster{
+    Extends: "prod",
+    Leaders: []Machine{{}, {}, {Memory: 8192}},
+  }
+
+  resolved, err := settings.ResolveCluster("prod-pool")
+  assert.NoError(t, err)
+
+  assert.Len(t, resolved.Leaders, 3)
+  for _, leader := range resolved.Leaders {
+    assert.Equal(t, "", leader.Name)
+    assert.Equal(t, "", leader.IpAddress)
+    assert.Empty(t, leader.Networks)
+    assert.Empty(t, leader.ForwardedPorts)
+  }
+  assert.Equal(t, prod.Leaders[0].Memory, resolved.Leaders[0].Memory)
+  assert.Equal(t, prod.Leaders[1].DiskSize, resolved.Leaders[2].DiskSize)
+  assert.Equal(t, 8192, resolved.Leaders[2].Memory)
+
+  // an empty machine list only keeps the sizing of the base
+  assert.Len(t, resolved.Workers, len(prod.Workers))
+  assert.Equal(t, Machine{Memory: prod.Workers[0].Memory, Cpu: prod.Workers[0].Cpu,
+    DiskSize: prod.Workers[0].DiskSize}, resolved.Workers[0])
+}
+
+func TestResolveClusterExtendsClusterLists(t *testing.T) {
+  settings := getValidSettings()
+  prod := settings.Clusters["prod"]
+  prod.Hooks = &Hooks{
+    PostUp: []Hook{{Name: "base", Command: "echo base"}},
+    PreDown: []Hook{{Command: "echo down"}},
+  }
+  prod.SyncedFolders = []SyncedFolder{{HostPath: "/src/base", GuestPath: "/src/base", Type: "nfs", NfsVersion: 3}}
+  settings.Clusters["prod"] = prod
+
+  settings.Clusters["prod-child"] = Cluster{
+    Extends: "prod",
+    Hooks: &Hooks{
+      PostUp: []Hook{{Name: "child", Script: "hooks/child.sh"}},
+    },
+    SyncedFolders: []SyncedFolder{{HostPath: "/src/child", GuestPath: "/src/child"}},
+  }
+
+  resolved, err := settings.ResolveCluster("prod-child")
+  assert.NoError(t, err)
+
+  // lists set in the child replace the lists of the base
+  assert.Equal(t, []Hook{{Name: "child", Script: "hooks/child.sh"}}, resolved.Hooks.PostUp)
+  assert.Equal(t, prod.Hooks.PreDown, resolved.Hooks.PreDown)
+  assert.Equal(t, []SyncedFolder{{HostPath: "/src/child", GuestPath: "/src/child"}}, resolved.SyncedFolders)
+
+  // lists the child does not set are inherited
+  settings.Clusters["prod-child"] = Cluster{Extends: "prod"}
+  resolved, err = settings.ResolveCluster("prod-child")
+  assert.NoError(t, err)
+  assert.Equal(t, prod.SyncedFolders, resolved.SyncedFolders)
+  assert.Equal(t, prod.Hooks.PostUp, resolved.Hooks.PostUp)
+}
+
+func TestResolveClusterErrors(t *testing.T) {
+  settings := getValidSettings()
+  settings.Clusters["a"] = Cluster{Extends: "b"}
+  settings.Clusters["b"] = Cluster{Extends: "a"}
+  settings.Clusters["c"] = Cluster{Extends: "nothing"}
+
+  _, err := settings.ResolveCluster("a")
+  assert.ErrorContains(t, err, "cycle")
+
+  _, err = settings.ResolveCluster("c")
+  assert.ErrorContains(t, err, "nothing")
+
+  _, err = settings.ResolveCluster("missing")
+  assert.Error(t, err)
+
+  paths := validationPaths(settings.Validate())
+  assert.Contains(t, paths, "clusters.a.extends")
+  assert.Contains(t, paths, "clusters.c.extends")
+}
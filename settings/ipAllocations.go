@@ -0,0 +1,399 @@
+package settings
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+)
+
+/*
+  The key used for the vip in a cluster's
+  ip allocations
+*/
+const vipAllocationKey = "vip"
+
+/*
+  IpAllocations - The ips that have been given out from provider
+  ip pools, this is cluster name -> machine name (or vip) -> ip.
+  Allocations are kept after a cluster is destroyed so a rebuilt
+  cluster gets the same ips
+*/
+type IpAllocations map[string]map[string]string
+
+/*
+Gets the path to the ip allocations file
+*/
+func GetIpAllocationsPath(appDir string) string {
+  return filepath.Join(appDir, "ip-allocations.json")
+}
+
+/*
+ReadIpAllocations()
+Reads the ip allocations file, if the file does not
+exist there are no allocations yet
+*/
+func ReadIpAllocations(appDir string) (IpAllocations, error) {
+  allocations := make(IpAllocations)
+
+  bytes, err := os.ReadFile(GetIpAllocationsPath(appDir))
+  if errors.Is(err, os.ErrNotExist) {
+    logger.LogDebug("No ip allocations file, no ips allocated yet")
+    return allocations, nil
+  } else if err != nil {
+    logger.LogError("Error reading ip allocations file")
+    return nil, err
+  }
+
+  err = json.Unmarshal(bytes, &allocations)
+  if err != nil {
+    logger.LogError("Error unmarshaling ip allocations")
+    return nil, err
+  }
+  return allocations, nil
+}
+
+/*
+WriteIpAllocations()
+Writes the ip allocations file
+*/
+func WriteIpAllocations(appDir string, allocations IpAllocations) error {
+  bytes, err := json.MarshalIndent(allocations, "", " ")
+  if err != nil {
+    logger.LogError("Error encoding ip allocations")
+    return err
+  }
+
+  err = os.WriteFile(GetIpAllocationsPath(appDir), append(bytes, '\n'), 0644)
+  if err != nil {
+    logger.LogError("Error writing ip allocations file")
+    return err
+  }
+  return nil
+}
+
+/*
+AllocateClusterIps()
+Fills in any blank machine ips (and the vip if kubevip is enabled)
+for a cluster from its provider's ip pool. Ips already allocated to
+the cluster are reused so they are stable across rebuilds, ips used
+by any other cluster are never given out. The allocations are saved
+to the ip allocations file and the cluster in the settings is updated,
+this should be run on resolved cluster settings
+*/
+func (settings *Settings) AllocateClusterIps(appDir string, clusterName string) error {
+  cluster, exists := settings.Clusters[clusterName]
+  if !exists {
+    return fmt.Errorf("cluster %s is not present in settings", clusterName)
+  }
+
+  if !clusterNeedsIps(cluster) {
+    logger.LogDebug("All ips are set for cluster, nothing to allocate", "cluster", clusterName)
+    return nil
+  }
+
+  ipPool := settings.Providers[cluster.ProviderName].IpPool
+  if ipPool == "" {
+    logger.LogError("Error cluster has blank ips but the provider has no ip pool", "provider", cluster.ProviderName)
+    return fmt.Errorf("cluster %s has machines without ips and provider %q has no ipPool",
+      clusterName, cluster.ProviderName)
+  }
+
+  pool, err := parseIpPool(ipPool)
+  if err != nil {
+    return err
+  }
+
+  allocations, err := ReadIpAllocations(appDir)
+  if err != nil {
+    return err
+  }
+
+  // forget allocations for clusters that have been removed from settings
+  for allocatedCluster := range allocations {
+    if _, exists := settings.Clusters[allocatedCluster]; !exists {
+      logger.LogDebug("Removing ip allocations for cluster no longer in settings", "cluster", allocatedCluster)
+      delete(allocations, allocatedCluster)
+    }
+  }
+
+  usedIps := settings.getOtherClusterIps(clusterName, allocations)
+  for _, machine := range append(append([]Machine{}, cluster.Leaders...), cluster.Workers...) {
+    if machine.IpAddress != "" {
+      usedIps[machine.IpAddress] = fmt.Sprintf("%s/%s", clusterName, machine.Name)
+    }
+  }
+  if cluster.Vip != "" {
+    usedIps[cluster.Vip] = fmt.Sprintf("%s/%s", clusterName, vipAllocationKey)
+  }
+
+  previous := allocations[clusterName]
+  clusterAllocations := make(map[string]string)
+
+  allocate := func(key string) (string, error) {
+    if ip, exists := previous[key]; exists && pool.contains(ip) {
+      if _, used := usedIps[ip]; !used {
+        logger.LogDebug("Reusing ip allocation", "cluster", clusterName, "name", key, "ip", ip)
+        return ip, nil
+      }
+    }
+
+    for address := pool.first; address <= pool.last; address++ {
+      ip := pool.ip(address)
+      if _, used := usedIps[ip]; used {
+        continue
+      }
+
+      // don't take an ip that was allocated to another name in this cluster
+      // before, so rebuilds keep ips stable
+      if previouslyAllocatedTo(previous, ip, key) {
+        continue
+      }
+      logger.LogDebug("Allocating new ip", "cluster", clusterName, "name", key, "ip", ip)
+      return ip, nil
+    }
+    logger.LogError("Error no free ips left in the pool", "pool", ipPool)
+    return "", fmt.Errorf("no free ips left in pool %s for %s/%s", ipPool, clusterName, key)
+  }
+
+  // copy the machines so nothing shared with other clusters is changed
+  cluster.Leaders = append([]Machine{}, cluster.Leaders...)
+  cluster.Workers = append([]Machine{}, cluster.Workers...)
+
+  for _, machines := range [][]Machine{cluster.Leaders, cluster.Workers} {
+    for index := range machines {
+      if machines[index].IpAddress != "" {
+        continue
+      }
+
+      ip, err := allocate(machines[index].Name)
+      if err != nil {
+        return err
+      }
+      machines[index].IpAddress = ip
+      usedIps[ip] = fmt.Sprintf("%s/%s", clusterName, machines[index].Name)
+      clusterAllocations[machines[index].Name] = ip
+    }
+  }
+
+  if cluster.Vip == "" && cluster.ClusterFeatures != nil && cluster.ClusterFeatures.KubeVipEnable {
+    ip, err := allocate(vipAllocationKey)
+    if err != nil {
+      return err
+    }
+    cluster.Vip = ip
+    clusterAllocations[vipAllocationKey] = ip
+  }
+
+  allocations[clusterName] = clusterAllocations
+  settings.Clusters[clusterName] = cluster
+
+  logger.LogInfo("Ips allocated for cluster", "cluster", clusterName, "allocations", clusterAllocations)
+  return WriteIpAllocations(appDir, allocations)
+}
+
+/*
+ApplyIpAllocations()
+Fills in the blank machine ips and vip of a cluster from the ips
+already allocated to it, nothing new is allocated. This is for
+commands that work on a deployed cluster (ex cluster-down) and
+should be run on resolved cluster settings
+*/
+func (settings *Settings) ApplyIpAllocations(appDir string, clusterName string) error {
+  cluster, exists := settings.Clusters[clusterName]
+  if !exists {
+    return fmt.Errorf("cluster %s is not present in settings", clusterName)
+  }
+
+  if !clusterNeedsIps(cluster) {
+    return nil
+  }
+
+  allocations, err := ReadIpAllocations(appDir)
+  if err != nil {
+    return err
+  }
+  clusterAllocations := allocations[clusterName]
+
+  // copy the machines so nothing shared with other clusters is changed
+  cluster.Leaders = append([]Machine{}, cluster.Leaders...)
+  cluster.Workers = append([]Machine{}, cluster.Workers...)
+
+  for _, machines := range [][]Machine{cluster.Leaders, cluster.Workers} {
+    for index := range machines {
+      if machines[index].IpAddress == "" {
+        machines[index].IpAddress = clusterAllocations[machines[index].Name]
+      }
+    }
+  }
+
+  if cluster.Vip == "" {
+    cluster.Vip = clusterAllocations[vipAllocationKey]
+  }
+
+  settings.Clusters[clusterName] = cluster
+  return nil
+}
+
+/*
+CheckClusterIpOverlap()
+Checks that none of the ips of a cluster are used by any other
+cluster defined in the settings (or allocated to it)
+*/
+func (settings *Settings) CheckClusterIpOverlap(appDir string, clusterName string) error {
+  var overlaps []string
+
+  allocations, err := ReadIpAllocations(appDir)
+  if err != nil {
+    return err
+  }
+
+  cluster := settings.Clusters[clusterName]
+  usedIps := settings.getOtherClusterIps(clusterName, allocations)
+
+  clusterIps := getClusterIps(cluster)
+  for _, name := range sortedKeys(clusterIps) {
+    ip := clusterIps[name]
+    if otherName, used := usedIps[ip]; used {
+      logger.LogError("Error ip is used by another cluster", "ip", ip, "machine", name, "usedBy", otherName)
+      overlaps = append(overlaps, fmt.Sprintf("%s (%s) is used by %s", ip, name, otherName))
+    }
+  }
+
+  if len(overlaps) > 0 {
+    return fmt.Errorf("cluster %s has ips that overlap other clusters: %s",
+      clusterName, strings.Join(overlaps, ", "))
+  }
+  return nil
+}
+
+/*
+gets all the ips used by clusters other than the given cluster,
+the ip is the key and the value is cluster/machine. Clusters that
+can't be resolved use their raw settings
+*/
+func (settings *Settings) getOtherClusterIps(clusterName string, allocations IpAllocations) map[string]string {
+  usedIps := make(map[string]string)
+
+  for _, otherName := range sortedKeys(settings.Clusters) {
+    if otherName == clusterName {
+      continue
+    }
+
+    otherCluster, err := settings.ResolveCluster(otherName)
+    if err != nil {
+      otherCluster = settings.Clusters[otherName]
+    }
+
+    for name, ip := range getClusterIps(otherCluster) {
+      usedIps[ip] = fmt.Sprintf("%s/%s", otherName, name)
+    }
+  }
+
+  for _, otherName := range sortedKeys(allocations) {
+    if otherName == clusterName {
+      continue
+    }
+
+    for name, ip := range allocations[otherName] {
+      usedIps[ip] = fmt.Sprintf("%s/%s", otherName, name)
+    }
+  }
+  return usedIps
+}
+
+// gets the ips that are set in a cluster, machine name (or vip) -> ip
+func getClusterIps(cluster Cluster) map[string]string {
+  ips := make(map[string]string)
+
+  for _, machine := range append(append([]Machine{}, cluster.Leaders...), cluster.Workers...) {
+    if machine.IpAddress != "" {
+      ips[machine.Name] = machine.IpAddress
+    }
+  }
+
+  if cluster.Vip != "" {
+    ips[vipAllocationKey] = cluster.Vip
+  }
+  return ips
+}
+
+// checks if a cluster has any ips that need to be allocated
+func clusterNeedsIps(cluster Cluster) bool {
+  for _, machine := range append(append([]Machine{}, cluster.Leaders...), cluster.Workers...) {
+    if machine.IpAddress == "" {
+      return true
+    }
+  }
+  return cluster.Vip == "" && cluster.ClusterFeatures != nil && cluster.ClusterFeatures.KubeVipEnable
+}
+
+// checks if an ip was allocated to a different name before
+func previouslyAllocatedTo(previous map[string]string, ip string, key string) bool {
+  for name, previousIp := range previous {
+    if previousIp == ip && name != key {
+      return true
+    }
+  }
+  return false
+}
+
+/*
+  ipPoolRange - The addresses that can be given out from an ipv4
+  pool, the network and broadcast addresses are left out along with
+  the first address in the pool since that is normally the host or
+  gateway. Addresses are worked out as needed instead of listed
+*/
+type ipPoolRange struct {
+  first uint32      // the first address that can be given out
+  last uint32       // the last address that can be given out
+}
+
+// pools larger than a /16 are rejected, allocating can walk
+// every address in the pool
+const maxIpPoolPrefixSize = 16
+
+// parses an ipv4 cidr into the range of addresses it gives out
+func parseIpPool(ipPool string) (ipPoolRange, error) {
+  _, network, err := net.ParseCIDR(ipPool)
+  if err != nil || network.IP.To4() == nil {
+    logger.LogError("Error ip pool is not a valid ipv4 cidr", "pool", ipPool)
+    return ipPoolRange{}, fmt.Errorf("ip pool %q is not a valid ipv4 cidr", ipPool)
+  }
+
+  ones, bits := network.Mask.Size()
+  if bits - ones < 2 {
+    return ipPoolRange{}, fmt.Errorf("ip pool %q is too small", ipPool)
+  }
+  if ones < maxIpPoolPrefixSize {
+    return ipPoolRange{}, fmt.Errorf("ip pool %q is too large, the largest pool is a /%d", ipPool, maxIpPoolPrefixSize)
+  }
+
+  start := binary.BigEndian.Uint32(network.IP.To4())
+  size := uint32(1) << uint32(bits - ones)
+  return ipPoolRange{first: start + 2, last: start + size - 2}, nil
+}
+
+// gets the ip for an address in the pool
+func (pool ipPoolRange) ip(address uint32) string {
+  ip := make(net.IP, 4)
+  binary.BigEndian.PutUint32(ip, address)
+  return ip.String()
+}
+
+// checks if an ip is one the pool gives out
+func (pool ipPoolRange) contains(ip string) bool {
+  parsed := net.ParseIP(ip).To4()
+  if parsed == nil {
+    return false
+  }
+
+  address := binary.BigEndian.Uint32(parsed)
+  return address >= pool.first && address <= pool.last
+}
@@ -0,0 +1,192 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// gets valid settings where the prod cluster has blank ips
+func getPoolSettings() Settings {
+  settings := getValidSettings()
+  settings.Providers["vmware"] = Provider{
+    ProviderType: "vmware-desktop",
+    BoxName: "bento/ubuntu-24.04",
+    VmNet: "vmnet2",
+    IpPool: "192.168.1.0/24",
+  }
+
+  prod := settings.Clusters["prod"]
+  prod.Vip = ""
+  prod.Leaders = []Machine{
+    {Name: "prod-cp01", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+    {Name: "prod-cp02", IpAddress: "192.168.1.3", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+  }
+  prod.Workers = []Machine{
+    {Name: "prod-w01", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+  }
+  settings.Clusters["prod"] = prod
+  return settings
+}
+
+/*
+      Tests for parseIpPool
+*/
+func TestParseIpPool(t *testing.T) {
+  pool, err := parseIpPool("10.0.0.0/29")
+  assert.NoError(t, err)
+
+  ips := []string{}
+  for address := pool.first; address <= pool.last; address++ {
+    ips = append(ips, pool.ip(address))
+  }
+  assert.Equal(t, []string{"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"}, ips)
+
+  assert.True(t, pool.contains("10.0.0.6"))
+  assert.False(t, pool.contains("10.0.0.1"))
+  assert.False(t, pool.contains("10.0.0.7"))
+  assert.False(t, pool.contains("not-an-ip"))
+
+  pool, err = parseIpPool("10.1.0.0/16")
+  assert.NoError(t, err)
+  assert.Equal(t, "10.1.255.254", pool.ip(pool.last))
+
+  _, err = parseIpPool("10.0.0.0/8")
+  assert.ErrorContains(t, err, "too large")
+
+  _, err = parseIpPool("10.0.0.0/31")
+  assert.Error(t, err)
+
+  _, err = parseIpPool("not-a-cidr")
+  assert.Error(t, err)
+}
+
+/*
+      Tests for AllocateClusterIps
+*/
+func TestAllocateClusterIps(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  settings := getPoolSettings()
+  assert.Empty(t, settings.Validate())
+
+  err = settings.AllocateClusterIps(util.MockAppDir, "prod")
+  assert.NoError(t, err)
+
+  // .2 is free, .3 is set on cp02 and .10 is used by dev
+  prod := settings.Clusters["prod"]
+  assert.Equal(t, "192.168.1.2", prod.Leaders[0].IpAddress)
+  assert.Equal(t, "192.168.1.3", prod.Leaders[1].IpAddress)
+  assert.Equal(t, "192.168.1.4", prod.Workers[0].IpAddress)
+  assert.Equal(t, "192.168.1.5", prod.Vip)
+
+  allocations, err := ReadIpAllocations(util.MockAppDir)
+  assert.NoError(t, err)
+  assert.Equal(t, map[string]string{
+    "prod-cp01": "192.168.1.2",
+    "prod-w01": "192.168.1.4",
+    "vip": "192.168.1.5",
+  }, allocations["prod"])
+}
+
+func TestAllocateClusterIpsStable(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  err = WriteIpAllocations(util.MockAppDir, IpAllocations{
+    "prod": {"prod-cp01": "192.168.1.50", "prod-w01": "192.168.1.2"},
+  })
+  assert.NoError(t, err)
+
+  settings := getPoolSettings()
+  err = settings.AllocateClusterIps(util.MockAppDir, "prod")
+  assert.NoError(t, err)
+
+  prod := settings.Clusters["prod"]
+  assert.Equal(t, "192.168.1.50", prod.Leaders[0].IpAddress)
+  assert.Equal(t, "192.168.1.2", prod.Workers[0].IpAddress)
+  assert.Equal(t, "192.168.1.4", prod.Vip)
+}
+
+func TestAllocateClusterIpsNoPool(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  settings := getPoolSettings()
+  provider := settings.Providers["vmware"]
+  provider.IpPool = ""
+  settings.Providers["vmware"] = provider
+
+  assert.Contains(t, validationPaths(settings.Validate()), "clusters.prod.leaders[0].ipAddress")
+
+  err = settings.AllocateClusterIps(util.MockAppDir, "prod")
+  assert.ErrorContains(t, err, "ipPool")
+}
+
+/*
+      Tests for ApplyIpAllocations
+*/
+func TestApplyIpAllocations(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  settings := getPoolSettings()
+  err = settings.AllocateClusterIps(util.MockAppDir, "prod")
+  assert.NoError(t, err)
+  allocated := settings.Clusters["prod"]
+
+  // a fresh read of the settings gets the allocated ips
+  settings = getPoolSettings()
+  err = settings.ApplyIpAllocations(util.MockAppDir, "prod")
+  assert.NoError(t, err)
+  assert.Equal(t, allocated, settings.Clusters["prod"])
+
+  // nothing is allocated for a cluster without allocations
+  assert.NoError(t, WriteIpAllocations(util.MockAppDir, IpAllocations{}))
+  settings = getPoolSettings()
+  err = settings.ApplyIpAllocations(util.MockAppDir, "prod")
+  assert.NoError(t, err)
+  assert.Equal(t, "", settings.Clusters["prod"].Leaders[0].IpAddress)
+  assert.Equal(t, "", settings.Clusters["prod"].Vip)
+}
+
+/*
+      Tests for CheckClusterIpOverlap
+*/
+func TestCheckClusterIpOverlap(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  settings := getValidSettings()
+  err = settings.CheckClusterIpOverlap(util.MockAppDir, "prod")
+  assert.NoError(t, err)
+
+  // allocated to another cluster
+  err = WriteIpAllocations(util.MockAppDir, IpAllocations{
+    "dev": {"vip": "192.168.1.22"},
+  })
+  assert.NoError(t, err)
+
+  err = settings.CheckClusterIpOverlap(util.MockAppDir, "prod")
+  assert.ErrorContains(t, err, "192.168.1.22 (prod-cp02) is used by dev/vip")
+
+  // set on another cluster
+  dev := settings.Clusters["dev"]
+  dev.Leaders[0].IpAddress = "192.168.1.23"
+  settings.Clusters["dev"] = dev
+
+  err = settings.CheckClusterIpOverlap(util.MockAppDir, "prod")
+  assert.ErrorContains(t, err, "192.168.1.23 (prod-w01) is used by dev/dev")
+}
@@ -0,0 +1,141 @@
+package settings
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+  K3sArgs - Extra arguments for k3s (or rke2), set for a cluster in the
+  cluster features and overridden for a machine or worker pool,
+  a list set on a machine replaces the cluster list of the same kind
+*/
+type K3sArgs struct {
+  ServerArgs []string          `json:"serverArgs,omitempty"`          // extra k3s server arguments (ex --flannel-backend=wireguard-native), control plane machines only
+  AgentArgs []string           `json:"agentArgs,omitempty"`           // extra k3s agent arguments (ex --node-ip=192.168.1.10), worker machines only
+  KubeletArgs []string         `json:"kubeletArgs,omitempty"`         // kubelet arguments passed with --kubelet-arg (ex max-pods=200)
+  DisableComponents []string   `json:"disableComponents,omitempty"`   // packaged components to disable (ex servicelb), control plane machines only
+}
+
+// a k3s flag, --name or --name=value
+var k3sArgRegex = regexp.MustCompile(`^--[a-z0-9][a-z0-9-]*(=\S.*)?$`)
+
+// a kubelet arg without the leading dashes, name=value
+var kubeletArgRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*=\S.*$`)
+
+/*
+WithOverrides()
+Gets the k3s args with the lists that are set in the
+overrides replacing the lists in these args
+*/
+func (args K3sArgs) WithOverrides(overrides K3sArgs) K3sArgs {
+  if len(overrides.ServerArgs) > 0 {
+    args.ServerArgs = overrides.ServerArgs
+  }
+  if len(overrides.AgentArgs) > 0 {
+    args.AgentArgs = overrides.AgentArgs
+  }
+  if len(overrides.KubeletArgs) > 0 {
+    args.KubeletArgs = overrides.KubeletArgs
+  }
+  if len(overrides.DisableComponents) > 0 {
+    args.DisableComponents = overrides.DisableComponents
+  }
+  return args
+}
+
+/*
+GetServerArgs()
+Gets the k3s command line arguments for a control plane machine
+*/
+func (args K3sArgs) GetServerArgs() []string {
+  commandArgs := append([]string{}, args.ServerArgs...)
+
+  for _, component := range args.DisableComponents {
+    commandArgs = append(commandArgs, "--disable=" + component)
+  }
+  return append(commandArgs, args.getKubeletArgs()...)
+}
+
+/*
+GetAgentArgs()
+Gets the k3s command line arguments for a worker machine
+*/
+func (args K3sArgs) GetAgentArgs() []string {
+  commandArgs := append([]string{}, args.AgentArgs...)
+  return append(commandArgs, args.getKubeletArgs()...)
+}
+
+// gets the kubelet args as k3s arguments
+func (args K3sArgs) getKubeletArgs() []string {
+  commandArgs := []string{}
+
+  for _, kubeletArg := range args.KubeletArgs {
+    commandArgs = append(commandArgs, "--kubelet-arg=" + kubeletArg)
+  }
+  return commandArgs
+}
+
+/*
+GetMachineK3sArgs()
+Gets the k3s command line arguments for a machine in the
+cluster, the machine overrides are applied to the cluster
+feature args. Leaders get server args and workers agent args
+*/
+func (cluster Cluster) GetMachineK3sArgs(machine Machine, server bool) []string {
+  args := K3sArgs{}
+  if cluster.ClusterFeatures != nil {
+    args = cluster.ClusterFeatures.K3sArgs
+  }
+  args = args.WithOverrides(machine.K3sArgs)
+
+  if server {
+    return args.GetServerArgs()
+  }
+  return args.GetAgentArgs()
+}
+
+// validates k3s args, server and agent are false when the args
+// are for a machine that is not a k3s server or agent. The components
+// that can be disabled depend on the distribution
+func (validator *settingsValidator) validateK3sArgs(path string, args K3sArgs, distribution DistributionDefinition,
+server bool, agent bool) {
+  for index, arg := range args.ServerArgs {
+    if !k3sArgRegex.MatchString(arg) {
+      validator.add(fmt.Sprintf("%s.serverArgs[%d]", path, index), "malformed k3s argument %q, expected --name or --name=value", arg)
+    }
+  }
+
+  for index, arg := range args.AgentArgs {
+    if !k3sArgRegex.MatchString(arg) {
+      validator.add(fmt.Sprintf("%s.agentArgs[%d]", path, index), "malformed k3s argument %q, expected --name or --name=value", arg)
+    }
+  }
+
+  for index, arg := range args.KubeletArgs {
+    if !kubeletArgRegex.MatchString(arg) {
+      validator.add(fmt.Sprintf("%s.kubeletArgs[%d]", path, index), "malformed kubelet argument %q, expected name=value", arg)
+    }
+  }
+
+  for index, component := range args.DisableComponents {
+    if !contains(distribution.DisableComponents, component) {
+      validator.add(fmt.Sprintf("%s.disableComponents[%d]", path, index), "unsupported component %q for %s, must be one of %s",
+        component, distribution.Name, strings.Join(distribution.DisableComponents, ", "))
+    }
+  }
+
+  if !server {
+    if len(args.ServerArgs) > 0 {
+      validator.add(path + ".serverArgs", "server args are only used on leaders")
+    }
+    if len(args.DisableComponents) > 0 {
+      validator.add(path + ".disableComponents", "disable components is only used on leaders")
+    }
+  }
+
+  if !agent && len(args.AgentArgs) > 0 {
+    validator.add(path + ".agentArgs", "agent args are only used on workers")
+  }
+}
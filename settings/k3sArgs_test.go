@@ -0,0 +1,144 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for WithOverrides
+*/
+func TestK3sArgsWithOverrides(t *testing.T) {
+  args := K3sArgs{
+    ServerArgs: []string{"--flannel-backend=wireguard-native"},
+    KubeletArgs: []string{"max-pods=200"},
+    DisableComponents: []string{"servicelb"},
+  }
+
+  overridden := args.WithOverrides(K3sArgs{KubeletArgs: []string{"max-pods=50"}})
+
+  assert.Equal(t, []string{"--flannel-backend=wireguard-native"}, overridden.ServerArgs)
+  assert.Equal(t, []string{"max-pods=50"}, overridden.KubeletArgs)
+  assert.Equal(t, []string{"servicelb"}, overridden.DisableComponents)
+
+  // the original args are not changed
+  assert.Equal(t, []string{"max-pods=200"}, args.KubeletArgs)
+}
+
+/*
+      Tests for GetMachineK3sArgs
+*/
+func TestGetMachineK3sArgs(t *testing.T) {
+  cluster := Cluster{
+    ClusterFeatures: &ClusterFeatures{
+      K3sArgs: K3sArgs{
+        ServerArgs: []string{"--secrets-encryption"},
+        AgentArgs: []string{"--node-label=pool=default"},
+        KubeletArgs: []string{"max-pods=200"},
+        DisableComponents: []string{"servicelb", "metrics-server"},
+      },
+    },
+  }
+
+  server := Machine{Name: "cp01"}
+  assert.Equal(t, []string{
+    "--secrets-encryption",
+    "--disable=servicelb",
+    "--disable=metrics-server",
+    "--kubelet-arg=max-pods=200",
+  }, cluster.GetMachineK3sArgs(server, true))
+
+  worker := Machine{Name: "w01", K3sArgs: K3sArgs{AgentArgs: []string{"--node-ip=192.168.1.30"}}}
+  assert.Equal(t, []string{
+    "--node-ip=192.168.1.30",
+    "--kubelet-arg=max-pods=200",
+  }, cluster.GetMachineK3sArgs(worker, false))
+
+  assert.Empty(t, Cluster{}.GetMachineK3sArgs(server, true))
+}
+
+/*
+      Tests for k3s arg validation
+*/
+func TestValidateK3sArgs(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.ClusterFeatures.K3sArgs = K3sArgs{
+    ServerArgs: []string{"--secrets-encryption", "--etcd-snapshot-retention=10"},
+    AgentArgs: []string{"--node-label=pool=default"},
+    KubeletArgs: []string{"max-pods=200", "feature-gates=GracefulNodeShutdown=true"},
+    DisableComponents: []string{"servicelb"},
+  }
+  prod.Leaders[1].K3sArgs = K3sArgs{DisableComponents: []string{"metrics-server"}}
+  prod.Workers[0].K3sArgs = K3sArgs{AgentArgs: []string{"--node-ip=192.168.1.23"}}
+  appSettings.Clusters["prod"] = prod
+  assert.Empty(t, appSettings.Validate())
+
+  prod.ClusterFeatures.K3sArgs = K3sArgs{
+    ServerArgs: []string{"--disable servicelb"},
+    AgentArgs: []string{"node-ip=192.168.1.23"},
+    KubeletArgs: []string{"--max-pods=200"},
+    DisableComponents: []string{"kube-proxy", "traefik"},
+  }
+  prod.Leaders[1].K3sArgs = K3sArgs{AgentArgs: []string{"--node-ip=192.168.1.22"}}
+  prod.Workers[0].K3sArgs = K3sArgs{ServerArgs: []string{"--secrets-encryption"}, DisableComponents: []string{"servicelb"}}
+  appSettings.Clusters["prod"] = prod
+
+  assert.Equal(t, []string{
+    "clusters.prod.leaders[1].agentArgs",
+    "clusters.prod.workers[0].serverArgs",
+    "clusters.prod.workers[0].disableComponents",
+    "clusters.prod.clusterFeatures.serverArgs[0]",
+    "clusters.prod.clusterFeatures.agentArgs[0]",
+    "clusters.prod.clusterFeatures.kubeletArgs[0]",
+    "clusters.prod.clusterFeatures.disableComponents[0]",
+    "clusters.prod.clusterFeatures.disableComponents",
+  }, validationPaths(appSettings.Validate()))
+}
+
+func TestValidateK3sArgsStorageConflict(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.ClusterFeatures.DisableComponents = []string{"local-storage"}
+  appSettings.Clusters["prod"] = prod
+
+  assert.Equal(t, []string{
+    "clusters.prod.clusterFeatures.disableComponents",
+  }, validationPaths(appSettings.Validate()))
+
+  prod.ClusterFeatures.StorageController = "longhorn"
+  appSettings.Clusters["prod"] = prod
+  assert.Empty(t, appSettings.Validate())
+}
+
+/*
+      Tests for k3s args in worker pools and settings paths
+*/
+func TestWorkerPoolK3sArgs(t *testing.T) {
+  pool := WorkerPool{
+    NamePrefix: "gpu", Count: 2, Memory: 4096, Cpu: 2, DiskSize: "50GB",
+    K3sArgs: K3sArgs{KubeletArgs: []string{"max-pods=50"}},
+  }
+
+  machines, err := pool.GetMachines()
+  assert.NoError(t, err)
+  assert.Equal(t, []string{"max-pods=50"}, machines[1].KubeletArgs)
+}
+
+func TestK3sArgsSettingsPath(t *testing.T) {
+  settings := getValidSettings()
+
+  err := settings.SetValue("clusters.prod.clusterFeatures.disableComponents", `["servicelb"]`)
+  assert.NoError(t, err)
+  assert.Equal(t, []string{"servicelb"}, settings.Clusters["prod"].ClusterFeatures.DisableComponents)
+
+  err = settings.SetValue("clusters.prod.workers[0].kubeletArgs", `["max-pods=50"]`)
+  assert.NoError(t, err)
+
+  value, err := settings.GetValue("clusters.prod.workers[0].kubeletArgs")
+  assert.NoError(t, err)
+  assert.Equal(t, []string{"max-pods=50"}, value)
+}
@@ -0,0 +1,474 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/util"
+)
+
+/*
+  The current version of the settings schema, any time a field
+  is added or renamed in a way that older settings files would
+  break this should be bumped and a migration added
+*/
+const CurrentSchemaVersion = 1
+
+/*
+  settingsMigration - A single step to upgrade a settings
+  document from the version before it to version
+*/
+type settingsMigration struct {
+  version int                                           // the version the document is at after the migration
+  description string                                    // what the migration does
+  migrate func(document *orderedDocument) error         // upgrades the document in place
+}
+
+/*
+  All the migrations in order, documents without a schema version
+  are version 0
+*/
+var settingsMigrations = []settingsMigration{
+  {
+    version: 1,
+    description: "rename clusterFeatures cillumCliVersion to ciliumCliVersion",
+    migrate: migrateCiliumCliVersionName,
+  },
+}
+
+/*
+MigrateSettingsFile()
+Migrates the settings file to the current schema version, the
+original file is backed up before it is rewritten. If dryRun is
+set nothing is written. The diff of the changes is returned along
+with if a migration was needed
+*/
+func MigrateSettingsFile(appDir string, dryRun bool) (string, bool, error) {
+  settingsFile := filepath.Join(appDir, "settings.json")
+
+  original, err := os.ReadFile(settingsFile)
+  if err != nil {
+    logger.LogError("Error reading settings file")
+    return "", false, err
+  }
+
+  migrated, migrationNeeded, err := migrateSettingsFile(appDir, dryRun)
+  if err != nil {
+    return "", false, err
+  }
+
+  if !migrationNeeded {
+    return "", false, nil
+  }
+  return util.LineDiff(string(original), string(migrated)), true, nil
+}
+
+/*
+reads the settings file and migrates it if needed, the migrated
+bytes are returned along with if a migration was needed. When dry
+run is not set the original file is backed up and the migrated
+file written
+*/
+func migrateSettingsFile(appDir string, dryRun bool) ([]byte, bool, error) {
+  settingsFile := filepath.Join(appDir, "settings.json")
+
+  original, err := os.ReadFile(settingsFile)
+  if err != nil {
+    logger.LogError("Error reading settings file")
+    return nil, false, err
+  }
+
+  fromVersion, migrated, err := migrateSettingsBytes(original)
+  if err != nil {
+    logger.LogError("Error migrating settings")
+    return nil, false, err
+  }
+
+  if fromVersion == CurrentSchemaVersion {
+    logger.LogDebug("Settings file is at the current schema version", "version", fromVersion)
+    return original, false, nil
+  }
+
+  if dryRun {
+    logger.LogDebug("Dry run set, not writing migrated settings")
+    return migrated, true, nil
+  }
+
+  backupFile := filepath.Join(appDir, fmt.Sprintf("settings.json.v%d.bak", fromVersion))
+  logger.LogInfo("Backing up settings file before migration", "backup", backupFile)
+
+  err = os.WriteFile(backupFile, original, 0644)
+  if err != nil {
+    logger.LogError("Error backing up the settings file")
+    return nil, false, err
+  }
+
+  err = os.WriteFile(settingsFile, migrated, 0644)
+  if err != nil {
+    logger.LogError("Error writing the migrated settings file")
+    return nil, false, err
+  }
+
+  logger.LogInfo("Settings file migrated", "from", fromVersion, "to", CurrentSchemaVersion)
+  return migrated, true, nil
+}
+
+/*
+runs all the needed migrations on a settings document, returns the
+version the document was at and the migrated document. If the document
+is already at the current version it is returned unchanged. The
+document is migrated as json so keys the settings don't know about,
+the order of the keys and the indent are kept and only what the
+migrations change is different
+*/
+func migrateSettingsBytes(original []byte) (int, []byte, error) {
+  document, err := parseOrderedDocument(original)
+  if err != nil {
+    logger.LogError("Error unmarshaling settings document")
+    return 0, nil, err
+  }
+
+  fromVersion, err := getSchemaVersion(document)
+  if err != nil {
+    return 0, nil, err
+  }
+
+  if fromVersion > CurrentSchemaVersion {
+    logger.LogError("Settings file is from a newer version of local-kube", "version", fromVersion)
+    return 0, nil, fmt.Errorf("settings schema version %d is newer than the supported version %d",
+      fromVersion, CurrentSchemaVersion)
+  }
+
+  if fromVersion == CurrentSchemaVersion {
+    return fromVersion, original, nil
+  }
+
+  err = runMigrations(document, fromVersion)
+  if err != nil {
+    return 0, nil, err
+  }
+
+  migrated, err := formatDocument(original, document)
+  if err != nil {
+    return 0, nil, err
+  }
+  return fromVersion, migrated, nil
+}
+
+/*
+runs the migrations on a cluster file, cluster files store their
+own schema version (no version is version 0) and are migrated the
+same way as the settings file. The cluster is returned with the
+version it was at and the migrated cluster, a cluster already at the
+current version is returned unchanged
+*/
+func migrateClusterBytes(clusterName string, original []byte) (int, []byte, error) {
+  cluster, err := parseOrderedDocument(original)
+  if err != nil {
+    logger.LogError("Error unmarshaling cluster document", "cluster", clusterName)
+    return 0, nil, err
+  }
+
+  fromVersion, err := getSchemaVersion(cluster)
+  if err != nil {
+    return 0, nil, err
+  }
+
+  if fromVersion > CurrentSchemaVersion {
+    logger.LogError("Cluster file is from a newer version of local-kube", "cluster", clusterName, "version", fromVersion)
+    return 0, nil, fmt.Errorf("cluster schema version %d is newer than the supported version %d",
+      fromVersion, CurrentSchemaVersion)
+  }
+
+  if fromVersion == CurrentSchemaVersion {
+    return fromVersion, original, nil
+  }
+
+  // the migrations run on settings documents so the cluster is
+  // put in a settings document with just the cluster
+  cluster.remove("schemaVersion")
+  clusterJson, err := cluster.MarshalJSON()
+  if err != nil {
+    return 0, nil, err
+  }
+  clustersJson, err := json.Marshal(map[string]json.RawMessage{clusterName: clusterJson})
+  if err != nil {
+    return 0, nil, err
+  }
+  document := &orderedDocument{
+    keys: []string{"clusters"},
+    values: map[string]json.RawMessage{"clusters": clustersJson},
+  }
+
+  err = runMigrations(document, fromVersion)
+  if err != nil {
+    return 0, nil, err
+  }
+
+  clusters, err := parseOrderedDocument(document.values["clusters"])
+  if err != nil {
+    return 0, nil, err
+  }
+  migratedCluster, err := parseOrderedDocument(clusters.values[clusterName])
+  if err != nil {
+    return 0, nil, err
+  }
+  setSchemaVersion(migratedCluster, CurrentSchemaVersion)
+
+  migrated, err := formatDocument(original, migratedCluster)
+  if err != nil {
+    return 0, nil, err
+  }
+  return fromVersion, migrated, nil
+}
+
+// runs the migrations after fromVersion on a document and sets
+// the schema version of the document
+func runMigrations(document *orderedDocument, fromVersion int) error {
+  for _, migration := range settingsMigrations {
+    if migration.version <= fromVersion {
+      continue
+    }
+
+    logger.LogDebug("Running settings migration", "version", migration.version, "description", migration.description)
+    err := migration.migrate(document)
+    if err != nil {
+      logger.LogError("Error running settings migration", "version", migration.version)
+      return err
+    }
+
+    setSchemaVersion(document, migration.version)
+  }
+  return nil
+}
+
+// sets the schema version of a document, the schema
+// version is the first key like the settings write it
+func setSchemaVersion(document *orderedDocument, version int) {
+  if _, exists := document.get("schemaVersion"); !exists {
+    document.keys = append([]string{"schemaVersion"}, document.keys...)
+  }
+  document.values["schemaVersion"] = json.RawMessage(strconv.Itoa(version))
+}
+
+// gets the schema version of a document, no version is version 0
+func getSchemaVersion(document *orderedDocument) (int, error) {
+  rawVersion, exists := document.get("schemaVersion")
+  if !exists {
+    return 0, nil
+  }
+
+  var version float64
+  err := json.Unmarshal(rawVersion, &version)
+  if err != nil || version != float64(int(version)) {
+    return 0, fmt.Errorf("schemaVersion must be a whole number, got %s", rawVersion)
+  }
+  return int(version), nil
+}
+
+// runs a function on every cluster in a settings document
+func forEachClusterDocument(document *orderedDocument, update func(cluster *orderedDocument) error) error {
+  return updateObject(document, "clusters", func(clusters *orderedDocument) error {
+    for _, clusterName := range clusters.keys {
+      err := updateObject(clusters, clusterName, update)
+      if err != nil {
+        return err
+      }
+    }
+    return nil
+  })
+}
+
+/*
+  orderedDocument - A json object that keeps the order of its
+  keys and the original json of its values, so a rewritten
+  document only changes where it was changed
+*/
+type orderedDocument struct {
+  keys []string                         // the keys in the order they are in the json
+  values map[string]json.RawMessage     // key -> the json of the value
+}
+
+// parses a json object into an ordered document
+func parseOrderedDocument(raw []byte) (*orderedDocument, error) {
+  document := &orderedDocument{values: make(map[string]json.RawMessage)}
+  decoder := json.NewDecoder(bytes.NewReader(raw))
+
+  token, err := decoder.Token()
+  if err != nil {
+    return nil, err
+  }
+  if delim, ok := token.(json.Delim); !ok || delim != '{' {
+    return nil, errors.New("expected a json object")
+  }
+
+  for decoder.More() {
+    token, err := decoder.Token()
+    if err != nil {
+      return nil, err
+    }
+    key := token.(string)
+
+    var value json.RawMessage
+    err = decoder.Decode(&value)
+    if err != nil {
+      return nil, err
+    }
+
+    if _, exists := document.values[key]; !exists {
+      document.keys = append(document.keys, key)
+    }
+    document.values[key] = value
+  }
+
+  // the closing brace and nothing after it
+  if _, err := decoder.Token(); err != nil {
+    return nil, err
+  }
+  if _, err := decoder.Token(); err != io.EOF {
+    return nil, errors.New("unexpected data after the json object")
+  }
+  return document, nil
+}
+
+// gets the json of a value in the document
+func (document *orderedDocument) get(key string) (json.RawMessage, bool) {
+  value, exists := document.values[key]
+  return value, exists
+}
+
+// renames a key keeping its place in the document
+func (document *orderedDocument) rename(oldKey string, newKey string) {
+  for index, key := range document.keys {
+    if key == oldKey {
+      document.keys[index] = newKey
+    }
+  }
+  document.values[newKey] = document.values[oldKey]
+  delete(document.values, oldKey)
+}
+
+// removes a key from the document
+func (document *orderedDocument) remove(key string) {
+  keys := []string{}
+  for _, existingKey := range document.keys {
+    if existingKey != key {
+      keys = append(keys, existingKey)
+    }
+  }
+  document.keys = keys
+  delete(document.values, key)
+}
+
+// encodes the document as compact json in key order
+func (document *orderedDocument) MarshalJSON() ([]byte, error) {
+  var buffer bytes.Buffer
+
+  buffer.WriteByte('{')
+  for index, key := range document.keys {
+    if index > 0 {
+      buffer.WriteByte(',')
+    }
+
+    keyBytes, err := json.Marshal(key)
+    if err != nil {
+      return nil, err
+    }
+    buffer.Write(keyBytes)
+    buffer.WriteByte(':')
+    buffer.Write(document.values[key])
+  }
+  buffer.WriteByte('}')
+
+  var compact bytes.Buffer
+  err := json.Compact(&compact, buffer.Bytes())
+  return compact.Bytes(), err
+}
+
+// runs a function on an object value in the document and stores
+// the result, values that are missing or not objects are skipped
+func updateObject(document *orderedDocument, key string, update func(object *orderedDocument) error) error {
+  raw, exists := document.get(key)
+  if trimmed := bytes.TrimSpace(raw); !exists || len(trimmed) == 0 || trimmed[0] != '{' {
+    return nil
+  }
+
+  object, err := parseOrderedDocument(raw)
+  if err != nil {
+    return err
+  }
+
+  err = update(object)
+  if err != nil {
+    return err
+  }
+
+  document.values[key], err = object.MarshalJSON()
+  return err
+}
+
+// formats a document with the indent of the original json,
+// original json on one line stays on one line
+func formatDocument(original []byte, document *orderedDocument) ([]byte, error) {
+  var buffer bytes.Buffer
+
+  compact, err := document.MarshalJSON()
+  if err != nil {
+    return nil, err
+  }
+
+  if !bytes.Contains(bytes.TrimSpace(original), []byte("\n")) {
+    buffer.Write(compact)
+  } else {
+    err = json.Indent(&buffer, compact, "", getJsonIndent(original))
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  if bytes.HasSuffix(original, []byte("\n")) {
+    buffer.WriteByte('\n')
+  }
+  return buffer.Bytes(), nil
+}
+
+// gets the indent of the first indented line of some json, one
+// space (the settings file indent) if no line is indented
+func getJsonIndent(original []byte) string {
+  for _, line := range bytes.Split(original, []byte("\n"))[1:] {
+    trimmed := bytes.TrimLeft(line, " \t")
+    if len(trimmed) > 0 && len(trimmed) < len(line) {
+      return string(line[:len(line) - len(trimmed)])
+    }
+  }
+  return " "
+}
+
+/*
+  Migrations
+*/
+
+// version 1 - the cilium cli version field was misspelled
+func migrateCiliumCliVersionName(document *orderedDocument) error {
+  return forEachClusterDocument(document, func(cluster *orderedDocument) error {
+    return updateObject(cluster, "clusterFeatures", func(features *orderedDocument) error {
+      if _, exists := features.get("cillumCliVersion"); !exists {
+        return nil
+      }
+
+      if _, alreadySet := features.get("ciliumCliVersion"); alreadySet {
+        features.remove("cillumCliVersion")
+      } else {
+        features.rename("cillumCliVersion", "ciliumCliVersion")
+      }
+      return nil
+    })
+  })
+}
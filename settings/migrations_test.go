@@ -0,0 +1,143 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var versionZeroSettings = `{
+ "kubeconfigPath": "~/.kube/config",
+ "provision": {
+  "ansibleVersion": "2.17.6",
+  "ansibleRoles": {}
+ },
+ "providers": {},
+ "clusters": {
+  "dev": {
+   "clusterType": "single",
+   "leaders": [],
+   "workers": [],
+   "clusterFeatures": {
+    "cniController": "cilium",
+    "cillumCliVersion": "0.16.0"
+   }
+  }
+ }
+}
+`
+
+/*
+      Tests for migrateSettingsBytes
+*/
+func TestMigrateSettingsBytesFromVersionZero(t *testing.T) {
+  fromVersion, migrated, err := migrateSettingsBytes([]byte(versionZeroSettings))
+  assert.NoError(t, err)
+
+  assert.Equal(t, 0, fromVersion)
+  assert.Contains(t, string(migrated), `"schemaVersion": 1`)
+  assert.Contains(t, string(migrated), `"ciliumCliVersion": "0.16.0"`)
+  assert.NotContains(t, string(migrated), "cillumCliVersion")
+}
+
+func TestMigrateSettingsBytesKeepsDocument(t *testing.T) {
+  // keys out of struct order, a key the settings don't have and a two space indent
+  original := `{
+  "clusters": {
+    "dev": {
+      "clusterFeatures": {
+        "cillumCliVersion": "0.16.0",
+        "cniController": "cilium"
+      },
+      "leaders": [],
+      "notes": "added by hand <team>"
+    }
+  },
+  "kubeconfigPath": "~/.kube/config"
+}
+`
+  expected := `{
+  "schemaVersion": 1,
+  "clusters": {
+    "dev": {
+      "clusterFeatures": {
+        "ciliumCliVersion": "0.16.0",
+        "cniController": "cilium"
+      },
+      "leaders": [],
+      "notes": "added by hand <team>"
+    }
+  },
+  "kubeconfigPath": "~/.kube/config"
+}
+`
+
+  _, migrated, err := migrateSettingsBytes([]byte(original))
+  assert.NoError(t, err)
+  assert.Equal(t, expected, string(migrated))
+}
+
+func TestMigrateSettingsBytesCurrentVersion(t *testing.T) {
+  current := []byte(`{"schemaVersion": 1, "clusters": {}}`)
+
+  fromVersion, migrated, err := migrateSettingsBytes(current)
+  assert.NoError(t, err)
+
+  assert.Equal(t, CurrentSchemaVersion, fromVersion)
+  assert.Equal(t, current, migrated)
+}
+
+func TestMigrateSettingsBytesNewerVersion(t *testing.T) {
+  _, _, err := migrateSettingsBytes([]byte(`{"schemaVersion": 99}`))
+  assert.Error(t, err)
+}
+
+/*
+      Tests for ReadSettingsFile migrations &
+      MigrateSettingsFile
+*/
+func TestReadSettingsFileMigrates(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  err = os.WriteFile(filepath.Join(util.MockAppDir, "settings.json"), []byte(versionZeroSettings), 0644)
+  assert.NoError(t, err)
+
+  settings, err := ReadSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+
+  assert.Equal(t, CurrentSchemaVersion, settings.SchemaVersion)
+  assert.Equal(t, "0.16.0", settings.Clusters["dev"].ClusterFeatures.CiliumCliVersion)
+
+  backup, err := os.ReadFile(filepath.Join(util.MockAppDir, "settings.json.v0.bak"))
+  assert.NoError(t, err)
+  assert.Equal(t, versionZeroSettings, string(backup))
+}
+
+func TestMigrateSettingsFileDryRun(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  settingsFile := filepath.Join(util.MockAppDir, "settings.json")
+  err = os.WriteFile(settingsFile, []byte(versionZeroSettings), 0644)
+  assert.NoError(t, err)
+
+  diff, migrated, err := MigrateSettingsFile(util.MockAppDir, true)
+  assert.NoError(t, err)
+
+  assert.True(t, migrated)
+  assert.Contains(t, diff, `-    "cillumCliVersion": "0.16.0"`)
+  assert.Contains(t, diff, `+    "ciliumCliVersion": "0.16.0"`)
+
+  content, err := os.ReadFile(settingsFile)
+  assert.NoError(t, err)
+  assert.Equal(t, versionZeroSettings, string(content))
+  assert.NoFileExists(t, filepath.Join(util.MockAppDir, "settings.json.v0.bak"))
+}
@@ -0,0 +1,209 @@
+package settings
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+)
+
+/*
+  NetworkSettings - Network settings for the machines of
+  a cluster
+*/
+type NetworkSettings struct {
+  Dns []string          `json:"dns,omitempty"`     // The nameservers for the machines, default is 8.8.8.8 and 8.8.4.4
+}
+
+/*
+  ProxySettings - The proxy the machines use to reach the
+  internet, used for apt, pip, containerd and k3s
+*/
+type ProxySettings struct {
+  HttpProxy string      `json:"httpProxy,omitempty"`    // The proxy for http (ex http://proxy.corp:3128)
+  HttpsProxy string     `json:"httpsProxy,omitempty"`   // The proxy for https, the http proxy is used if empty
+  NoProxy []string      `json:"noProxy,omitempty"`      // Extra hosts, domains or cidrs to not proxy, the machine ips, vip and cluster cidrs are always added
+}
+
+var defaultDnsServers = []string{"8.8.8.8", "8.8.4.4"}
+
+// the pod and service cidrs k3s and rke2 use when no
+// --cluster-cidr or --service-cidr server arg is set
+const defaultClusterCidr = "10.42.0.0/16"
+const defaultServiceCidr = "10.43.0.0/16"
+
+// always local to the machines and the cluster
+var defaultNoProxy = []string{"localhost", "127.0.0.1", ".svc", ".cluster.local"}
+
+/*
+GetClusterDnsServers()
+Gets the nameservers for a cluster, the cluster network settings
+are merged on top of the global network settings. The default
+nameservers are used if neither sets any
+*/
+func (settings *Settings) GetClusterDnsServers(clusterName string) []string {
+  global := settings.Network
+  cluster := settings.Clusters[clusterName].Network
+
+  if cluster != nil && len(cluster.Dns) > 0 {
+    return cluster.Dns
+  }
+  if global != nil && len(global.Dns) > 0 {
+    return global.Dns
+  }
+  return defaultDnsServers
+}
+
+/*
+GetClusterProxy()
+Gets the proxy settings for a cluster, the cluster settings
+are merged on top of the global proxy settings. Nil is returned
+if neither has proxy settings
+*/
+func (settings *Settings) GetClusterProxy(clusterName string) *ProxySettings {
+  global := settings.Proxy
+  cluster := settings.Clusters[clusterName].Proxy
+
+  if global == nil {
+    return cluster
+  }
+  if cluster == nil {
+    return global
+  }
+
+  merged := mergeValues(reflect.ValueOf(*global), reflect.ValueOf(*cluster)).Interface().(ProxySettings)
+  return &merged
+}
+
+/*
+GetClusterCaCertificates()
+Gets the ca certificate files for a cluster, the global
+certificates followed by the cluster certificates
+*/
+func (settings *Settings) GetClusterCaCertificates(clusterName string) []string {
+  certificates := []string{}
+
+  for _, certificate := range append(append([]string{}, settings.CaCertificates...),
+    settings.Clusters[clusterName].CaCertificates...) {
+    if !contains(certificates, certificate) {
+      certificates = append(certificates, certificate)
+    }
+  }
+  return certificates
+}
+
+/*
+GetHttpsProxy()
+Gets the https proxy, the http proxy if no https proxy is set
+*/
+func (proxy ProxySettings) GetHttpsProxy() string {
+  if proxy.HttpsProxy == "" {
+    return proxy.HttpProxy
+  }
+  return proxy.HttpsProxy
+}
+
+/*
+GetClusterCidrs()
+Gets the pod and service cidrs of the cluster, these are the
+k3s defaults unless they are set in the cluster server args
+*/
+func (cluster Cluster) GetClusterCidrs() []string {
+  cidrs := []string{defaultClusterCidr, defaultServiceCidr}
+  if cluster.ClusterFeatures == nil {
+    return cidrs
+  }
+
+  for _, arg := range cluster.ClusterFeatures.ServerArgs {
+    if value, found := strings.CutPrefix(arg, "--cluster-cidr="); found {
+      cidrs[0] = value
+    } else if value, found := strings.CutPrefix(arg, "--service-cidr="); found {
+      cidrs[1] = value
+    }
+  }
+  return cidrs
+}
+
+/*
+GetNoProxy()
+Gets the hosts the machines should not proxy, the local hosts,
+the machine names and ips, the vip, the cluster cidrs and the
+extra no proxy entries of the proxy settings
+*/
+func (cluster Cluster) GetNoProxy(proxy ProxySettings) []string {
+  noProxy := append([]string{}, defaultNoProxy...)
+
+  add := func(entry string) {
+    if entry != "" && !contains(noProxy, entry) {
+      noProxy = append(noProxy, entry)
+    }
+  }
+
+  for _, machine := range append(append([]Machine{}, cluster.Leaders...), cluster.Workers...) {
+    add(machine.Name)
+    add(machine.IpAddress)
+  }
+  add(cluster.Vip)
+
+  for _, cidr := range cluster.GetClusterCidrs() {
+    add(cidr)
+  }
+  for _, entry := range proxy.NoProxy {
+    add(entry)
+  }
+  return noProxy
+}
+
+// validates dns, proxy and ca certificate settings, the paths are
+// the json paths of each of the settings
+func (validator *settingsValidator) validateNetworkSettings(networkPath string, network *NetworkSettings,
+proxyPath string, proxy *ProxySettings, certificatesPath string, certificates []string) {
+  if network != nil {
+    for index, server := range network.Dns {
+      if net.ParseIP(server) == nil {
+        validator.add(fmt.Sprintf("%s.dns[%d]", networkPath, index), "%q is not a valid ip address", server)
+      }
+    }
+  }
+
+  if proxy != nil {
+    proxyUrls := []struct {
+      name string
+      value string
+    }{
+      {"httpProxy", proxy.HttpProxy},
+      {"httpsProxy", proxy.HttpsProxy},
+    }
+
+    for _, proxyUrl := range proxyUrls {
+      if proxyUrl.value == "" {
+        continue
+      }
+
+      parsedUrl, err := url.Parse(proxyUrl.value)
+      if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+        validator.add(proxyPath + "." + proxyUrl.name, "malformed proxy url %q, expected http(s)://host:port", proxyUrl.value)
+      }
+    }
+
+    for index, entry := range proxy.NoProxy {
+      if entry == "" || strings.ContainsAny(entry, " ,") {
+        validator.add(fmt.Sprintf("%s.noProxy[%d]", proxyPath, index), "malformed no proxy entry %q, expected a host, domain or cidr", entry)
+      }
+    }
+  }
+
+  for index, certificate := range certificates {
+    path := fmt.Sprintf("%s[%d]", certificatesPath, index)
+
+    content, err := os.ReadFile(certificate)
+    if err != nil {
+      validator.add(path, "ca certificate %s can not be read", certificate)
+    } else if block, _ := pem.Decode(content); block == nil || block.Type != "CERTIFICATE" {
+      validator.add(path, "ca certificate %s is not a pem encoded certificate", certificate)
+    }
+  }
+}
@@ -0,0 +1,129 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a pem block that is enough for the ca certificate validation
+const testCaCertificate = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+
+/*
+      Tests for GetClusterDnsServers
+*/
+func TestGetClusterDnsServers(t *testing.T) {
+  appSettings := getValidSettings()
+  assert.Equal(t, []string{"8.8.8.8", "8.8.4.4"}, appSettings.GetClusterDnsServers("dev"))
+
+  appSettings.Network = &NetworkSettings{Dns: []string{"10.0.0.2"}}
+  assert.Equal(t, []string{"10.0.0.2"}, appSettings.GetClusterDnsServers("dev"))
+
+  // the cluster dns replaces the global dns
+  dev := appSettings.Clusters["dev"]
+  dev.Network = &NetworkSettings{Dns: []string{"10.1.0.2", "10.1.0.3"}}
+  appSettings.Clusters["dev"] = dev
+  assert.Equal(t, []string{"10.1.0.2", "10.1.0.3"}, appSettings.GetClusterDnsServers("dev"))
+  assert.Equal(t, []string{"10.0.0.2"}, appSettings.GetClusterDnsServers("prod"))
+}
+
+/*
+      Tests for GetClusterProxy
+*/
+func TestGetClusterProxy(t *testing.T) {
+  appSettings := getValidSettings()
+  assert.Nil(t, appSettings.GetClusterProxy("dev"))
+
+  appSettings.Proxy = &ProxySettings{HttpProxy: "http://proxy.corp:3128", NoProxy: []string{".corp"}}
+  assert.Equal(t, "http://proxy.corp:3128", appSettings.GetClusterProxy("dev").GetHttpsProxy())
+
+  dev := appSettings.Clusters["dev"]
+  dev.Proxy = &ProxySettings{HttpsProxy: "http://secure.corp:3128"}
+  appSettings.Clusters["dev"] = dev
+
+  proxy := appSettings.GetClusterProxy("dev")
+  assert.Equal(t, "http://proxy.corp:3128", proxy.HttpProxy)
+  assert.Equal(t, "http://secure.corp:3128", proxy.GetHttpsProxy())
+  assert.Equal(t, []string{".corp"}, proxy.NoProxy)
+}
+
+/*
+      Tests for GetClusterCaCertificates
+*/
+func TestGetClusterCaCertificates(t *testing.T) {
+  appSettings := getValidSettings()
+  assert.Empty(t, appSettings.GetClusterCaCertificates("dev"))
+
+  appSettings.CaCertificates = []string{"/certs/root.pem"}
+  dev := appSettings.Clusters["dev"]
+  dev.CaCertificates = []string{"/certs/dev.pem", "/certs/root.pem"}
+  appSettings.Clusters["dev"] = dev
+
+  assert.Equal(t, []string{"/certs/root.pem", "/certs/dev.pem"}, appSettings.GetClusterCaCertificates("dev"))
+  assert.Equal(t, []string{"/certs/root.pem"}, appSettings.GetClusterCaCertificates("prod"))
+}
+
+/*
+      Tests for GetClusterCidrs
+*/
+func TestGetClusterCidrs(t *testing.T) {
+  assert.Equal(t, []string{"10.42.0.0/16", "10.43.0.0/16"}, Cluster{}.GetClusterCidrs())
+
+  cluster := Cluster{ClusterFeatures: &ClusterFeatures{K3sArgs: K3sArgs{
+    ServerArgs: []string{"--service-cidr=10.96.0.0/12", "--cluster-cidr=10.244.0.0/16"},
+  }}}
+  assert.Equal(t, []string{"10.244.0.0/16", "10.96.0.0/12"}, cluster.GetClusterCidrs())
+}
+
+/*
+      Tests for GetNoProxy
+*/
+func TestGetNoProxy(t *testing.T) {
+  cluster := getValidSettings().Clusters["prod"]
+
+  assert.Equal(t, []string{
+    "localhost", "127.0.0.1", ".svc", ".cluster.local",
+    "prod-cp01", "192.168.1.21", "prod-cp02", "192.168.1.22", "prod-w01", "192.168.1.23",
+    "192.168.1.20", "10.42.0.0/16", "10.43.0.0/16", ".corp",
+  }, cluster.GetNoProxy(ProxySettings{NoProxy: []string{".corp", "localhost"}}))
+}
+
+/*
+      Tests for validateNetworkSettings
+*/
+func TestValidateNetworkSettings(t *testing.T) {
+  certsDir := t.TempDir()
+  caFile := filepath.Join(certsDir, "root.pem")
+  notCaFile := filepath.Join(certsDir, "root.txt")
+  assert.NoError(t, os.WriteFile(caFile, []byte(testCaCertificate), 0644))
+  assert.NoError(t, os.WriteFile(notCaFile, []byte("not a certificate"), 0644))
+
+  appSettings := getValidSettings()
+  appSettings.Network = &NetworkSettings{Dns: []string{"10.0.0.2"}}
+  appSettings.Proxy = &ProxySettings{HttpProxy: "http://proxy.corp:3128", NoProxy: []string{".corp", "10.0.0.0/8"}}
+  appSettings.CaCertificates = []string{caFile}
+  assert.Empty(t, appSettings.Validate())
+
+  appSettings.Network = &NetworkSettings{Dns: []string{"dns.corp"}}
+  appSettings.Proxy = &ProxySettings{HttpProxy: "proxy.corp:3128", HttpsProxy: "socks5://proxy.corp:1080",
+    NoProxy: []string{"a.corp,b.corp"}}
+  appSettings.CaCertificates = []string{notCaFile, filepath.Join(certsDir, "missing.pem")}
+
+  dev := appSettings.Clusters["dev"]
+  dev.Network = &NetworkSettings{Dns: []string{"10.0.0.300"}}
+  dev.Proxy = &ProxySettings{NoProxy: []string{""}}
+  appSettings.Clusters["dev"] = dev
+
+  assert.Equal(t, []string{
+    "network.dns[0]",
+    "proxy.httpProxy",
+    "proxy.httpsProxy",
+    "proxy.noProxy[0]",
+    "caCertificates[0]",
+    "caCertificates[1]",
+    "clusters.dev.network.dns[0]",
+    "clusters.dev.proxy.noProxy[0]",
+  }, validationPaths(appSettings.Validate()))
+}
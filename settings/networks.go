@@ -0,0 +1,172 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+/*
+  Network - An additional network interface for a machine, the
+  primary private network on the machine ip is always the first
+  interface and these are added after it in order
+*/
+type Network struct {
+  Name string              `json:"name" yaml:"name"`                                         // The name of the network (ex storage), the same network has the same name on every machine
+  Type string              `json:"type,omitempty" yaml:"type,omitempty"`                     // (private or bridged) the type of network, default is private
+  IpAddress string         `json:"ipAddress,omitempty" yaml:"ip,omitempty"`                  // The static ip for the interface (if empty dhcp is used)
+  Netmask string           `json:"netmask,omitempty" yaml:"netmask,omitempty"`               // The netmask for the static ip, default is 255.255.255.0
+  Bridge string            `json:"bridge,omitempty" yaml:"bridge,omitempty"`                 // The host interface to bridge to, bridged networks only
+  ProviderNetwork string   `json:"providerNetwork,omitempty" yaml:"provider_network,omitempty"`  // The provider network to attach to (vmnet, libvirt network or virtualbox host only network), private networks only
+}
+
+var supportedNetworkTypes = []string{"private", "bridged"}
+
+const defaultNetmask = "255.255.255.0"
+
+// network names are referenced from the cluster features
+var networkNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+/*
+GetType()
+Gets the network type, private if no type is set
+*/
+func (network Network) GetType() string {
+  if network.Type == "" {
+    return "private"
+  }
+  return network.Type
+}
+
+/*
+GetNetmask()
+Gets the netmask, the default netmask if none is set
+*/
+func (network Network) GetNetmask() string {
+  if network.Netmask == "" {
+    return defaultNetmask
+  }
+  return network.Netmask
+}
+
+/*
+GetCidr()
+Gets the subnet of the network in cidr notation (ex 192.168.20.0/24),
+empty if the network has no static ip
+*/
+func (network Network) GetCidr() string {
+  ip := net.ParseIP(network.IpAddress).To4()
+  mask := net.IPMask(net.ParseIP(network.GetNetmask()).To4())
+  if ip == nil || mask == nil {
+    return ""
+  }
+
+  subnet := net.IPNet{IP: ip.Mask(mask), Mask: mask}
+  return subnet.String()
+}
+
+/*
+GetNetwork()
+Gets a network of the machine by name
+*/
+func (machine Machine) GetNetwork(name string) (Network, bool) {
+  for _, network := range machine.Networks {
+    if network.Name == name {
+      return network, true
+    }
+  }
+  return Network{}, false
+}
+
+/*
+GetBindNetworkCidr()
+Gets the subnet of the network k3s binds to in cidr notation,
+empty if k3s uses the primary network. The subnet is taken from
+the lead machine
+*/
+func (cluster Cluster) GetBindNetworkCidr() string {
+  if cluster.ClusterFeatures == nil || cluster.ClusterFeatures.BindNetwork == "" || len(cluster.Leaders) == 0 {
+    return ""
+  }
+
+  network, exists := cluster.Leaders[0].GetNetwork(cluster.ClusterFeatures.BindNetwork)
+  if !exists {
+    return ""
+  }
+  return network.GetCidr()
+}
+
+// validates the additional networks of a machine, ips are tracked
+// with the machine ips to find duplicates
+func (validator *settingsValidator) validateNetworks(machinePath string, networks []Network, ipAddresses map[string]string) {
+  names := make(map[string]string)
+
+  for index, network := range networks {
+    path := fmt.Sprintf("%s.networks[%d]", machinePath, index)
+
+    if !networkNameRegex.MatchString(network.Name) {
+      validator.add(path + ".name", "malformed network name %q, expected lowercase letters, numbers and dashes", network.Name)
+    } else if otherPath, exists := names[network.Name]; exists {
+      validator.add(path + ".name", "network name %q is already used by %s", network.Name, otherPath)
+    } else {
+      names[network.Name] = path
+    }
+
+    if !contains(supportedNetworkTypes, network.GetType()) {
+      validator.add(path + ".type", "unsupported network type %q, must be one of %s",
+        network.Type, strings.Join(supportedNetworkTypes, ", "))
+    }
+
+    if network.IpAddress != "" {
+      if net.ParseIP(network.IpAddress).To4() == nil {
+        validator.add(path + ".ipAddress", "%q is not a valid ipv4 address", network.IpAddress)
+      } else if otherPath, exists := ipAddresses[network.IpAddress]; exists {
+        validator.add(path + ".ipAddress", "ip address %s is already used by %s", network.IpAddress, otherPath)
+      } else {
+        ipAddresses[network.IpAddress] = path
+      }
+    }
+
+    if network.Netmask != "" {
+      if network.IpAddress == "" {
+        validator.add(path + ".netmask", "netmask is only used with a static ip")
+      } else if mask := net.ParseIP(network.Netmask).To4(); mask == nil {
+        validator.add(path + ".netmask", "%q is not a valid netmask", network.Netmask)
+      } else if ones, bits := net.IPMask(mask).Size(); ones == 0 && bits == 0 {
+        validator.add(path + ".netmask", "%q is not a valid netmask", network.Netmask)
+      }
+    }
+
+    if network.Bridge != "" && network.GetType() != "bridged" {
+      validator.add(path + ".bridge", "bridge is only used with bridged networks")
+    }
+
+    if network.ProviderNetwork != "" && network.GetType() != "private" {
+      validator.add(path + ".providerNetwork", "provider network is only used with private networks")
+    }
+  }
+}
+
+// validates every machine in the cluster has a static ip on the
+// network k3s binds to and the ips are all in the same subnet
+func (validator *settingsValidator) validateBindNetwork(path string, cluster Cluster, features ClusterFeatures) {
+  if features.BindNetwork == "" {
+    return
+  }
+
+  cidr := cluster.GetBindNetworkCidr()
+  _, subnet, _ := net.ParseCIDR(cidr)
+
+  machines := append(append([]Machine{}, cluster.Leaders...), cluster.Workers...)
+  for _, machine := range machines {
+    network, exists := machine.GetNetwork(features.BindNetwork)
+    if !exists {
+      validator.add(path + ".bindNetwork", "machine %s has no network %q", machine.Name, features.BindNetwork)
+    } else if network.IpAddress == "" {
+      validator.add(path + ".bindNetwork", "network %q on machine %s needs a static ip for k3s to bind to", features.BindNetwork, machine.Name)
+    } else if subnet != nil && !subnet.Contains(net.ParseIP(network.IpAddress)) {
+      validator.add(path + ".bindNetwork", "network %q on machine %s is not in the subnet %s", features.BindNetwork, machine.Name, cidr)
+    }
+  }
+}
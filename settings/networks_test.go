@@ -0,0 +1,100 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for GetCidr
+*/
+func TestNetworkGetCidr(t *testing.T) {
+  assert.Equal(t, "192.168.20.0/24", Network{IpAddress: "192.168.20.10"}.GetCidr())
+  assert.Equal(t, "10.10.0.0/16", Network{IpAddress: "10.10.4.2", Netmask: "255.255.0.0"}.GetCidr())
+  assert.Equal(t, "", Network{}.GetCidr())
+}
+
+/*
+      Tests for GetBindNetworkCidr
+*/
+func TestGetBindNetworkCidr(t *testing.T) {
+  cluster := Cluster{
+    Leaders: []Machine{
+      {Name: "dev", Networks: []Network{{Name: "cluster", IpAddress: "192.168.20.10"}}},
+    },
+    ClusterFeatures: &ClusterFeatures{BindNetwork: "cluster"},
+  }
+  assert.Equal(t, "192.168.20.0/24", cluster.GetBindNetworkCidr())
+
+  cluster.ClusterFeatures.BindNetwork = "storage"
+  assert.Equal(t, "", cluster.GetBindNetworkCidr())
+
+  cluster.ClusterFeatures = nil
+  assert.Equal(t, "", cluster.GetBindNetworkCidr())
+}
+
+/*
+      Tests for validateNetworks
+*/
+func TestValidateNetworks(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.Workers[0].Networks = []Network{
+    {Name: "storage", IpAddress: "192.168.20.23", Netmask: "255.255.255.0", ProviderNetwork: "vmnet3"},
+    {Name: "lan", Type: "bridged", Bridge: "en0"},
+  }
+  appSettings.Clusters["prod"] = prod
+  assert.Empty(t, appSettings.Validate())
+
+  prod.Workers[0].Networks = []Network{
+    {Name: "Storage", IpAddress: "192.168.1.21"},
+    {Name: "lan", Type: "nat", Netmask: "255.255.255.0"},
+    {Name: "lan", IpAddress: "192.168.20.23", Netmask: "255.0.255.0", Bridge: "en0"},
+    {Name: "wifi", Type: "bridged", ProviderNetwork: "vmnet3"},
+  }
+  appSettings.Clusters["prod"] = prod
+
+  assert.Equal(t, []string{
+    "clusters.prod.workers[0].networks[0].name",
+    "clusters.prod.workers[0].networks[0].ipAddress",
+    "clusters.prod.workers[0].networks[1].type",
+    "clusters.prod.workers[0].networks[1].netmask",
+    "clusters.prod.workers[0].networks[2].name",
+    "clusters.prod.workers[0].networks[2].netmask",
+    "clusters.prod.workers[0].networks[2].bridge",
+    "clusters.prod.workers[0].networks[3].providerNetwork",
+  }, validationPaths(appSettings.Validate()))
+}
+
+/*
+      Tests for validateBindNetwork
+*/
+func TestValidateBindNetwork(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.ClusterFeatures.BindNetwork = "cluster"
+  prod.Leaders[0].Networks = []Network{{Name: "cluster", IpAddress: "192.168.20.21"}}
+  prod.Leaders[1].Networks = []Network{{Name: "cluster", IpAddress: "192.168.20.22"}}
+  prod.Workers[0].Networks = []Network{{Name: "cluster", IpAddress: "192.168.20.23"}}
+  appSettings.Clusters["prod"] = prod
+  assert.Empty(t, appSettings.Validate())
+
+  prod.Leaders[1].Networks = []Network{{Name: "cluster"}}
+  prod.Workers[0].Networks = []Network{{Name: "cluster", IpAddress: "192.168.30.23"}}
+  appSettings.Clusters["prod"] = prod
+  assert.Equal(t, []string{
+    "clusters.prod.clusterFeatures.bindNetwork",
+    "clusters.prod.clusterFeatures.bindNetwork",
+  }, validationPaths(appSettings.Validate()))
+
+  // every machine needs the network
+  prod.Leaders[1].Networks = nil
+  prod.Workers[0].Networks = []Network{{Name: "cluster", IpAddress: "192.168.20.23"}}
+  appSettings.Clusters["prod"] = prod
+  assert.Equal(t, []string{
+    "clusters.prod.clusterFeatures.bindNetwork",
+  }, validationPaths(appSettings.Validate()))
+}
@@ -0,0 +1,380 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+)
+
+/*
+  settingsPathSegment - One part of a settings path, a
+  field/map key with an optional list index ex leaders[0]
+*/
+type settingsPathSegment struct {
+  name string          // the json field name or map key
+  index int            // the list index, -1 if there is none
+}
+
+// a path segment with an optional list index
+var pathSegmentRegex = regexp.MustCompile(`^([^\[\]]+)(\[([0-9]+)\])?$`)
+
+/*
+parses a settings path made of json field names and map
+keys ex clusters.dev.leaders[0].memory
+*/
+func parseSettingsPath(path string) ([]settingsPathSegment, error) {
+  segments := []settingsPathSegment{}
+
+  if path == "" {
+    return nil, fmt.Errorf("settings path can not be empty")
+  }
+
+  for _, part := range strings.Split(path, ".") {
+    match := pathSegmentRegex.FindStringSubmatch(part)
+    if match == nil {
+      return nil, fmt.Errorf("malformed settings path %q at %q", path, part)
+    }
+
+    segment := settingsPathSegment{name: match[1], index: -1}
+    if match[3] != "" {
+      segment.index, _ = strconv.Atoi(match[3])
+    }
+    segments = append(segments, segment)
+  }
+  return segments, nil
+}
+
+/*
+GetValue()
+Gets the value at a settings path ex clusters.dev.clusterFeatures.kubeVersion
+*/
+func (settings *Settings) GetValue(path string) (interface{}, error) {
+  segments, err := parseSettingsPath(path)
+  if err != nil {
+    return nil, err
+  }
+
+  current := reflect.ValueOf(*settings)
+  for _, segment := range segments {
+    current, err = getPathSegment(current, segment)
+    if err != nil {
+      logger.LogError("Error getting settings path", "path", path)
+      return nil, fmt.Errorf("%s: %w", path, err)
+    }
+  }
+  return current.Interface(), nil
+}
+
+/*
+SetValue()
+Sets the value at a settings path, the value is converted to the
+type of the setting. Strings are used as is, numbers and bools
+are parsed and anything else (lists, maps and objects) is json.
+Missing map entries are created
+*/
+func (settings *Settings) SetValue(path string, value string) error {
+  segments, err := parseSettingsPath(path)
+  if err != nil {
+    return err
+  }
+
+  updated, err := setPathSegments(reflect.ValueOf(*settings), segments, value)
+  if err != nil {
+    logger.LogError("Error setting settings path", "path", path)
+    return fmt.Errorf("%s: %w", path, err)
+  }
+
+  clusterFiles := settings.clusterFiles
+  *settings = updated.Interface().(Settings)
+  settings.clusterFiles = clusterFiles
+  return nil
+}
+
+/*
+SetSettingsFileValue()
+Sets a value in the settings files, the part of the settings
+the value is in (ex the cluster) is validated before anything is
+written. Only the file the value lives in is backed up (to .bak)
+and then written atomically
+*/
+func SetSettingsFileValue(appDir string, path string, value string) error {
+  settings, err := ReadSettingsFile(appDir)
+  if err != nil {
+    return err
+  }
+
+  err = settings.SetValue(path, value)
+  if err != nil {
+    return err
+  }
+
+  segments, _ := parseSettingsPath(path)
+  validationErrors := settings.validateSettingsSection(segments)
+  if len(validationErrors) > 0 {
+    logger.LogError("Error updated settings are not valid")
+    return validationErrors
+  }
+
+  // the value either lives in a cluster file or in settings.json
+  clusterName := ""
+  settingsFile := filepath.Join(appDir, "settings.json")
+  if len(segments) > 1 && segments[0].name == "clusters" && settings.IsClusterFile(segments[1].name) {
+    clusterName = segments[1].name
+    settingsFile = GetClusterFilePath(appDir, clusterName)
+  }
+
+  original, err := os.ReadFile(settingsFile)
+  if err != nil {
+    logger.LogError("Error reading file to back up", "file", settingsFile)
+    return err
+  }
+
+  logger.LogInfo("Backing up settings before saving", "backup", settingsFile + ".bak")
+  err = os.WriteFile(settingsFile + ".bak", original, 0644)
+  if err != nil {
+    logger.LogError("Error backing up settings")
+    return err
+  }
+
+  if clusterName != "" {
+    return writeClusterFile(appDir, clusterName, settings.Clusters[clusterName])
+  }
+  return writeSettingsJson(appDir, settings)
+}
+
+// validates only the part of the settings a path is in, so
+// existing problems in other clusters or providers don't block
+// a change. Paths that cover several parts validate everything
+func (settings *Settings) validateSettingsSection(segments []settingsPathSegment) ValidationErrors {
+  validator := settingsValidator{}
+
+  switch {
+  case len(segments) > 1 && segments[0].name == "clusters":
+    return settings.ValidateCluster(segments[1].name)
+
+  case len(segments) > 1 && segments[0].name == "providers":
+    validator.validateProvider(segments[1].name, settings.Providers[segments[1].name])
+
+  case segments[0].name == "provision":
+    validator.validateProvisionSettings(settings.ProvisionSettings)
+
+  case segments[0].name == "registries":
+    validator.validateRegistries("registries", settings.Registries)
+
+  case segments[0].name == "network" || segments[0].name == "proxy" || segments[0].name == "caCertificates":
+    validator.validateNetworkSettings("network", settings.Network, "proxy", settings.Proxy,
+      "caCertificates", settings.CaCertificates)
+
+  case segments[0].name == "kubeconfigPath":
+    // nothing in the settings validates the kubeconfig path
+
+  default:
+    return settings.Validate()
+  }
+  return validator.errors
+}
+
+// gets the value of one path segment from a struct or map
+func getPathSegment(current reflect.Value, segment settingsPathSegment) (reflect.Value, error) {
+  for current.Kind() == reflect.Ptr {
+    if current.IsNil() {
+      return reflect.Value{}, fmt.Errorf("%s is not set", segment.name)
+    }
+    current = current.Elem()
+  }
+
+  var next reflect.Value
+  switch current.Kind() {
+  case reflect.Struct:
+    fieldIndex, exists := jsonFieldIndex(current.Type(), segment.name)
+    if !exists {
+      return reflect.Value{}, fmt.Errorf("unknown setting %q", segment.name)
+    }
+    next = current.FieldByIndex(fieldIndex)
+
+  case reflect.Map:
+    next = current.MapIndex(reflect.ValueOf(segment.name))
+    if !next.IsValid() {
+      return reflect.Value{}, fmt.Errorf("%q is not present", segment.name)
+    }
+
+  default:
+    return reflect.Value{}, fmt.Errorf("%q is not an object", segment.name)
+  }
+
+  if segment.index < 0 {
+    return next, nil
+  }
+
+  if next.Kind() != reflect.Slice {
+    return reflect.Value{}, fmt.Errorf("%s is not a list", segment.name)
+  }
+
+  if segment.index >= next.Len() {
+    return reflect.Value{}, fmt.Errorf("index %d is out of range for %s (length %d)", segment.index, segment.name, next.Len())
+  }
+  return next.Index(segment.index), nil
+}
+
+/*
+sets the value at the path below current, values in maps are not
+addressable so an updated copy of current is returned
+*/
+func setPathSegments(current reflect.Value, segments []settingsPathSegment, value string) (reflect.Value, error) {
+  if len(segments) == 0 {
+    return parseSettingValue(current.Type(), value)
+  }
+
+  // work on an addressable copy
+  updated := reflect.New(current.Type()).Elem()
+  updated.Set(current)
+
+  if updated.Kind() == reflect.Ptr {
+    elem := reflect.New(updated.Type().Elem()).Elem()
+    if !updated.IsNil() {
+      elem.Set(updated.Elem())
+    }
+
+    updatedElem, err := setPathSegments(elem, segments, value)
+    if err != nil {
+      return reflect.Value{}, err
+    }
+
+    pointer := reflect.New(updated.Type().Elem())
+    pointer.Elem().Set(updatedElem)
+    return pointer, nil
+  }
+
+  segment := segments[0]
+
+  var child reflect.Value
+  var store func(reflect.Value)
+
+  switch updated.Kind() {
+  case reflect.Struct:
+    fieldIndex, exists := jsonFieldIndex(updated.Type(), segment.name)
+    if !exists {
+      return reflect.Value{}, fmt.Errorf("unknown setting %q", segment.name)
+    }
+    child = updated.FieldByIndex(fieldIndex)
+    store = func(value reflect.Value) { updated.FieldByIndex(fieldIndex).Set(value) }
+
+  case reflect.Map:
+    if updated.Type().Key().Kind() != reflect.String {
+      return reflect.Value{}, fmt.Errorf("%q is not an object", segment.name)
+    }
+
+    key := reflect.ValueOf(segment.name)
+    child = updated.MapIndex(key)
+    if !child.IsValid() {
+      logger.LogDebug("Creating new map entry", "key", segment.name)
+      child = reflect.Zero(updated.Type().Elem())
+    }
+
+    if updated.IsNil() {
+      updated.Set(reflect.MakeMap(updated.Type()))
+    }
+    store = func(value reflect.Value) { updated.SetMapIndex(key, value) }
+
+  default:
+    return reflect.Value{}, fmt.Errorf("%q is not an object", segment.name)
+  }
+
+  if segment.index >= 0 {
+    if child.Kind() != reflect.Slice {
+      return reflect.Value{}, fmt.Errorf("%s is not a list", segment.name)
+    }
+
+    if segment.index >= child.Len() {
+      return reflect.Value{}, fmt.Errorf("index %d is out of range for %s (length %d)", segment.index, segment.name, child.Len())
+    }
+
+    // copy the list so the original settings are not changed
+    list := reflect.MakeSlice(child.Type(), child.Len(), child.Len())
+    reflect.Copy(list, child)
+
+    item, err := setPathSegments(list.Index(segment.index), segments[1:], value)
+    if err != nil {
+      return reflect.Value{}, err
+    }
+    list.Index(segment.index).Set(item)
+    store(list)
+    return updated, nil
+  }
+
+  updatedChild, err := setPathSegments(child, segments[1:], value)
+  if err != nil {
+    return reflect.Value{}, err
+  }
+  store(updatedChild)
+  return updated, nil
+}
+
+// converts a value from the command line to the type of a setting
+func parseSettingValue(valueType reflect.Type, value string) (reflect.Value, error) {
+  parsed := reflect.New(valueType).Elem()
+
+  switch valueType.Kind() {
+  case reflect.String:
+    parsed.SetString(value)
+
+  case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+    number, err := strconv.ParseInt(value, 10, 64)
+    if err != nil {
+      return reflect.Value{}, fmt.Errorf("%q is not a whole number", value)
+    }
+    parsed.SetInt(number)
+
+  case reflect.Bool:
+    boolean, err := strconv.ParseBool(value)
+    if err != nil {
+      return reflect.Value{}, fmt.Errorf("%q is not true or false", value)
+    }
+    parsed.SetBool(boolean)
+
+  default:
+    decoder := json.NewDecoder(strings.NewReader(value))
+    decoder.DisallowUnknownFields()
+
+    err := decoder.Decode(parsed.Addr().Interface())
+    if err != nil {
+      return reflect.Value{}, fmt.Errorf("value is not valid json for %s: %w", valueType, err)
+    }
+  }
+  return parsed, nil
+}
+
+// finds the struct field with a json name, fields of embedded
+// structs are found the way encoding/json flattens them
+func jsonFieldIndex(structType reflect.Type, name string) ([]int, bool) {
+  for index := 0; index < structType.NumField(); index++ {
+    field := structType.Field(index)
+    if !field.IsExported() {
+      continue
+    }
+
+    tagName := strings.Split(field.Tag.Get("json"), ",")[0]
+    if tagName == "-" {
+      continue
+    }
+
+    if field.Anonymous && tagName == "" && field.Type.Kind() == reflect.Struct {
+      if embeddedIndex, exists := jsonFieldIndex(field.Type, name); exists {
+        return append([]int{index}, embeddedIndex...), true
+      }
+      continue
+    }
+
+    if tagName == name || (tagName == "" && field.Name == name) {
+      return []int{index}, true
+    }
+  }
+  return nil, false
+}
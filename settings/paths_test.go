@@ -0,0 +1,221 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/util"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for parseSettingsPath
+*/
+func TestParseSettingsPath(t *testing.T) {
+  segments, err := parseSettingsPath("clusters.dev.leaders[1].memory")
+  assert.NoError(t, err)
+  assert.Equal(t, []settingsPathSegment{
+    {name: "clusters", index: -1},
+    {name: "dev", index: -1},
+    {name: "leaders", index: 1},
+    {name: "memory", index: -1},
+  }, segments)
+
+  _, err = parseSettingsPath("clusters..dev")
+  assert.Error(t, err)
+
+  _, err = parseSettingsPath("leaders[x]")
+  assert.Error(t, err)
+}
+
+/*
+      Tests for GetValue
+*/
+func TestGetValue(t *testing.T) {
+  settings := getValidSettings()
+
+  value, err := settings.GetValue("clusters.prod.leaders[1].ipAddress")
+  assert.NoError(t, err)
+  assert.Equal(t, "192.168.1.22", value)
+
+  value, err = settings.GetValue("clusters.prod.clusterFeatures.kubeVipEnable")
+  assert.NoError(t, err)
+  assert.Equal(t, true, value)
+
+  value, err = settings.GetValue("providers.vmware")
+  assert.NoError(t, err)
+  assert.Equal(t, settings.Providers["vmware"], value)
+
+  _, err = settings.GetValue("clusters.missing.vip")
+  assert.Error(t, err)
+
+  _, err = settings.GetValue("clusters.dev.clusterFeatures.kubeVersion")
+  assert.ErrorContains(t, err, "not set")
+
+  _, err = settings.GetValue("clusters.prod.leaders[5]")
+  assert.ErrorContains(t, err, "out of range")
+
+  _, err = settings.GetValue("clusters.prod.notAField")
+  assert.ErrorContains(t, err, "unknown setting")
+}
+
+/*
+      Tests for SetValue
+*/
+func TestSetValue(t *testing.T) {
+  settings := getValidSettings()
+  original := getValidSettings()
+
+  err := settings.SetValue("clusters.prod.leaders[0].memory", "8192")
+  assert.NoError(t, err)
+  assert.Equal(t, 8192, settings.Clusters["prod"].Leaders[0].Memory)
+
+  err = settings.SetValue("clusters.dev.clusterFeatures.kubeVersion", "1.30.0")
+  assert.NoError(t, err)
+  assert.Equal(t, "1.30.0", settings.Clusters["dev"].ClusterFeatures.KubeVersion)
+
+  err = settings.SetValue("clusters.prod.clusterFeatures.disableDefaultMetrics", "true")
+  assert.NoError(t, err)
+  assert.True(t, settings.Clusters["prod"].ClusterFeatures.DisableDefaultMetrics)
+  assert.Equal(t, "cilium", settings.Clusters["prod"].ClusterFeatures.CniController)
+
+  err = settings.SetValue("clusters.prod.workers", `[{"name": "prod-w09", "ipAddress": "192.168.1.29"}]`)
+  assert.NoError(t, err)
+  assert.Equal(t, "prod-w09", settings.Clusters["prod"].Workers[0].Name)
+
+  err = settings.SetValue("providers.libvirt.boxName", "generic/ubuntu2204")
+  assert.NoError(t, err)
+  assert.Equal(t, "generic/ubuntu2204", settings.Providers["libvirt"].BoxName)
+
+  // other settings are not changed
+  assert.Equal(t, original.Clusters["dev"].Leaders, settings.Clusters["dev"].Leaders)
+}
+
+func TestSetValueTypeErrors(t *testing.T) {
+  settings := getValidSettings()
+
+  err := settings.SetValue("clusters.prod.leaders[0].memory", "lots")
+  assert.ErrorContains(t, err, "whole number")
+
+  err = settings.SetValue("clusters.prod.clusterFeatures.kubeVipEnable", "maybe")
+  assert.ErrorContains(t, err, "true or false")
+
+  err = settings.SetValue("clusters.prod.workers", `[{"nmae": "typo"}]`)
+  assert.ErrorContains(t, err, "valid json")
+
+  err = settings.SetValue("clusters.prod.vip.other", "1")
+  assert.ErrorContains(t, err, "not an object")
+}
+
+/*
+      Tests for SetSettingsFileValue
+*/
+func TestSetSettingsFileValue(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  settings := getValidSettings()
+  settings.SchemaVersion = CurrentSchemaVersion
+  err = WriteSettingsFile(util.MockAppDir, settings)
+  assert.NoError(t, err)
+
+  settingsFile := filepath.Join(util.MockAppDir, "settings.json")
+  original, err := os.ReadFile(settingsFile)
+  assert.NoError(t, err)
+
+  err = SetSettingsFileValue(util.MockAppDir, "clusters.dev.leaders[0].cpus", "4")
+  assert.NoError(t, err)
+
+  updated, err := ReadSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+  assert.Equal(t, 4, updated.Clusters["dev"].Leaders[0].Cpu)
+
+  backup, err := os.ReadFile(settingsFile + ".bak")
+  assert.NoError(t, err)
+  assert.Equal(t, original, backup)
+}
+
+func TestSetSettingsFileValueInvalid(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  settings := getValidSettings()
+  settings.SchemaVersion = CurrentSchemaVersion
+  err = WriteSettingsFile(util.MockAppDir, settings)
+  assert.NoError(t, err)
+
+  err = SetSettingsFileValue(util.MockAppDir, "clusters.dev.clusterType", "huge")
+  var validationErrors ValidationErrors
+  assert.ErrorAs(t, err, &validationErrors)
+  assert.Contains(t, validationPaths(validationErrors), "clusters.dev.clusterType")
+
+  unchanged, err := ReadSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+  assert.Equal(t, "single", unchanged.Clusters["dev"].ClusterType)
+  assert.NoFileExists(t, filepath.Join(util.MockAppDir, "settings.json.bak"))
+}
+
+func TestSetSettingsFileValueOtherClusterInvalid(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  // prod syncs a host path that does not exist on this host
+  settings := getValidSettings()
+  settings.SchemaVersion = CurrentSchemaVersion
+  prod := settings.Clusters["prod"]
+  prod.SyncedFolders = []SyncedFolder{{HostPath: "/does/not/exist", GuestPath: "/src/app"}}
+  settings.Clusters["prod"] = prod
+  err = WriteSettingsFile(util.MockAppDir, settings)
+  assert.NoError(t, err)
+
+  // only the changed cluster is validated
+  err = SetSettingsFileValue(util.MockAppDir, "clusters.dev.leaders[0].cpus", "4")
+  assert.NoError(t, err)
+
+  err = SetSettingsFileValue(util.MockAppDir, "clusters.prod.leaders[0].cpus", "4")
+  var validationErrors ValidationErrors
+  assert.ErrorAs(t, err, &validationErrors)
+  assert.Equal(t, []string{"clusters.prod.syncedFolders[0].hostPath"}, validationPaths(validationErrors))
+}
+
+func TestSetSettingsFileValueClusterFile(t *testing.T) {
+  err := util.MockAppDirSetup()
+  assert.NoError(t, err)
+
+  defer util.MockAppDirCleanup()
+
+  writeClusterFilesFixture(t, map[string]string{
+    "staging": `{"clusterType": "single", "providerName": "vmware", "leaders": [` +
+      `{"name": "staging", "ipAddress": "192.168.1.30", "memory": 2048, "cpus": 2, "diskSize": "30GB"}]}`,
+    "other": `{"schemaVersion": 1, "clusterType": "single"}`,
+  })
+
+  settingsFile := filepath.Join(util.MockAppDir, "settings.json")
+  original, err := os.ReadFile(settingsFile)
+  assert.NoError(t, err)
+
+  err = SetSettingsFileValue(util.MockAppDir, "clusters.staging.leaders[0].cpus", "4")
+  assert.NoError(t, err)
+
+  updated, err := ReadSettingsFile(util.MockAppDir)
+  assert.NoError(t, err)
+  assert.Equal(t, 4, updated.Clusters["staging"].Leaders[0].Cpu)
+  assert.FileExists(t, GetClusterFilePath(util.MockAppDir, "staging") + ".bak")
+
+  // only the cluster file is written and backed up
+  content, err := os.ReadFile(settingsFile)
+  assert.NoError(t, err)
+  assert.Equal(t, original, content)
+  assert.NoFileExists(t, settingsFile + ".bak")
+
+  content, err = os.ReadFile(GetClusterFilePath(util.MockAppDir, "other"))
+  assert.NoError(t, err)
+  assert.Equal(t, `{"schemaVersion": 1, "clusterType": "single"}`, string(content))
+}
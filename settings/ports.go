@@ -0,0 +1,169 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+/*
+  ForwardedPort - A port forwarded from the host to a machine, for
+  hosts that can't reach the private network (ex over a vpn)
+*/
+type ForwardedPort struct {
+  Guest int          `json:"guest" yaml:"guest"`                            // The port on the machine
+  Host int           `json:"host" yaml:"host"`                              // The port on the host
+  Protocol string    `json:"protocol,omitempty" yaml:"protocol,omitempty"`  // (tcp or udp) the protocol, default is tcp
+  HostIp string      `json:"hostIp,omitempty" yaml:"host_ip,omitempty"`     // The host ip to listen on, default is 127.0.0.1
+}
+
+/*
+  ClusterForwardedPorts - Host ports for the cluster api server
+  and ingress, these are forwarded from the lead machine
+*/
+type ClusterForwardedPorts struct {
+  ApiServer int    `json:"apiServer,omitempty"`   // The host port for the api server (6443)
+  Http int         `json:"http,omitempty"`        // The host port for ingress http (80)
+  Https int        `json:"https,omitempty"`       // The host port for ingress https (443)
+}
+
+var supportedPortProtocols = []string{"tcp", "udp"}
+
+const defaultForwardHostIp = "127.0.0.1"
+
+// the machine ports for the cluster forwarded ports
+const apiServerPort = 6443
+const ingressHttpPort = 80
+const ingressHttpsPort = 443
+
+/*
+GetProtocol()
+Gets the protocol, tcp if no protocol is set
+*/
+func (port ForwardedPort) GetProtocol() string {
+  if port.Protocol == "" {
+    return "tcp"
+  }
+  return port.Protocol
+}
+
+/*
+GetHostIp()
+Gets the host ip, 127.0.0.1 if no host ip is set
+*/
+func (port ForwardedPort) GetHostIp() string {
+  if port.HostIp == "" {
+    return defaultForwardHostIp
+  }
+  return port.HostIp
+}
+
+/*
+GetForwardedPorts()
+Gets the cluster forwarded ports as ports forwarded
+from the lead machine
+*/
+func (ports ClusterForwardedPorts) GetForwardedPorts() []ForwardedPort {
+  forwardedPorts := []ForwardedPort{}
+
+  if ports.ApiServer != 0 {
+    forwardedPorts = append(forwardedPorts, ForwardedPort{Guest: apiServerPort, Host: ports.ApiServer})
+  }
+  if ports.Http != 0 {
+    forwardedPorts = append(forwardedPorts, ForwardedPort{Guest: ingressHttpPort, Host: ports.Http})
+  }
+  if ports.Https != 0 {
+    forwardedPorts = append(forwardedPorts, ForwardedPort{Guest: ingressHttpsPort, Host: ports.Https})
+  }
+  return forwardedPorts
+}
+
+/*
+WithForwardedPorts()
+Gets the cluster with the cluster forwarded ports added
+to the ports of the lead machine
+*/
+func (cluster Cluster) WithForwardedPorts() Cluster {
+  if cluster.ForwardedPorts == nil || len(cluster.Leaders) == 0 {
+    return cluster
+  }
+
+  leaders := append([]Machine{}, cluster.Leaders...)
+  leaders[0].ForwardedPorts = append(append([]ForwardedPort{}, leaders[0].ForwardedPorts...),
+    cluster.ForwardedPorts.GetForwardedPorts()...)
+  cluster.Leaders = leaders
+  return cluster
+}
+
+/*
+GetPortKey()
+Gets the key used to find host port conflicts (ex 8443/tcp)
+*/
+func (port ForwardedPort) GetPortKey() string {
+  return fmt.Sprintf("%d/%s", port.Host, port.GetProtocol())
+}
+
+// validates the forwarded ports of the machines and the cluster, host
+// ports can only be used once in a cluster
+func (validator *settingsValidator) validateForwardedPorts(clusterPath string, cluster Cluster) {
+  hostPorts := make(map[string]string)
+
+  checkHostPort := func(path string, port ForwardedPort) {
+    if port.Host < 1 || port.Host > 65535 {
+      validator.add(path, "host port %d must be between 1 and 65535", port.Host)
+    } else if otherPath, exists := hostPorts[port.GetPortKey()]; exists {
+      validator.add(path, "host port %s is already used by %s", port.GetPortKey(), otherPath)
+    } else {
+      hostPorts[port.GetPortKey()] = path
+    }
+  }
+
+  if cluster.ForwardedPorts != nil {
+    path := clusterPath + ".forwardedPorts"
+    fields := []struct {
+      name string
+      port int
+    }{
+      {"apiServer", cluster.ForwardedPorts.ApiServer},
+      {"http", cluster.ForwardedPorts.Http},
+      {"https", cluster.ForwardedPorts.Https},
+    }
+
+    for _, field := range fields {
+      if field.port != 0 {
+        checkHostPort(path + "." + field.name, ForwardedPort{Host: field.port})
+      }
+    }
+  }
+
+  machineGroups := []struct {
+    name string
+    machines []Machine
+  }{
+    {"leaders", cluster.Leaders},
+    {"workers", cluster.Workers},
+  }
+
+  for _, group := range machineGroups {
+    for machineIndex, machine := range group.machines {
+      for index, port := range machine.ForwardedPorts {
+        path := fmt.Sprintf("%s.%s[%d].forwardedPorts[%d]", clusterPath, group.name, machineIndex, index)
+
+        if port.Guest < 1 || port.Guest > 65535 {
+          validator.add(path + ".guest", "guest port %d must be between 1 and 65535", port.Guest)
+        }
+
+        checkHostPort(path + ".host", port)
+
+        if !contains(supportedPortProtocols, port.GetProtocol()) {
+          validator.add(path + ".protocol", "unsupported protocol %q, must be one of %s",
+            port.Protocol, strings.Join(supportedPortProtocols, ", "))
+        }
+
+        if port.HostIp != "" && net.ParseIP(port.HostIp) == nil {
+          validator.add(path + ".hostIp", "%q is not a valid ip address", port.HostIp)
+        }
+      }
+    }
+  }
+}
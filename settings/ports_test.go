@@ -0,0 +1,66 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for WithForwardedPorts
+*/
+func TestWithForwardedPorts(t *testing.T) {
+  cluster := Cluster{
+    Leaders: []Machine{
+      {Name: "leader1", ForwardedPorts: []ForwardedPort{{Guest: 22, Host: 2222}}},
+      {Name: "leader2"},
+    },
+    ForwardedPorts: &ClusterForwardedPorts{ApiServer: 16443, Https: 8443},
+  }
+
+  forwarded := cluster.WithForwardedPorts()
+
+  assert.Equal(t, []ForwardedPort{
+    {Guest: 22, Host: 2222},
+    {Guest: 6443, Host: 16443},
+    {Guest: 443, Host: 8443},
+  }, forwarded.Leaders[0].ForwardedPorts)
+  assert.Empty(t, forwarded.Leaders[1].ForwardedPorts)
+
+  // the original cluster is not changed
+  assert.Equal(t, []ForwardedPort{{Guest: 22, Host: 2222}}, cluster.Leaders[0].ForwardedPorts)
+
+  cluster.ForwardedPorts = nil
+  assert.Equal(t, cluster, cluster.WithForwardedPorts())
+}
+
+/*
+      Tests for validateForwardedPorts
+*/
+func TestValidateForwardedPorts(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.ForwardedPorts = &ClusterForwardedPorts{ApiServer: 16443, Http: 8080, Https: 8443}
+  prod.Workers[0].ForwardedPorts = []ForwardedPort{
+    {Guest: 30080, Host: 30080},
+    {Guest: 53, Host: 8443, Protocol: "udp", HostIp: "0.0.0.0"},
+  }
+  appSettings.Clusters["prod"] = prod
+  assert.Empty(t, appSettings.Validate())
+
+  prod.ForwardedPorts = &ClusterForwardedPorts{ApiServer: 16443, Http: 70000}
+  prod.Workers[0].ForwardedPorts = []ForwardedPort{
+    {Guest: 0, Host: 16443},
+    {Guest: 53, Host: 5353, Protocol: "icmp", HostIp: "localhost"},
+  }
+  appSettings.Clusters["prod"] = prod
+
+  assert.Equal(t, []string{
+    "clusters.prod.forwardedPorts.http",
+    "clusters.prod.workers[0].forwardedPorts[0].guest",
+    "clusters.prod.workers[0].forwardedPorts[0].host",
+    "clusters.prod.workers[0].forwardedPorts[1].protocol",
+    "clusters.prod.workers[0].forwardedPorts[1].hostIp",
+  }, validationPaths(appSettings.Validate()))
+}
@@ -0,0 +1,149 @@
+package settings
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/*
+  ProviderFeatures - The things a provider's vagrantfile
+  templates are able to do
+*/
+type ProviderFeatures struct {
+  DiskResize bool       `json:"diskResize"`      // The primary disk can be resized to the machine disk size
+  Snapshots bool        `json:"snapshots"`       // Machines can be snapshotted with vagrant snapshot
+  LinkedClones bool     `json:"linkedClones"`    // Machines can be created as linked clones of the box
+}
+
+/*
+  ProviderDefinition - Everything local-kube knows about a
+  provider type, the templates used to render its vagrantfiles,
+  the settings it needs and the defaults for its settings
+*/
+type ProviderDefinition struct {
+  ProviderType string             `json:"providerType"`     // The provider type used in the settings
+  VagrantProvider string          `json:"vagrantProvider"`  // The name of the provider in vagrant
+  Description string              `json:"description"`      // A short description of the provider
+  Templates map[string]string     `json:"templates"`        // cluster type -> vagrantfile template name
+  RequiredFields []string         `json:"requiredFields"`   // json names of Provider settings that must be set
+  Features ProviderFeatures       `json:"features"`         // The features the provider supports
+  SyncedFolderType string         `json:"syncedFolderType"` // The type the templates use for synced folders without a type
+  Defaults Provider               `json:"defaults"`         // Defaults for blank Provider settings
+}
+
+// all the provider types that are supported
+var providerRegistry = map[string]ProviderDefinition{
+  "vmware-desktop": {
+    ProviderType: "vmware-desktop",
+    VagrantProvider: "vmware_desktop",
+    Description: "VMware Fusion or Workstation",
+    Templates: map[string]string{
+      "single": "vmware-desktop-single",
+      "ha": "vmware-desktop-ha",
+    },
+    RequiredFields: []string{"boxName", "vmNet"},
+    Features: ProviderFeatures{DiskResize: true, Snapshots: true, LinkedClones: true},
+    Defaults: Provider{BoxName: "bento/ubuntu-24.04"},
+  },
+  "libvirt": {
+    ProviderType: "libvirt",
+    VagrantProvider: "libvirt",
+    Description: "Libvirt/KVM with vagrant-libvirt",
+    Templates: map[string]string{
+      "single": "libvirt-single",
+      "ha": "libvirt-ha",
+    },
+    RequiredFields: []string{"boxName"},
+    Features: ProviderFeatures{DiskResize: true, Snapshots: true},
+    SyncedFolderType: "nfs",
+    Defaults: Provider{BoxName: "generic/ubuntu2404", CpuMode: "host-passthrough"},
+  },
+  "virtualbox": {
+    ProviderType: "virtualbox",
+    VagrantProvider: "virtualbox",
+    Description: "Oracle VirtualBox",
+    Templates: map[string]string{
+      "single": "virtualbox-single",
+      "ha": "virtualbox-ha",
+    },
+    RequiredFields: []string{"boxName"},
+    Features: ProviderFeatures{DiskResize: true, Snapshots: true, LinkedClones: true},
+    Defaults: Provider{BoxName: "bento/ubuntu-24.04", ParavirtProvider: "kvm"},
+  },
+}
+
+/*
+Gets the supported provider types sorted by name
+*/
+func GetProviderTypes() []string {
+  return sortedKeys(providerRegistry)
+}
+
+/*
+Gets the definition for a provider type, the bool is
+false if the provider type is not supported
+*/
+func GetProviderDefinition(providerType string) (ProviderDefinition, bool) {
+  definition, exists := providerRegistry[providerType]
+  return definition, exists
+}
+
+/*
+Gets the definitions of all the supported providers
+sorted by provider type
+*/
+func GetProviderDefinitions() []ProviderDefinition {
+  definitions := []ProviderDefinition{}
+  for _, providerType := range GetProviderTypes() {
+    definitions = append(definitions, providerRegistry[providerType])
+  }
+  return definitions
+}
+
+/*
+Gets the vagrantfile template name for a cluster type
+*/
+func (definition ProviderDefinition) GetTemplateName(clusterType string) (string, error) {
+  templateName, exists := definition.Templates[clusterType]
+  if !exists {
+    return "", fmt.Errorf("provider type %s does not support %s clusters", definition.ProviderType, clusterType)
+  }
+  return templateName, nil
+}
+
+/*
+Gets the cluster types the provider has templates for
+*/
+func (definition ProviderDefinition) GetClusterTypes() []string {
+  return sortedKeys(definition.Templates)
+}
+
+/*
+WithDefaults()
+Gets the provider with blank settings filled in from the
+defaults of its provider type
+*/
+func (provider Provider) WithDefaults() Provider {
+  definition, exists := GetProviderDefinition(provider.ProviderType)
+  if !exists {
+    return provider
+  }
+
+  defaults := definition.Defaults
+  defaults.ProviderType = provider.ProviderType
+  return mergeValues(reflect.ValueOf(defaults), reflect.ValueOf(provider)).Interface().(Provider)
+}
+
+// gets the required settings that are not set on a provider
+func (definition ProviderDefinition) missingFields(provider Provider) []string {
+  missing := []string{}
+
+  providerValue := reflect.ValueOf(provider)
+  for _, field := range definition.RequiredFields {
+    fieldIndex, exists := jsonFieldIndex(providerValue.Type(), field)
+    if !exists || providerValue.FieldByIndex(fieldIndex).IsZero() {
+      missing = append(missing, field)
+    }
+  }
+  return missing
+}
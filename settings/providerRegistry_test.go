@@ -0,0 +1,132 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for the provider registry
+*/
+func TestGetProviderTypes(t *testing.T) {
+  assert.Equal(t, []string{"libvirt", "virtualbox", "vmware-desktop"}, GetProviderTypes())
+}
+
+func TestGetProviderDefinition(t *testing.T) {
+  definition, exists := GetProviderDefinition("libvirt")
+  assert.True(t, exists)
+  assert.Equal(t, "libvirt", definition.VagrantProvider)
+  assert.Equal(t, []string{"ha", "single"}, definition.GetClusterTypes())
+
+  templateName, err := definition.GetTemplateName("ha")
+  assert.NoError(t, err)
+  assert.Equal(t, "libvirt-ha", templateName)
+
+  _, err = definition.GetTemplateName("multi")
+  assert.Error(t, err)
+
+  _, exists = GetProviderDefinition("hyperv")
+  assert.False(t, exists)
+}
+
+func TestGetProviderDefinitionFeatures(t *testing.T) {
+  expected := map[string]ProviderFeatures{
+    "libvirt": {DiskResize: true, Snapshots: true},
+    "virtualbox": {DiskResize: true, Snapshots: true, LinkedClones: true},
+    "vmware-desktop": {DiskResize: true, Snapshots: true, LinkedClones: true},
+  }
+
+  for _, definition := range GetProviderDefinitions() {
+    assert.Equal(t, expected[definition.ProviderType], definition.Features, definition.ProviderType)
+  }
+}
+
+/*
+      Tests for WithDefaults
+*/
+func TestProviderWithDefaults(t *testing.T) {
+  provider := Provider{ProviderType: "libvirt", NetworkName: "local-kube"}.WithDefaults()
+
+  assert.Equal(t, "generic/ubuntu2404", provider.BoxName)
+  assert.Equal(t, "host-passthrough", provider.CpuMode)
+  assert.Equal(t, "local-kube", provider.NetworkName)
+
+  // set values are kept
+  provider = Provider{ProviderType: "libvirt", BoxName: "generic/debian12", CpuMode: "host-model"}.WithDefaults()
+  assert.Equal(t, "generic/debian12", provider.BoxName)
+  assert.Equal(t, "host-model", provider.CpuMode)
+}
+
+func TestProviderWithDefaultsUnknownType(t *testing.T) {
+  provider := Provider{ProviderType: "hyperv"}
+  assert.Equal(t, provider, provider.WithDefaults())
+}
+
+/*
+      Tests for provider validation with the registry
+*/
+func TestValidateProviderRequiredFields(t *testing.T) {
+  appSettings := getValidSettings()
+
+  vmware := appSettings.Providers["vmware"]
+  vmware.BoxName = ""
+  vmware.VmNet = ""
+  appSettings.Providers["vmware"] = vmware
+
+  // the box name has a default but the vmnet does not
+  assert.Equal(t, []string{"providers.vmware.vmNet"}, validationPaths(appSettings.Validate()))
+}
+
+func TestValidateProviderFeatures(t *testing.T) {
+  appSettings := getValidSettings()
+
+  appSettings.Providers["kvm"] = Provider{ProviderType: "libvirt", LinkedClone: true}
+  appSettings.Providers["vbox"] = Provider{ProviderType: "virtualbox", LinkedClone: true}
+
+  vmware := appSettings.Providers["vmware"]
+  vmware.LinkedClone = true
+  appSettings.Providers["vmware"] = vmware
+
+  assert.Equal(t, []string{"providers.kvm.linkedClone"}, validationPaths(appSettings.Validate()))
+}
+
+func TestValidateProviderDiskResize(t *testing.T) {
+  providerRegistry["fixed-disk"] = ProviderDefinition{
+    ProviderType: "fixed-disk",
+    Templates: map[string]string{"single": "fixed-disk-single", "ha": "fixed-disk-ha"},
+  }
+  defer delete(providerRegistry, "fixed-disk")
+
+  appSettings := getValidSettings()
+
+  vmware := appSettings.Providers["vmware"]
+  vmware.ProviderType = "fixed-disk"
+  appSettings.Providers["vmware"] = vmware
+
+  // the disks can not be resized so only the blank disk size is valid
+  appSettings.Clusters["dev"].Leaders[0].DiskSize = ""
+  prod := appSettings.Clusters["prod"]
+  prod.Leaders[0].DiskSize = ""
+  prod.Leaders[1].DiskSize = ""
+  appSettings.Clusters["prod"] = prod
+
+  assert.Equal(t, []string{"clusters.prod.workers[0].diskSize"}, validationPaths(appSettings.Validate()))
+}
+
+func TestValidateProviderUnsupportedClusterType(t *testing.T) {
+  providerRegistry["single-only"] = ProviderDefinition{
+    ProviderType: "single-only",
+    Templates: map[string]string{"single": "single-only-single"},
+    Features: ProviderFeatures{DiskResize: true},
+  }
+  defer delete(providerRegistry, "single-only")
+
+  appSettings := getValidSettings()
+
+  vmware := appSettings.Providers["vmware"]
+  vmware.ProviderType = "single-only"
+  appSettings.Providers["vmware"] = vmware
+
+  assert.Equal(t, []string{"clusters.prod.clusterType"}, validationPaths(appSettings.Validate()))
+}
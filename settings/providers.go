@@ -15,11 +15,30 @@ type Provider struct {
   several different providers
   */
   BoxName string        `json:"boxName"`         // vagrant box name for the provider
+  IpPool string         `json:"ipPool,omitempty"` // ipv4 cidr (/16 or smaller) that blank machine ips and vips are allocated from
 
   /*
   VmWare Fusion/Workstation - These are settings for vmware
   fusion/workstation provider only
   */
   VmNet string          `json:"vmNet"`          // The vmnet for the cluster to use
-  
+
+  /*
+  Libvirt - These are settings for the vagrant-libvirt
+  provider only
+  */
+  StoragePool string              `json:"storagePool,omitempty"`              // The libvirt storage pool for machine disks
+  NetworkName string              `json:"networkName,omitempty"`              // The libvirt network for the private network
+  ManagementNetworkName string    `json:"managementNetworkName,omitempty"`    // The libvirt network vagrant uses to manage machines
+  ManagementNetworkAddress string `json:"managementNetworkAddress,omitempty"` // The cidr of the management network
+  CpuMode string                  `json:"cpuMode,omitempty"`                  // The cpu mode (host-passthrough, host-model or custom)
+  NestedVirtualization bool       `json:"nestedVirtualization,omitempty"`     // Enable nested virtualization for machines
+
+  /*
+  VirtualBox - These are settings for the virtualbox
+  provider only
+  */
+  HostOnlyNetwork string          `json:"hostOnlyNetwork,omitempty"`          // The host-only network for the cluster to use ex vboxnet0
+  LinkedClone bool                `json:"linkedClone,omitempty"`              // Create machines as linked clones of the box
+  ParavirtProvider string         `json:"paravirtProvider,omitempty"`         // The paravirtualization provider ex kvm
 }
@@ -0,0 +1,134 @@
+package settings
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+/*
+  RegistrySettings - Container registry mirrors and registry
+  configuration for k3s, rendered into a k3s registries.yaml
+*/
+type RegistrySettings struct {
+  Mirrors map[string]RegistryMirror     `json:"mirrors,omitempty"`    // registry name (ex docker.io) -> the mirror to pull from
+  Configs map[string]RegistryConfig     `json:"configs,omitempty"`    // registry host -> tls and auth settings
+}
+
+/*
+  RegistryMirror - The endpoints to pull images for a
+  registry from
+*/
+type RegistryMirror struct {
+  Endpoints []string                `json:"endpoints"`            // the mirror urls ex https://registry.internal:5000
+  Rewrite map[string]string         `json:"rewrite,omitempty"`    // image name regex -> replacement
+}
+
+/*
+  RegistryConfig - The tls and auth settings for a
+  registry host
+*/
+type RegistryConfig struct {
+  Auth *RegistryAuth    `json:"auth,omitempty"`   // credentials for the registry
+  Tls *RegistryTls      `json:"tls,omitempty"`    // tls settings for the registry
+}
+
+/*
+  RegistryAuth - Registry credentials, secrets are never
+  stored in the settings, they are read from an environment
+  variable or a file when the cluster is brought up
+*/
+type RegistryAuth struct {
+  Username string        `json:"username,omitempty"`        // the username
+  UsernameEnv string     `json:"usernameEnv,omitempty"`     // an environment variable with the username
+  PasswordEnv string     `json:"passwordEnv,omitempty"`     // an environment variable with the password
+  PasswordFile string    `json:"passwordFile,omitempty"`    // a file with the password
+  TokenEnv string        `json:"tokenEnv,omitempty"`        // an environment variable with an identity token
+}
+
+/*
+  RegistryTls - Tls settings for a registry, the files are
+  paths on the host and are copied to the machines
+*/
+type RegistryTls struct {
+  CaFile string                `json:"caFile,omitempty"`                 // ca certificate to trust for the registry
+  CertFile string              `json:"certFile,omitempty"`               // client certificate
+  KeyFile string               `json:"keyFile,omitempty"`                // client key
+  InsecureSkipVerify bool      `json:"insecureSkipVerify,omitempty"`     // don't verify the registry certificate
+}
+
+/*
+GetClusterRegistries()
+Gets the registry settings for a cluster, the cluster settings
+are merged on top of the global registry settings. Nil is returned
+if neither has registry settings
+*/
+func (settings *Settings) GetClusterRegistries(clusterName string) *RegistrySettings {
+  global := settings.Registries
+  cluster := settings.Clusters[clusterName].Registries
+
+  if global == nil {
+    return cluster
+  }
+  if cluster == nil {
+    return global
+  }
+
+  merged := mergeValues(reflect.ValueOf(*global), reflect.ValueOf(*cluster)).Interface().(RegistrySettings)
+  return &merged
+}
+
+// validates registry settings, path is the json path of the settings
+func (validator *settingsValidator) validateRegistries(path string, registries *RegistrySettings) {
+  if registries == nil {
+    return
+  }
+
+  for _, registry := range sortedKeys(registries.Mirrors) {
+    mirror := registries.Mirrors[registry]
+    mirrorPath := fmt.Sprintf("%s.mirrors.%s", path, registry)
+
+    if len(mirror.Endpoints) == 0 {
+      validator.add(mirrorPath + ".endpoints", "at least one endpoint is required")
+    }
+
+    for index, endpoint := range mirror.Endpoints {
+      endpointUrl, err := url.Parse(endpoint)
+      if err != nil || (endpointUrl.Scheme != "http" && endpointUrl.Scheme != "https") || endpointUrl.Host == "" {
+        validator.add(fmt.Sprintf("%s.endpoints[%d]", mirrorPath, index),
+          "malformed endpoint %q, expected an http or https url", endpoint)
+      }
+    }
+  }
+
+  for _, host := range sortedKeys(registries.Configs) {
+    config := registries.Configs[host]
+    configPath := fmt.Sprintf("%s.configs.%s", path, host)
+
+    if auth := config.Auth; auth != nil {
+      if auth.Username != "" && auth.UsernameEnv != "" {
+        validator.add(configPath + ".auth.usernameEnv", "username and usernameEnv can not both be set")
+      }
+
+      if auth.PasswordEnv != "" && auth.PasswordFile != "" {
+        validator.add(configPath + ".auth.passwordFile", "passwordEnv and passwordFile can not both be set")
+      }
+
+      hasPassword := auth.PasswordEnv != "" || auth.PasswordFile != ""
+      hasUsername := auth.Username != "" || auth.UsernameEnv != ""
+      if hasPassword != hasUsername {
+        validator.add(configPath + ".auth", "a username and password are both required")
+      }
+
+      if auth.TokenEnv == "" && !hasPassword && !hasUsername {
+        validator.add(configPath + ".auth", "a username and password or tokenEnv is required")
+      }
+    }
+
+    if tls := config.Tls; tls != nil {
+      if (tls.CertFile == "") != (tls.KeyFile == "") {
+        validator.add(configPath + ".tls", "certFile and keyFile must be set together")
+      }
+    }
+  }
+}
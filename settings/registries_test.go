@@ -0,0 +1,104 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for registry validation
+*/
+func TestValidateRegistries(t *testing.T) {
+  appSettings := getValidSettings()
+
+  appSettings.Registries = &RegistrySettings{
+    Mirrors: map[string]RegistryMirror{
+      "docker.io": {Endpoints: []string{"https://registry.internal:5000"}},
+    },
+    Configs: map[string]RegistryConfig{
+      "registry.internal:5000": {
+        Auth: &RegistryAuth{Username: "admin", PasswordEnv: "REGISTRY_PASSWORD"},
+        Tls: &RegistryTls{CaFile: "/certs/ca.crt"},
+      },
+    },
+  }
+  assert.Empty(t, appSettings.Validate())
+
+  appSettings.Registries = &RegistrySettings{
+    Mirrors: map[string]RegistryMirror{
+      "docker.io": {Endpoints: []string{"registry.internal:5000"}},
+      "quay.io": {},
+    },
+    Configs: map[string]RegistryConfig{
+      "a.internal": {Auth: &RegistryAuth{Username: "admin", UsernameEnv: "USER", PasswordEnv: "PASS", PasswordFile: "/pass"}},
+      "b.internal": {Auth: &RegistryAuth{Username: "admin"}},
+      "c.internal": {Auth: &RegistryAuth{}, Tls: &RegistryTls{CertFile: "/certs/client.crt"}},
+    },
+  }
+
+  assert.Equal(t, []string{
+    "registries.mirrors.docker.io.endpoints[0]",
+    "registries.mirrors.quay.io.endpoints",
+    "registries.configs.a.internal.auth.usernameEnv",
+    "registries.configs.a.internal.auth.passwordFile",
+    "registries.configs.b.internal.auth",
+    "registries.configs.c.internal.auth",
+    "registries.configs.c.internal.tls",
+  }, validationPaths(appSettings.Validate()))
+}
+
+func TestValidateClusterRegistries(t *testing.T) {
+  appSettings := getValidSettings()
+
+  dev := appSettings.Clusters["dev"]
+  dev.Registries = &RegistrySettings{
+    Mirrors: map[string]RegistryMirror{"docker.io": {}},
+  }
+  appSettings.Clusters["dev"] = dev
+
+  assert.Equal(t, []string{
+    "clusters.dev.registries.mirrors.docker.io.endpoints",
+  }, validationPaths(appSettings.Validate()))
+}
+
+/*
+      Tests for GetClusterRegistries
+*/
+func TestGetClusterRegistriesNone(t *testing.T) {
+  appSettings := getValidSettings()
+
+  assert.Nil(t, appSettings.GetClusterRegistries("dev"))
+}
+
+func TestGetClusterRegistriesMerged(t *testing.T) {
+  appSettings := getValidSettings()
+
+  appSettings.Registries = &RegistrySettings{
+    Mirrors: map[string]RegistryMirror{
+      "docker.io": {Endpoints: []string{"https://global.internal"}},
+      "quay.io": {Endpoints: []string{"https://global.internal"}},
+    },
+  }
+
+  dev := appSettings.Clusters["dev"]
+  dev.Registries = &RegistrySettings{
+    Mirrors: map[string]RegistryMirror{
+      "docker.io": {Endpoints: []string{"https://dev.internal"}},
+    },
+    Configs: map[string]RegistryConfig{
+      "dev.internal": {Tls: &RegistryTls{InsecureSkipVerify: true}},
+    },
+  }
+  appSettings.Clusters["dev"] = dev
+
+  registries := appSettings.GetClusterRegistries("dev")
+  assert.Equal(t, []string{"https://dev.internal"}, registries.Mirrors["docker.io"].Endpoints)
+  assert.Equal(t, []string{"https://global.internal"}, registries.Mirrors["quay.io"].Endpoints)
+  assert.True(t, registries.Configs["dev.internal"].Tls.InsecureSkipVerify)
+
+  // the global settings are not changed
+  assert.Equal(t, []string{"https://global.internal"}, appSettings.Registries.Mirrors["docker.io"].Endpoints)
+  assert.Equal(t, registries, appSettings.GetClusterRegistries("dev"))
+  assert.Equal(t, appSettings.Registries, appSettings.GetClusterRegistries("prod"))
+}
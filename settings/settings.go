@@ -1,9 +1,9 @@
 package settings
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
-	"io"
 	"os"
 	"path/filepath"
 
@@ -14,16 +14,24 @@ import (
   Settings - Local kube settings
 */
 type Settings struct {
+  SchemaVersion int                     `json:"schemaVersion"`  // version of the settings schema
   KubeConfigPath string                 `json:"kubeconfigPath"` // path to kubeconfig path
   ProvisionSettings ProvisionSettings   `json:"provision"`      // Provision settings
   Providers map[string]Provider         `json:"providers"`      // Providers
   Clusters map[string]Cluster           `json:"clusters"`       // Clusters
+  Registries *RegistrySettings          `json:"registries,omitempty"` // Registry mirrors and config for every cluster
+  Network *NetworkSettings              `json:"network,omitempty"`    // Network settings (dns) for every cluster
+  Proxy *ProxySettings                  `json:"proxy,omitempty"`      // The proxy for every cluster
+  CaCertificates []string               `json:"caCertificates,omitempty"` // Ca certificate files every machine trusts
+
+  clusterFiles map[string]bool          // clusters that are stored in clusters.d instead of settings.json
 }
 
 /* 
 validate settings
-TODO: right now this will only validate the cluster 
- name is in the settings more validation can be done later
+this will only validate the cluster name is in the 
+settings, use Validate or ValidateCluster for a full
+validation of the settings
 */
 func (settings *Settings) SettingsValid(clusterName string) bool {
   //validate cluster name is in settings
@@ -62,8 +70,6 @@ create a default settings file (mostly blank) with
 a couple known defaults filled in
 */
 func CreateDefaultSettingsFile(appDir string) error {
-  settingsFile := filepath.Join(appDir, "settings.json")
-
   defaultAnsibleRoles := make(map[string]AnsibleRole)
   defaultAnsibleRoles["kube"] = AnsibleRole{
     LocationType: "git",
@@ -73,6 +79,7 @@ func CreateDefaultSettingsFile(appDir string) error {
   }
 
   emptySettings := Settings{
+    SchemaVersion: CurrentSchemaVersion,
     KubeConfigPath: "~/.kube/config",
     ProvisionSettings: ProvisionSettings{
       AnsibleVersion: "2.17.6",
@@ -84,18 +91,8 @@ func CreateDefaultSettingsFile(appDir string) error {
   logger.LogDebug("creating an empty settings with defauts", "settings", emptySettings)
 
   logger.LogDebug("Creating new settings file")
-  file, err := os.Create(settingsFile)
+  err := WriteSettingsFile(appDir, emptySettings)
   if err != nil {
-    logger.LogError("Error creating blank settings file")
-    return err
-  }
-  defer file.Close()
-
-  encoder := json.NewEncoder(file)
-  encoder.SetIndent("", " ")
-
-  logger.LogDebug("Writing default settings to file")
-  if err := encoder.Encode(emptySettings); err != nil {
     logger.LogError("Error writing settings defaults to file")
     return err
   }
@@ -106,32 +103,125 @@ func CreateDefaultSettingsFile(appDir string) error {
 /*
 ReadSettingsFile()
 Reads the settings file and returns a settings object
-this will contain all the settings in the settings file 
+this will contain all the settings in the settings file,
+if the settings file is from an older schema version it
+will be migrated (and the original backed up) first. Any
+clusters in clusters.d are merged into the clusters
 */
 func ReadSettingsFile(appDir string) (Settings, error){
-  settingsFile := filepath.Join(appDir, "settings.json")
+  bytes, _, err := migrateSettingsFile(appDir, false)
+  if err != nil {
+    logger.LogError("Error reading settings file")
+    return Settings{}, err
+  }
 
-  file, err := os.Open(settingsFile) 
+  var settings Settings
+  err = json.Unmarshal(bytes, &settings)
   if err != nil {
-    logger.LogError("Error opening settings file")
+    logger.LogError("Error unmarshaling json to struct")
     return Settings{}, err
   }
-  defer file.Close()
 
-  bytes, err := io.ReadAll(file)
+  clusterFileClusters, err := readClusterFiles(appDir)
   if err != nil {
-    logger.LogError("Error reading settings file")
+    logger.LogError("Error reading cluster files")
     return Settings{}, err
   }
 
-  var settings Settings
-  err = json.Unmarshal(bytes, &settings)
+  err = settings.mergeClusterFiles(clusterFileClusters)
   if err != nil {
-    logger.LogError("Error unmarshaling json to struct")
     return Settings{}, err
   }
   logger.LogDebug("Settings file read successfully")
   return settings, nil
 }
 
+/*
+WriteSettingsFile()
+Writes the settings object out to the settings file, clusters
+that were read from clusters.d are written back to their own
+cluster files instead of settings.json
+*/
+func WriteSettingsFile(appDir string, settings Settings) error {
+  for clusterName, cluster := range settings.Clusters {
+    if !settings.IsClusterFile(clusterName) {
+      continue
+    }
+
+    err := writeClusterFile(appDir, clusterName, cluster)
+    if err != nil {
+      return err
+    }
+  }
+  return writeSettingsJson(appDir, settings)
+}
+
+// writes settings.json without the clusters that are
+// stored in their own cluster files
+func writeSettingsJson(appDir string, settings Settings) error {
+  settingsFile := filepath.Join(appDir, "settings.json")
+
+  settingsClusters := make(map[string]Cluster)
+  for clusterName, cluster := range settings.Clusters {
+    if !settings.IsClusterFile(clusterName) {
+      settingsClusters[clusterName] = cluster
+    }
+  }
+  settings.Clusters = settingsClusters
+
+  bytes, err := encodeSettings(settings)
+  if err != nil {
+    logger.LogError("Error encoding settings")
+    return err
+  }
+
+  logger.LogDebug("Writing settings to file", "file", settingsFile)
+  err = writeFileAtomic(settingsFile, bytes)
+  if err != nil {
+    logger.LogError("Error writing settings file")
+    return err
+  }
+  return nil
+}
+
+/*
+writes a file by writing a temp file next to it and renaming
+it over the original, so the file is never left half written
+*/
+func writeFileAtomic(path string, content []byte) error {
+  tempFile, err := os.CreateTemp(filepath.Dir(path), "." + filepath.Base(path) + ".tmp")
+  if err != nil {
+    return err
+  }
+  tempPath := tempFile.Name()
 
+  _, err = tempFile.Write(content)
+  if err == nil {
+    err = tempFile.Chmod(0644)
+  }
+  if closeErr := tempFile.Close(); err == nil {
+    err = closeErr
+  }
+
+  if err != nil {
+    os.Remove(tempPath)
+    return err
+  }
+  return os.Rename(tempPath, path)
+}
+
+/*
+encodes settings to json the same way for every 
+write so files are consistent
+*/
+func encodeSettings(settings Settings) ([]byte, error) {
+  var buffer bytes.Buffer
+
+  encoder := json.NewEncoder(&buffer)
+  encoder.SetIndent("", " ")
+
+  if err := encoder.Encode(settings); err != nil {
+    return nil, err
+  }
+  return buffer.Bytes(), nil
+}
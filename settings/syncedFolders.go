@@ -0,0 +1,125 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+/*
+  SyncedFolder - A host directory synced into every machine of
+  the cluster (ex an application repo for hostPath volumes)
+*/
+type SyncedFolder struct {
+  HostPath string             `json:"hostPath"`                   // The directory on the host, absolute or starting with ~/
+  GuestPath string            `json:"guestPath"`                  // Where the directory is mounted on the machines
+  Type string                 `json:"type,omitempty"`             // (nfs or rsync) the synced folder type, if empty the provider default is used
+  RsyncExcludes []string      `json:"rsyncExcludes,omitempty"`    // Paths rsync skips, rsync folders only (ex .git/)
+  NfsVersion int              `json:"nfsVersion,omitempty"`       // (3 or 4) the nfs version, nfs folders only, default is 4
+  MountOptions []string       `json:"mountOptions,omitempty"`     // Mount options for the folder, not used by rsync folders
+}
+
+var supportedSyncedFolderTypes = []string{"nfs", "rsync"}
+
+const defaultNfsVersion = 4
+
+// guest paths local-kube already syncs or that would hide the system
+var reservedGuestPaths = []string{"/", "/vagrant", "/provision", "/scripts", "/etc", "/etc/ansible", "/bundle", "/usr", "/var", "/home"}
+
+/*
+GetHostPath()
+Gets the host path with a leading ~ expanded to the
+home directory, in forward slash form for the vagrantfile
+*/
+func (folder SyncedFolder) GetHostPath() string {
+  hostPath := folder.HostPath
+
+  if hostPath == "~" || strings.HasPrefix(hostPath, "~/") {
+    if homeDir, err := os.UserHomeDir(); err == nil {
+      hostPath = filepath.Join(homeDir, strings.TrimPrefix(hostPath, "~"))
+    }
+  }
+  return filepath.ToSlash(hostPath)
+}
+
+/*
+GetNfsVersion()
+Gets the nfs version, the default version if none is set
+*/
+func (folder SyncedFolder) GetNfsVersion() int {
+  if folder.NfsVersion == 0 {
+    return defaultNfsVersion
+  }
+  return folder.NfsVersion
+}
+
+// checks if a guest path is reserved or under /vagrant which
+// local-kube uses for its own folders
+func isReservedGuestPath(guestPath string) bool {
+  return contains(reservedGuestPaths, guestPath) || strings.HasPrefix(guestPath, "/vagrant/") ||
+    strings.HasPrefix(guestPath, "/etc/ansible/")
+}
+
+// validates the synced folders of a cluster, the host paths
+// have to exist on this host, folders without a type use the
+// default type of the provider
+func (validator *settingsValidator) validateSyncedFolders(clusterPath string, folders []SyncedFolder,
+  defaultType string) {
+  guestPaths := make(map[string]string)
+
+  for index, folder := range folders {
+    folderPath := fmt.Sprintf("%s.syncedFolders[%d]", clusterPath, index)
+
+    hostPath := folder.GetHostPath()
+    if folder.HostPath == "" {
+      validator.add(folderPath + ".hostPath", "host path is required")
+    } else if !filepath.IsAbs(filepath.FromSlash(hostPath)) {
+      validator.add(folderPath + ".hostPath", "host path %q must be absolute or start with ~/", folder.HostPath)
+    } else if info, err := os.Stat(filepath.FromSlash(hostPath)); err != nil {
+      validator.add(folderPath + ".hostPath", "host path %s does not exist", hostPath)
+    } else if !info.IsDir() {
+      validator.add(folderPath + ".hostPath", "host path %s is not a directory", hostPath)
+    }
+
+    if folder.GuestPath == "" {
+      validator.add(folderPath + ".guestPath", "guest path is required")
+    } else if !path.IsAbs(folder.GuestPath) || path.Clean(folder.GuestPath) != folder.GuestPath {
+      validator.add(folderPath + ".guestPath", "guest path %q must be a clean absolute path", folder.GuestPath)
+    } else if isReservedGuestPath(folder.GuestPath) {
+      validator.add(folderPath + ".guestPath", "guest path %s is reserved", folder.GuestPath)
+    } else if otherPath, exists := guestPaths[folder.GuestPath]; exists {
+      validator.add(folderPath + ".guestPath", "guest path %s is already used by %s", folder.GuestPath, otherPath)
+    } else {
+      guestPaths[folder.GuestPath] = folderPath
+    }
+
+    if folder.Type != "" && !contains(supportedSyncedFolderTypes, folder.Type) {
+      validator.add(folderPath + ".type", "unsupported synced folder type %q, must be one of %s",
+        folder.Type, strings.Join(supportedSyncedFolderTypes, ", "))
+    }
+
+    folderType := folder.Type
+    if folderType == "" {
+      folderType = defaultType
+    }
+
+    if len(folder.RsyncExcludes) > 0 && folderType != "rsync" {
+      validator.add(folderPath + ".rsyncExcludes", "rsync excludes are only used with rsync folders")
+    }
+
+    if folder.NfsVersion != 0 {
+      if folderType != "nfs" {
+        validator.add(folderPath + ".nfsVersion", "nfs version is only used with nfs folders")
+      } else if folder.NfsVersion != 3 && folder.NfsVersion != 4 {
+        validator.add(folderPath + ".nfsVersion", "unsupported nfs version %d, must be 3 or 4", folder.NfsVersion)
+      }
+    }
+
+    if len(folder.MountOptions) > 0 && folderType == "rsync" {
+      validator.add(folderPath + ".mountOptions", "mount options are not used with rsync folders")
+    }
+  }
+}
+
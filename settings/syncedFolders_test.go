@@ -0,0 +1,82 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for GetHostPath
+*/
+func TestSyncedFolderGetHostPath(t *testing.T) {
+  homeDir, err := os.UserHomeDir()
+  assert.NoError(t, err)
+
+  assert.Equal(t, filepath.ToSlash(filepath.Join(homeDir, "src", "app")), SyncedFolder{HostPath: "~/src/app"}.GetHostPath())
+  assert.Equal(t, "/srv/app", SyncedFolder{HostPath: "/srv/app"}.GetHostPath())
+  assert.Equal(t, "~app", SyncedFolder{HostPath: "~app"}.GetHostPath())
+}
+
+/*
+      Tests for validateSyncedFolders
+*/
+func TestValidateSyncedFolders(t *testing.T) {
+  hostDir := t.TempDir()
+  hostFile := filepath.Join(hostDir, "file")
+  assert.NoError(t, os.WriteFile(hostFile, []byte("file"), 0644))
+
+  appSettings := getValidSettings()
+
+  dev := appSettings.Clusters["dev"]
+  dev.SyncedFolders = []SyncedFolder{
+    {HostPath: hostDir, GuestPath: "/src/app"},
+    {HostPath: hostDir, GuestPath: "/src/api", Type: "rsync", RsyncExcludes: []string{".git/"}},
+    {HostPath: hostDir, GuestPath: "/src/web", Type: "nfs", NfsVersion: 3, MountOptions: []string{"actimeo=1"}},
+  }
+  appSettings.Clusters["dev"] = dev
+  assert.Empty(t, appSettings.Validate())
+
+  dev.SyncedFolders = []SyncedFolder{
+    {HostPath: "src/app", GuestPath: "src/app"},
+    {HostPath: filepath.Join(hostDir, "missing"), GuestPath: "/vagrant/app", Type: "smb"},
+    {HostPath: hostFile, GuestPath: "/src/app", RsyncExcludes: []string{".git/"}, NfsVersion: 4},
+    {HostPath: hostDir, GuestPath: "/src/app", Type: "nfs", NfsVersion: 2},
+    {HostPath: hostDir, GuestPath: "/src/api", Type: "rsync", MountOptions: []string{"ro"}},
+  }
+  appSettings.Clusters["dev"] = dev
+
+  assert.Equal(t, []string{
+    "clusters.dev.syncedFolders[0].hostPath",
+    "clusters.dev.syncedFolders[0].guestPath",
+    "clusters.dev.syncedFolders[1].hostPath",
+    "clusters.dev.syncedFolders[1].guestPath",
+    "clusters.dev.syncedFolders[1].type",
+    "clusters.dev.syncedFolders[2].hostPath",
+    "clusters.dev.syncedFolders[2].rsyncExcludes",
+    "clusters.dev.syncedFolders[2].nfsVersion",
+    "clusters.dev.syncedFolders[3].guestPath",
+    "clusters.dev.syncedFolders[3].nfsVersion",
+    "clusters.dev.syncedFolders[4].mountOptions",
+  }, validationPaths(appSettings.Validate()))
+}
+
+func TestValidateSyncedFoldersProviderDefault(t *testing.T) {
+  hostDir := t.TempDir()
+
+  appSettings := getValidSettings()
+  appSettings.Providers["kvm"] = Provider{ProviderType: "libvirt"}
+
+  // libvirt folders without a type are nfs folders
+  dev := appSettings.Clusters["dev"]
+  dev.ProviderName = "kvm"
+  dev.SyncedFolders = []SyncedFolder{
+    {HostPath: hostDir, GuestPath: "/src/app", NfsVersion: 3},
+    {HostPath: hostDir, GuestPath: "/src/api", RsyncExcludes: []string{".git/"}},
+  }
+  appSettings.Clusters["dev"] = dev
+
+  assert.Equal(t, []string{"clusters.dev.syncedFolders[1].rsyncExcludes"}, validationPaths(appSettings.Validate()))
+}
@@ -0,0 +1,509 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+)
+
+/*
+  ValidationError - A single problem found in the settings, path
+  is the json path to the offending value (ex clusters.dev.leaders[0].diskSize)
+*/
+type ValidationError struct {
+  Path string         `json:"path"`       // json path of the value that failed validation
+  Message string      `json:"message"`    // what is wrong with the value
+}
+
+func (validationError ValidationError) Error() string {
+  return fmt.Sprintf("%s: %s", validationError.Path, validationError.Message)
+}
+
+/*
+  ValidationErrors - All the problems found while validating
+  the settings
+*/
+type ValidationErrors []ValidationError
+
+func (validationErrors ValidationErrors) Error() string {
+  messages := []string{}
+
+  for _, validationError := range validationErrors {
+    messages = append(messages, validationError.Error())
+  }
+  return strings.Join(messages, "\n")
+}
+
+// supported values for settings that are an enum
+var supportedClusterTypes = []string{"single", "ha"}
+var supportedLibvirtCpuModes = []string{"host-passthrough", "host-model", "custom"}
+var supportedParavirtProviders = []string{"default", "legacy", "minimal", "hyperv", "kvm", "none"}
+var supportedRoleLocationTypes = []string{"git", "local"}
+var supportedRoleRefTypes = []string{"branch", "tag"}
+
+// disk sizes are a number and a unit ex 50GB
+var diskSizeRegex = regexp.MustCompile(`^[1-9][0-9]*(MB|GB|TB)$`)
+
+// kubernetes node labels and taints ex node-role/gpu=true and gpu=true:NoSchedule
+var labelKeyRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9.-]*[a-z0-9])?/)?[A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?$`)
+var labelValueRegex = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?)?$`)
+var taintRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9.-]*[a-z0-9])?/)?[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?(=[A-Za-z0-9._-]*)?:(NoSchedule|PreferNoSchedule|NoExecute)$`)
+
+/*
+  settingsValidator - collects validation errors while walking
+  the settings so every problem can be reported at once
+*/
+type settingsValidator struct {
+  errors ValidationErrors
+}
+
+func (validator *settingsValidator) add(path string, format string, args ...interface{}) {
+  message := fmt.Sprintf(format, args...)
+  logger.LogDebug("Settings validation error", "path", path, "message", message)
+  validator.errors = append(validator.errors, ValidationError{
+    Path: path,
+    Message: message,
+  })
+}
+
+/*
+Validate()
+Validates all of the settings, this includes the provision settings,
+all providers and all clusters. All problems are returned instead of
+stopping at the first one
+*/
+func (settings *Settings) Validate() ValidationErrors {
+  validator := settingsValidator{}
+
+  validator.validateProvisionSettings(settings.ProvisionSettings)
+
+  for _, providerName := range sortedKeys(settings.Providers) {
+    validator.validateProvider(providerName, settings.Providers[providerName])
+  }
+
+  validator.validateRegistries("registries", settings.Registries)
+  validator.validateNetworkSettings("network", settings.Network, "proxy", settings.Proxy,
+    "caCertificates", settings.CaCertificates)
+
+  for _, clusterName := range sortedKeys(settings.Clusters) {
+    validator.validateResolvedCluster(settings, clusterName)
+  }
+  return validator.errors
+}
+
+/*
+ValidateCluster()
+Validates the settings needed to bring up a single cluster, this is
+the provision settings, the provider the cluster uses and the cluster
+itself
+*/
+func (settings *Settings) ValidateCluster(clusterName string) ValidationErrors {
+  validator := settingsValidator{}
+
+  if _, exists := settings.Clusters[clusterName]; !exists {
+    validator.add(fmt.Sprintf("clusters.%s", clusterName), "cluster is not present in settings")
+    return validator.errors
+  }
+
+  validator.validateProvisionSettings(settings.ProvisionSettings)
+  validator.validateRegistries("registries", settings.Registries)
+  validator.validateNetworkSettings("network", settings.Network, "proxy", settings.Proxy,
+    "caCertificates", settings.CaCertificates)
+
+  cluster, err := settings.ResolveCluster(clusterName)
+  if err == nil {
+    if provider, exists := settings.Providers[cluster.ProviderName]; exists {
+      validator.validateProvider(cluster.ProviderName, provider)
+    }
+  }
+
+  validator.validateResolvedCluster(settings, clusterName)
+  return validator.errors
+}
+
+// resolves the extends for a cluster and validates the result
+func (validator *settingsValidator) validateResolvedCluster(settings *Settings, clusterName string) {
+  errorCount := len(validator.errors)
+
+  // pools are checked before they are expanded so errors point at the pool
+  for index, pool := range settings.Clusters[clusterName].WorkerPools {
+    validator.validateWorkerPool(fmt.Sprintf("clusters.%s.workerPools[%d]", clusterName, index), pool)
+  }
+
+  if len(validator.errors) > errorCount {
+    return
+  }
+
+  cluster, err := settings.ResolveCluster(clusterName)
+  if err != nil {
+    validator.add(fmt.Sprintf("clusters.%s.extends", clusterName), "%v", err)
+    return
+  }
+
+  validator.validateCluster(settings, clusterName, cluster)
+}
+
+// validates the provision settings and the ansible roles in it
+func (validator *settingsValidator) validateProvisionSettings(provisionSettings ProvisionSettings) {
+  if provisionSettings.AnsibleVersion == "" {
+    validator.add("provision.ansibleVersion", "ansible version is required")
+  }
+
+  for _, roleName := range sortedKeys(provisionSettings.AnsibleRoles) {
+    role := provisionSettings.AnsibleRoles[roleName]
+    path := fmt.Sprintf("provision.ansibleRoles.%s", roleName)
+
+    if role.Location == "" {
+      validator.add(path + ".location", "location is required")
+    }
+
+    if !contains(supportedRoleLocationTypes, role.LocationType) {
+      validator.add(path + ".locationType", "unsupported location type %q, must be one of %s",
+        role.LocationType, strings.Join(supportedRoleLocationTypes, ", "))
+      continue
+    }
+
+    if role.LocationType == "git" {
+      if !contains(supportedRoleRefTypes, role.RefType) {
+        validator.add(path + ".gitRefType", "unsupported git ref type %q, must be one of %s",
+          role.RefType, strings.Join(supportedRoleRefTypes, ", "))
+      }
+
+      if role.GitRef == "" {
+        validator.add(path + ".gitRef", "git ref is required for git roles")
+      }
+    }
+  }
+}
+
+// validates a single provider
+func (validator *settingsValidator) validateProvider(providerName string, provider Provider) {
+  path := fmt.Sprintf("providers.%s", providerName)
+
+  definition, exists := GetProviderDefinition(provider.ProviderType)
+  if !exists {
+    validator.add(path + ".providerType", "unsupported provider type %q, must be one of %s",
+      provider.ProviderType, strings.Join(GetProviderTypes(), ", "))
+  } else {
+    for _, field := range definition.missingFields(provider.WithDefaults()) {
+      validator.add(path + "." + field, "%s is required for %s providers", field, provider.ProviderType)
+    }
+
+    if provider.LinkedClone && !definition.Features.LinkedClones {
+      validator.add(path + ".linkedClone", "%s providers do not support linked clones", provider.ProviderType)
+    }
+  }
+
+  if provider.IpPool != "" {
+    if _, err := parseIpPool(provider.IpPool); err != nil {
+      validator.add(path + ".ipPool", "%v", err)
+    }
+  }
+
+  if provider.ProviderType == "libvirt" {
+    if provider.CpuMode != "" && !contains(supportedLibvirtCpuModes, provider.CpuMode) {
+      validator.add(path + ".cpuMode", "unsupported cpu mode %q, must be one of %s",
+        provider.CpuMode, strings.Join(supportedLibvirtCpuModes, ", "))
+    }
+
+    if provider.ManagementNetworkAddress != "" {
+      if _, _, err := net.ParseCIDR(provider.ManagementNetworkAddress); err != nil {
+        validator.add(path + ".managementNetworkAddress", "malformed management network address %q, expected a cidr",
+          provider.ManagementNetworkAddress)
+      }
+    }
+  }
+
+  if provider.ProviderType == "virtualbox" {
+    if provider.ParavirtProvider != "" && !contains(supportedParavirtProviders, provider.ParavirtProvider) {
+      validator.add(path + ".paravirtProvider", "unsupported paravirt provider %q, must be one of %s",
+        provider.ParavirtProvider, strings.Join(supportedParavirtProviders, ", "))
+    }
+  }
+}
+
+// validates a single cluster, its machines and features
+func (validator *settingsValidator) validateCluster(settings *Settings, clusterName string, cluster Cluster) {
+  path := fmt.Sprintf("clusters.%s", clusterName)
+
+  // provider reference
+  if cluster.ProviderName == "" {
+    validator.add(path + ".providerName", "provider name is required")
+  } else if _, exists := settings.Providers[cluster.ProviderName]; !exists {
+    validator.add(path + ".providerName", "provider %q is not defined in providers", cluster.ProviderName)
+  }
+
+  // blank ips are allocated from the provider ip pool
+  allowBlankIps := settings.Providers[cluster.ProviderName].IpPool != ""
+
+  // unknown provider types are reported on the provider
+  providerType := settings.Providers[cluster.ProviderName].ProviderType
+  diskResize := true
+  syncedFolderType := ""
+  if definition, exists := GetProviderDefinition(providerType); exists {
+    diskResize = definition.Features.DiskResize
+    syncedFolderType = definition.SyncedFolderType
+  }
+
+  // cluster type
+  if !contains(supportedClusterTypes, cluster.ClusterType) {
+    validator.add(path + ".clusterType", "unsupported cluster type %q, must be one of %s",
+      cluster.ClusterType, strings.Join(supportedClusterTypes, ", "))
+  } else if definition, exists := GetProviderDefinition(providerType); exists {
+    if _, err := definition.GetTemplateName(cluster.ClusterType); err != nil {
+      validator.add(path + ".clusterType", "%v", err)
+    }
+  }
+
+  // machines
+  if len(cluster.Leaders) == 0 {
+    validator.add(path + ".leaders", "at least one leader is required")
+  }
+
+  if cluster.ClusterType == "single" {
+    if len(cluster.Leaders) > 1 {
+      validator.add(path + ".leaders", "single clusters can only have one leader")
+    }
+
+    if len(cluster.Workers) > 0 {
+      validator.add(path + ".workers", "single clusters can not have workers")
+    }
+  }
+
+  machineNames := make(map[string]string)
+  ipAddresses := make(map[string]string)
+  distribution := cluster.GetDistribution()
+
+  for index, machine := range cluster.Leaders {
+    machinePath := fmt.Sprintf("%s.leaders[%d]", path, index)
+    validator.validateMachine(machinePath, machine, providerType, allowBlankIps, diskResize, machineNames, ipAddresses)
+    validator.validateK3sArgs(machinePath, machine.K3sArgs, distribution, true, false)
+  }
+
+  for index, machine := range cluster.Workers {
+    machinePath := fmt.Sprintf("%s.workers[%d]", path, index)
+    validator.validateMachine(machinePath, machine, providerType, allowBlankIps, diskResize, machineNames, ipAddresses)
+    validator.validateK3sArgs(machinePath, machine.K3sArgs, distribution, false, true)
+  }
+
+  // vip
+  if cluster.Vip != "" {
+    if net.ParseIP(cluster.Vip) == nil {
+      validator.add(path + ".vip", "%q is not a valid ip address", cluster.Vip)
+    } else if otherPath, exists := ipAddresses[cluster.Vip]; exists {
+      validator.add(path + ".vip", "ip address %s is already used by %s", cluster.Vip, otherPath)
+    }
+  }
+
+  validator.validateForwardedPorts(path, cluster)
+  validator.validateSyncedFolders(path, cluster.SyncedFolders, syncedFolderType)
+  validator.validateClusterFeatures(path, cluster, allowBlankIps)
+  validator.validateAddons(path, cluster.Addons)
+  validator.validateRegistries(path + ".registries", cluster.Registries)
+  validator.validateNetworkSettings(path + ".network", cluster.Network, path + ".proxy", cluster.Proxy,
+    path + ".caCertificates", cluster.CaCertificates)
+  validator.validateHooks(path + ".hooks", cluster.Hooks)
+}
+
+// validates a worker pool before it is expanded into machines
+func (validator *settingsValidator) validateWorkerPool(path string, pool WorkerPool) {
+  if pool.NamePrefix == "" {
+    validator.add(path + ".namePrefix", "name prefix is required")
+  }
+
+  if pool.Count <= 0 {
+    validator.add(path + ".count", "count must be greater than 0")
+  }
+
+  if pool.StartIp != "" && net.ParseIP(pool.StartIp).To4() == nil {
+    validator.add(path + ".startIp", "%q is not a valid ipv4 address", pool.StartIp)
+  }
+}
+
+// validates a single machine, names and ips are tracked to find duplicates,
+// machines on providers that can not resize disks use the box disk size
+func (validator *settingsValidator) validateMachine(path string, machine Machine, providerType string,
+  allowBlankIps bool, diskResize bool, machineNames map[string]string, ipAddresses map[string]string) {
+
+  if machine.Name == "" {
+    validator.add(path + ".name", "name is required")
+  } else if otherPath, exists := machineNames[machine.Name]; exists {
+    validator.add(path + ".name", "machine name %q is already used by %s", machine.Name, otherPath)
+  } else {
+    machineNames[machine.Name] = path
+  }
+
+  if machine.IpAddress == "" {
+    if !allowBlankIps {
+      validator.add(path + ".ipAddress", "ip address is required when the provider has no ipPool")
+    }
+  } else if net.ParseIP(machine.IpAddress) == nil {
+    validator.add(path + ".ipAddress", "%q is not a valid ip address", machine.IpAddress)
+  } else if otherPath, exists := ipAddresses[machine.IpAddress]; exists {
+    validator.add(path + ".ipAddress", "ip address %s is already used by %s", machine.IpAddress, otherPath)
+  } else {
+    ipAddresses[machine.IpAddress] = path
+  }
+
+  if machine.Memory <= 0 {
+    validator.add(path + ".memory", "memory must be greater than 0")
+  }
+
+  if machine.Cpu <= 0 {
+    validator.add(path + ".cpus", "cpus must be greater than 0")
+  }
+
+  if !diskResize {
+    if machine.DiskSize != "" {
+      validator.add(path + ".diskSize", "%s providers can not resize disks, diskSize must be blank", providerType)
+    }
+  } else if !diskSizeRegex.MatchString(machine.DiskSize) {
+    validator.add(path + ".diskSize", "malformed disk size %q, expected a size like 50GB", machine.DiskSize)
+  }
+
+  for _, labelKey := range sortedKeys(machine.Labels) {
+    if !labelKeyRegex.MatchString(labelKey) {
+      validator.add(path + ".labels", "malformed label key %q", labelKey)
+    }
+
+    if !labelValueRegex.MatchString(machine.Labels[labelKey]) {
+      validator.add(path + ".labels", "malformed value %q for label %s", machine.Labels[labelKey], labelKey)
+    }
+  }
+
+  for index, taint := range machine.Taints {
+    if !taintRegex.MatchString(taint) {
+      validator.add(fmt.Sprintf("%s.taints[%d]", path, index),
+        "malformed taint %q, expected key=value:Effect (NoSchedule, PreferNoSchedule or NoExecute)", taint)
+    }
+  }
+
+  validator.validateDisks(path, machine.Disks)
+  validator.validateNetworks(path, machine.Networks, ipAddresses)
+}
+
+// validates the feature combinations for a cluster, a nil feature set is valid
+// since defaults will be used
+func (validator *settingsValidator) validateClusterFeatures(clusterPath string, cluster Cluster, allowBlankIps bool) {
+  path := clusterPath + ".clusterFeatures"
+  features := cluster.ClusterFeatures
+
+  if features == nil {
+    features = &ClusterFeatures{}
+  }
+
+  if cluster.ClusterType == "ha" && !features.KubeVipEnable {
+    validator.add(path + ".kubeVipEnable", "kubevip must be enabled for ha clusters")
+  }
+
+  if features.KubeVipEnable && cluster.Vip == "" && !allowBlankIps {
+    validator.add(clusterPath + ".vip", "vip is required when kubevip is enabled")
+  }
+
+  if _, exists := GetDistributionDefinition(features.Distribution); !exists {
+    validator.add(path + ".distribution", "unsupported distribution %q, must be one of %s",
+      features.Distribution, strings.Join(GetDistributionNames(), ", "))
+  }
+  distribution := features.GetDistribution()
+
+  if features.CniController != "" && !contains(distribution.CniControllers, features.CniController) {
+    validator.add(path + ".cniController", "unsupported cni controller %q for %s, must be one of %s",
+      features.CniController, distribution.Name, strings.Join(distribution.CniControllers, ", "))
+  }
+
+  builtinCni := distribution.GetBuiltinCniController()
+  if features.ManagedCniController && (features.CniController == "" || features.CniController == builtinCni) {
+    validator.add(path + ".managedCniController", "%s is built in and can not be a managed cni controller", builtinCni)
+  }
+
+  if features.CiliumCliVersion != "" && features.CniController != "cilium" {
+    validator.add(path + ".ciliumCliVersion", "cilium cli version is only used with the cilium cni controller")
+  }
+
+  if features.StorageController != "" && !contains(distribution.StorageControllers, features.StorageController) {
+    validator.add(path + ".storageController", "unsupported storage controller %q for %s, must be one of %s",
+      features.StorageController, distribution.Name, strings.Join(distribution.StorageControllers, ", "))
+  }
+
+  if features.ManagedStorageController && features.StorageController != "longhorn" {
+    validator.add(path + ".managedStorageController", "only longhorn can be a managed storage controller")
+  }
+
+  validator.validateStorageDataPath(path, cluster, *features)
+  validator.validateBindNetwork(path, cluster, *features)
+
+  if features.KubeVipVersion != "" && !features.KubeVipEnable {
+    validator.add(path + ".kubeVipVersion", "kubevip version is set but kubevip is not enabled")
+  }
+
+  validator.validateK3sArgs(path, features.K3sArgs, distribution, true, true)
+
+  // the built in components the features use can't be disabled
+  controllers := []struct {
+    kind string
+    controller string
+    defaultController string
+  }{
+    {"ingress", features.IngressController, distribution.Defaults.IngressController},
+    {"storage", features.StorageController, distribution.Defaults.StorageController},
+  }
+
+  for _, controller := range controllers {
+    name := controller.controller
+    if name == "" {
+      name = controller.defaultController
+    }
+
+    if component, builtin := distribution.BuiltinComponents[name]; builtin && contains(features.DisableComponents, component) {
+      validator.add(path + ".disableComponents", "%s can not be disabled when it is the %s controller", component, controller.kind)
+    }
+  }
+
+  // component versions that are known to not work with the kube version
+  if catalog, err := GetVersionCatalog(); err == nil {
+    kubeVersion := features.KubeVersion
+    if kubeVersion == "" {
+      kubeVersion = catalog.DefaultKubeVersion
+    }
+
+    componentFields := map[string]string{
+      "cilium": "cniControllerVersion",
+      "calico": "cniControllerVersion",
+      "kubeVip": "kubeVipVersion",
+      "longhorn": "storageControllerVersion",
+    }
+
+    componentVersions := features.getComponentVersions()
+    for _, component := range sortedKeys(componentVersions) {
+      if reason, bad := catalog.IsKnownBad(kubeVersion, component, componentVersions[component]); bad {
+        validator.add(path + "." + componentFields[component], "%s %s does not work with kube version %s, %s",
+          component, componentVersions[component], kubeVersion, reason)
+      }
+    }
+  }
+}
+
+// checks if a string is in a list of strings
+func contains(list []string, value string) bool {
+  for _, item := range list {
+    if item == value {
+      return true
+    }
+  }
+  return false
+}
+
+// gets the keys of a map sorted so validation output is stable
+func sortedKeys[V any](values map[string]V) []string {
+  keys := []string{}
+
+  for key := range values {
+    keys = append(keys, key)
+  }
+  sort.Strings(keys)
+  return keys
+}
@@ -0,0 +1,265 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gets a set of valid settings that tests can break
+func getValidSettings() Settings {
+  return Settings{
+    KubeConfigPath: "~/.kube/config",
+    ProvisionSettings: ProvisionSettings{
+      AnsibleVersion: "2.17.6",
+      AnsibleRoles: map[string]AnsibleRole{
+        "kube": {
+          LocationType: "git",
+          Location: "https://github.com/dgutierrez1287/ansible-role-kube",
+          RefType: "branch",
+          GitRef: "master",
+        },
+      },
+    },
+    Providers: map[string]Provider{
+      "vmware": {ProviderType: "vmware-desktop", BoxName: "bento/ubuntu-24.04", VmNet: "vmnet2"},
+    },
+    Clusters: map[string]Cluster{
+      "dev": {
+        ClusterType: "single",
+        ProviderName: "vmware",
+        Leaders: []Machine{
+          {Name: "dev", IpAddress: "192.168.1.10", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+        },
+      },
+      "prod": {
+        ClusterType: "ha",
+        ProviderName: "vmware",
+        Vip: "192.168.1.20",
+        Leaders: []Machine{
+          {Name: "prod-cp01", IpAddress: "192.168.1.21", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+          {Name: "prod-cp02", IpAddress: "192.168.1.22", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+        },
+        Workers: []Machine{
+          {Name: "prod-w01", IpAddress: "192.168.1.23", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+        },
+        ClusterFeatures: &ClusterFeatures{
+          KubeVipEnable: true,
+          CniController: "cilium",
+        },
+      },
+    },
+  }
+}
+
+// gets just the paths from validation errors
+func validationPaths(validationErrors ValidationErrors) []string {
+  paths := []string{}
+
+  for _, validationError := range validationErrors {
+    paths = append(paths, validationError.Path)
+  }
+  return paths
+}
+
+/*
+      Tests for Validate
+*/
+func TestValidateValidSettings(t *testing.T) {
+  appSettings := getValidSettings()
+
+  validationErrors := appSettings.Validate()
+
+  assert.Empty(t, validationErrors)
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+  appSettings := getValidSettings()
+
+  dev := appSettings.Clusters["dev"]
+  dev.ProviderName = "missing"
+  dev.Leaders[0].DiskSize = "50 gigs"
+  appSettings.Clusters["dev"] = dev
+
+  prod := appSettings.Clusters["prod"]
+  prod.ClusterType = "multi"
+  prod.Workers[0].Name = "prod-cp01"
+  prod.Workers[0].IpAddress = "192.168.1.21"
+  appSettings.Clusters["prod"] = prod
+
+  validationErrors := appSettings.Validate()
+
+  assert.ElementsMatch(t, []string{
+    "clusters.dev.providerName",
+    "clusters.dev.leaders[0].diskSize",
+    "clusters.prod.clusterType",
+    "clusters.prod.workers[0].name",
+    "clusters.prod.workers[0].ipAddress",
+  }, validationPaths(validationErrors))
+}
+
+func TestValidateAnsibleRoles(t *testing.T) {
+  appSettings := getValidSettings()
+  appSettings.ProvisionSettings.AnsibleRoles["bad-type"] = AnsibleRole{
+    LocationType: "svn",
+    Location: "https://example.com/role",
+  }
+  appSettings.ProvisionSettings.AnsibleRoles["bad-ref"] = AnsibleRole{
+    LocationType: "git",
+    Location: "https://example.com/role",
+    RefType: "commit",
+    GitRef: "abc123",
+  }
+
+  validationErrors := appSettings.Validate()
+
+  assert.ElementsMatch(t, []string{
+    "provision.ansibleRoles.bad-type.locationType",
+    "provision.ansibleRoles.bad-ref.gitRefType",
+  }, validationPaths(validationErrors))
+}
+
+func TestValidateClusterFeatureCombinations(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.Vip = ""
+  prod.ClusterFeatures = &ClusterFeatures{
+    KubeVipEnable: true,
+    CniController: "flannel",
+    ManagedCniController: true,
+    ManagedStorageController: true,
+  }
+  appSettings.Clusters["prod"] = prod
+
+  validationErrors := appSettings.Validate()
+
+  assert.ElementsMatch(t, []string{
+    "clusters.prod.vip",
+    "clusters.prod.clusterFeatures.managedCniController",
+    "clusters.prod.clusterFeatures.managedStorageController",
+  }, validationPaths(validationErrors))
+}
+
+func TestValidateKnownBadVersions(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.ClusterFeatures.KubeVersion = "1.30.8"
+  prod.ClusterFeatures.CniControllerVersion = "1.14.5"
+  appSettings.Clusters["prod"] = prod
+
+  validationErrors := appSettings.Validate()
+
+  assert.Equal(t, []string{"clusters.prod.clusterFeatures.cniControllerVersion"}, validationPaths(validationErrors))
+}
+
+func TestValidateNilClusterFeatures(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.ClusterFeatures = nil
+  appSettings.Clusters["prod"] = prod
+
+  validationErrors := appSettings.Validate()
+
+  assert.Equal(t, []string{"clusters.prod.clusterFeatures.kubeVipEnable"}, validationPaths(validationErrors))
+}
+
+func TestValidateLibvirtProvider(t *testing.T) {
+  appSettings := getValidSettings()
+
+  appSettings.Providers["kvm"] = Provider{
+    ProviderType: "libvirt",
+    BoxName: "generic/ubuntu2404",
+    CpuMode: "host-passthrough",
+    ManagementNetworkAddress: "192.168.121.0/24",
+  }
+  assert.Empty(t, appSettings.Validate())
+
+  appSettings.Providers["kvm"] = Provider{
+    ProviderType: "libvirt",
+    BoxName: "generic/ubuntu2404",
+    CpuMode: "passthrough",
+    ManagementNetworkAddress: "192.168.121.0",
+  }
+
+  assert.Equal(t, []string{
+    "providers.kvm.cpuMode",
+    "providers.kvm.managementNetworkAddress",
+  }, validationPaths(appSettings.Validate()))
+}
+
+func TestValidateVirtualboxProvider(t *testing.T) {
+  appSettings := getValidSettings()
+
+  appSettings.Providers["vbox"] = Provider{
+    ProviderType: "virtualbox",
+    BoxName: "bento/ubuntu-24.04",
+    HostOnlyNetwork: "vboxnet0",
+    ParavirtProvider: "kvm",
+  }
+  assert.Empty(t, appSettings.Validate())
+
+  vbox := appSettings.Providers["vbox"]
+  vbox.ParavirtProvider = "xen"
+  appSettings.Providers["vbox"] = vbox
+
+  assert.Equal(t, []string{"providers.vbox.paravirtProvider"}, validationPaths(appSettings.Validate()))
+}
+
+/*
+      Tests for ValidateCluster
+*/
+func TestValidateClusterOnlyChecksCluster(t *testing.T) {
+  appSettings := getValidSettings()
+
+  prod := appSettings.Clusters["prod"]
+  prod.Leaders[0].Memory = 0
+  appSettings.Clusters["prod"] = prod
+
+  assert.Empty(t, appSettings.ValidateCluster("dev"))
+  assert.Equal(t, []string{"clusters.prod.leaders[0].memory"}, validationPaths(appSettings.ValidateCluster("prod")))
+}
+
+func TestValidateClusterMissing(t *testing.T) {
+  appSettings := getValidSettings()
+
+  validationErrors := appSettings.ValidateCluster("missing")
+
+  assert.Equal(t, []string{"clusters.missing"}, validationPaths(validationErrors))
+}
+
+func TestValidateWorkerPools(t *testing.T) {
+  settings := getValidSettings()
+  prod := settings.Clusters["prod"]
+  prod.WorkerPools = []WorkerPool{
+    {NamePrefix: "prod-gpu", Count: 2, StartIp: "192.168.1.40", Memory: 4096, Cpu: 2, DiskSize: "50GB",
+      Labels: map[string]string{"gpu": "true"}, Taints: []string{"gpu=true:NoSchedule"}},
+  }
+  settings.Clusters["prod"] = prod
+
+  assert.Empty(t, settings.Validate())
+
+  prod.WorkerPools = []WorkerPool{
+    {NamePrefix: "", Count: 0, StartIp: "bad"},
+  }
+  settings.Clusters["prod"] = prod
+
+  assert.ElementsMatch(t, []string{
+    "clusters.prod.workerPools[0].namePrefix",
+    "clusters.prod.workerPools[0].count",
+    "clusters.prod.workerPools[0].startIp",
+  }, validationPaths(settings.Validate()))
+
+  prod.WorkerPools = []WorkerPool{
+    {NamePrefix: "prod-gpu", Count: 1, StartIp: "192.168.1.40", Memory: 4096, Cpu: 2, DiskSize: "50GB",
+      Labels: map[string]string{"bad key!": "true"}, Taints: []string{"gpu=true:Sometimes"}},
+  }
+  settings.Clusters["prod"] = prod
+
+  assert.ElementsMatch(t, []string{
+    "clusters.prod.workers[1].labels",
+    "clusters.prod.workers[1].taints[0]",
+  }, validationPaths(settings.Validate()))
+}
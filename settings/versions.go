@@ -0,0 +1,213 @@
+package settings
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+)
+
+/*
+  The version compatibility catalog, the supported kubernetes
+  (k3s and rke2) versions and the component versions known to work with
+  each of them
+*/
+//go:embed catalog/versions.json
+var versionCatalogJson []byte
+
+/*
+  VersionCatalog - The supported kubernetes versions and
+  combinations of component versions that are known to be bad
+*/
+type VersionCatalog struct {
+  DefaultKubeVersion string                   `json:"defaultKubeVersion"`  // The kube version used when none is set
+  CiliumCliVersion string                     `json:"ciliumCliVersion"`    // The cilium cli version used when none is set
+  KubeVersions []KubeVersionCompatibility     `json:"kubeVersions"`        // The supported kube versions, newest first
+  KnownBad []KnownBadVersion                  `json:"knownBad"`            // Component versions that do not work
+}
+
+/*
+  KubeVersionCompatibility - The component versions that work
+  with a kubernetes version, the first version of each component
+  is the default
+*/
+type KubeVersionCompatibility struct {
+  KubeVersion string      `json:"kubeVersion"`   // The kubernetes version
+  K3sRelease string       `json:"k3sRelease"`    // The k3s release for the kubernetes version
+  Rke2Release string      `json:"rke2Release"`   // The rke2 release for the kubernetes version
+  Cilium []string         `json:"cilium"`        // cilium versions that work with the kube version
+  Calico []string         `json:"calico"`        // calico versions that work with the kube version
+  KubeVip []string        `json:"kubeVip"`       // kube-vip versions that work with the kube version
+  Longhorn []string       `json:"longhorn"`      // longhorn versions that work with the kube version
+}
+
+/*
+  KnownBadVersion - A component version that is known to not
+  work with some kubernetes versions, versions match exactly or
+  by prefix so 1.14 matches 1.14.2
+*/
+type KnownBadVersion struct {
+  Component string          `json:"component"`     // The component (cilium, calico, kubeVip or longhorn)
+  Version string            `json:"version"`       // The bad component version
+  KubeVersions []string     `json:"kubeVersions"`  // The kube versions it does not work with, empty is all
+  Reason string             `json:"reason"`        // Why the combination does not work
+}
+
+/*
+GetVersionCatalog()
+Gets the embedded version compatibility catalog
+*/
+func GetVersionCatalog() (VersionCatalog, error) {
+  var catalog VersionCatalog
+
+  err := json.Unmarshal(versionCatalogJson, &catalog)
+  if err != nil {
+    logger.LogError("Error unmarshaling version catalog")
+    return VersionCatalog{}, err
+  }
+  return catalog, nil
+}
+
+/*
+Gets the compatibility for a kube version, the bool is false
+if the exact kube version is not in the catalog
+*/
+func (catalog VersionCatalog) GetKubeVersion(kubeVersion string) (KubeVersionCompatibility, bool) {
+  for _, compatibility := range catalog.KubeVersions {
+    if compatibility.KubeVersion == kubeVersion {
+      return compatibility, true
+    }
+  }
+  return KubeVersionCompatibility{}, false
+}
+
+/*
+Gets the compatibility of the newest patch release of the same
+minor version as a kube version, the bool is false if there is
+no release of the minor version in the catalog. This is only
+close enough to pick component defaults, the releases in it are
+not releases of the kube version
+*/
+func (catalog VersionCatalog) GetKubeMinorVersion(kubeVersion string) (KubeVersionCompatibility, bool) {
+  for _, compatibility := range catalog.KubeVersions {
+    if minorVersion(compatibility.KubeVersion) == minorVersion(kubeVersion) {
+      return compatibility, true
+    }
+  }
+  return KubeVersionCompatibility{}, false
+}
+
+/*
+Gets the versions of a component that work with the
+kube version, the component is cilium, calico, kubeVip
+or longhorn
+*/
+func (compatibility KubeVersionCompatibility) GetComponentVersions(component string) []string {
+  switch component {
+  case "cilium":
+    return compatibility.Cilium
+  case "calico":
+    return compatibility.Calico
+  case "kubeVip":
+    return compatibility.KubeVip
+  case "longhorn":
+    return compatibility.Longhorn
+  }
+  return nil
+}
+
+/*
+Checks if a component version is known to not work with a kube
+version, the reason is returned if it is known bad
+*/
+func (catalog VersionCatalog) IsKnownBad(kubeVersion string, component string, version string) (string, bool) {
+  for _, knownBad := range catalog.KnownBad {
+    if knownBad.Component != component || !versionMatches(knownBad.Version, version) {
+      continue
+    }
+
+    if len(knownBad.KubeVersions) == 0 {
+      return knownBad.Reason, true
+    }
+
+    for _, badKubeVersion := range knownBad.KubeVersions {
+      if versionMatches(badKubeVersion, kubeVersion) {
+        return knownBad.Reason, true
+      }
+    }
+  }
+  return "", false
+}
+
+/*
+CheckFeatureVersions()
+Checks the component versions used by cluster features against
+the catalog. Known bad combinations are an error, versions that
+are not in the catalog for the kube version are a warning
+*/
+func (catalog VersionCatalog) CheckFeatureVersions(features ClusterFeatures) error {
+  var badVersions []string
+
+  compatibility, known := catalog.GetKubeVersion(features.KubeVersion)
+  if !known {
+    logger.LogWarn("Kube version is not in the version catalog, component versions can't be checked",
+      "kubeVersion", features.KubeVersion)
+  }
+
+  componentVersions := features.getComponentVersions()
+  for _, component := range sortedKeys(componentVersions) {
+    version := componentVersions[component]
+    if reason, bad := catalog.IsKnownBad(features.KubeVersion, component, version); bad {
+      logger.LogError("Error component version does not work with the kube version",
+        "component", component, "version", version, "kubeVersion", features.KubeVersion)
+      badVersions = append(badVersions, fmt.Sprintf("%s %s (%s)", component, version, reason))
+      continue
+    }
+
+    if known && !contains(compatibility.GetComponentVersions(component), version) {
+      logger.LogWarn("Component version is not known to work with the kube version",
+        "component", component, "version", version, "kubeVersion", features.KubeVersion,
+        "supported", strings.Join(compatibility.GetComponentVersions(component), ", "))
+    }
+  }
+
+  if len(badVersions) > 0 {
+    return fmt.Errorf("component versions do not work with kube version %s: %s",
+      features.KubeVersion, strings.Join(badVersions, ", "))
+  }
+  return nil
+}
+
+// gets the versions of the components the features use, component -> version
+func (features ClusterFeatures) getComponentVersions() map[string]string {
+  versions := make(map[string]string)
+
+  if (features.CniController == "cilium" || features.CniController == "calico") && features.CniControllerVersion != "" {
+    versions[features.CniController] = features.CniControllerVersion
+  }
+
+  if features.KubeVipEnable && features.KubeVipVersion != "" {
+    versions["kubeVip"] = features.KubeVipVersion
+  }
+
+  if features.StorageController == "longhorn" && features.StorageControllerVersion != "" {
+    versions["longhorn"] = features.StorageControllerVersion
+  }
+  return versions
+}
+
+// checks if a version is a pattern or a release of it ex 1.14 matches 1.14.2
+func versionMatches(pattern string, version string) bool {
+  return version == pattern || strings.HasPrefix(version, pattern + ".")
+}
+
+// gets the major.minor part of a version
+func minorVersion(version string) string {
+  parts := strings.Split(version, ".")
+  if len(parts) < 2 {
+    return version
+  }
+  return parts[0] + "." + parts[1]
+}
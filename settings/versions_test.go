@@ -0,0 +1,117 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+      Tests for GetVersionCatalog
+*/
+func TestGetVersionCatalog(t *testing.T) {
+  catalog, err := GetVersionCatalog()
+  assert.NoError(t, err)
+
+  // the default kube version must be in the catalog
+  _, known := catalog.GetKubeVersion(catalog.DefaultKubeVersion)
+  assert.True(t, known)
+
+  // every kube version has a default for every component
+  for _, compatibility := range catalog.KubeVersions {
+    for _, component := range []string{"cilium", "calico", "kubeVip", "longhorn"} {
+      assert.NotEmpty(t, compatibility.GetComponentVersions(component), compatibility.KubeVersion + " " + component)
+    }
+  }
+}
+
+/*
+      Tests for GetKubeVersion
+*/
+func TestGetKubeVersion(t *testing.T) {
+  catalog, err := GetVersionCatalog()
+  assert.NoError(t, err)
+
+  compatibility, known := catalog.GetKubeVersion("1.30.8")
+  assert.True(t, known)
+  assert.Equal(t, "1.30.8", compatibility.KubeVersion)
+
+  // other patch releases are not known, their releases are different
+  _, known = catalog.GetKubeVersion("1.30.2")
+  assert.False(t, known)
+
+  _, known = catalog.GetKubeVersion("1.20.0")
+  assert.False(t, known)
+}
+
+/*
+      Tests for GetKubeMinorVersion
+*/
+func TestGetKubeMinorVersion(t *testing.T) {
+  catalog, err := GetVersionCatalog()
+  assert.NoError(t, err)
+
+  compatibility, known := catalog.GetKubeMinorVersion("1.30.2")
+  assert.True(t, known)
+  assert.Equal(t, "1.30.8", compatibility.KubeVersion)
+
+  _, known = catalog.GetKubeMinorVersion("1.20.0")
+  assert.False(t, known)
+}
+
+/*
+      Tests for IsKnownBad
+*/
+func TestIsKnownBad(t *testing.T) {
+  catalog := VersionCatalog{
+    KnownBad: []KnownBadVersion{
+      {Component: "calico", Version: "3.25", KubeVersions: []string{"1.31"}, Reason: "too old"},
+      {Component: "longhorn", Version: "1.5.0", Reason: "broken"},
+    },
+  }
+
+  reason, bad := catalog.IsKnownBad("1.31.4", "calico", "3.25.0")
+  assert.True(t, bad)
+  assert.Equal(t, "too old", reason)
+
+  _, bad = catalog.IsKnownBad("1.30.8", "calico", "3.25.0")
+  assert.False(t, bad)
+
+  _, bad = catalog.IsKnownBad("1.31.4", "calico", "3.250.0")
+  assert.False(t, bad)
+
+  _, bad = catalog.IsKnownBad("1.29.12", "longhorn", "1.5.0")
+  assert.True(t, bad)
+}
+
+/*
+      Tests for CheckFeatureVersions
+*/
+func TestCheckFeatureVersions(t *testing.T) {
+  catalog, err := GetVersionCatalog()
+  assert.NoError(t, err)
+
+  features := ClusterFeatures{
+    KubeVersion: "1.31.4",
+    CniController: "calico",
+    CniControllerVersion: "3.28.2",
+    StorageController: "longhorn",
+    StorageControllerVersion: "1.7.2",
+  }
+  assert.NoError(t, catalog.CheckFeatureVersions(features))
+
+  // versions not in the catalog are only a warning
+  features.StorageControllerVersion = "1.7.0"
+  assert.NoError(t, catalog.CheckFeatureVersions(features))
+
+  features.CniControllerVersion = "3.25.0"
+  assert.ErrorContains(t, catalog.CheckFeatureVersions(features), "calico 3.25.0")
+}
+
+func TestCheckFeatureVersionsUnknownKubeVersion(t *testing.T) {
+  catalog, err := GetVersionCatalog()
+  assert.NoError(t, err)
+
+  features := ClusterFeatures{KubeVersion: "1.20.0", CniController: "cilium", CniControllerVersion: "1.10.0"}
+  assert.NoError(t, catalog.CheckFeatureVersions(features))
+}
@@ -1,5 +1,12 @@
 #!/usr/bin/env bash
 
+# use yq from the offline bundle if there is one
+if [[ -f /bundle/yq/yq ]]; then
+  echo "installing yq from the offline bundle"
+  sudo install -m 0755 /bundle/yq/yq /usr/local/bin/yq
+  exit 0
+fi
+
 echo "Installing yq ppa repo"
 sudo add-apt-repository -y ppa:rmescandon/yq
 
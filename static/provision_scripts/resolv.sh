@@ -1,6 +1,13 @@
 #!/usr/bin/env bash
 
+# set the nameservers from the cluster settings, this runs before
+# yq is installed so the flat dns-servers setting is read with sed
+dns_servers=$(sed -n 's/^dns-servers: //p' /vagrant/settings/settings.yaml | tr -d "\"'")
+if [[ -z "${dns_servers}" ]]; then
+  dns_servers="8.8.8.8 8.8.4.4"
+fi
+
 echo "[Resolve]" > /etc/systemd/resolved.conf
-echo "DNS=8.8.8.8 8.8.4.4" >> /etc/systemd/resolved.conf
+echo "DNS=${dns_servers}" >> /etc/systemd/resolved.conf
 
 sudo systemctl restart systemd-resolved
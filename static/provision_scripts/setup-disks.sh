@@ -0,0 +1,72 @@
+#!/usr/bin/env bash
+
+# formats and mounts the data disks of this machine from the
+# settings file, disks are formatted once with their name as the
+# ext4 label so running this again only makes sure they are mounted
+
+SETTINGS_FILE="/vagrant/settings/settings.yaml"
+
+machine_name="$(hostname)"
+
+# get the data disks of this machine into an array
+readarray disks < <(yq e -o=j -I=0 "(.machine_settings, .lead-control-node[], .control-nodes[], .workers[]) | select(.name == \"${machine_name}\") | .disks[]" $SETTINGS_FILE)
+
+if [[ ${#disks[@]} -eq 0 ]]; then
+  echo "No data disks for ${machine_name}"
+  exit 0
+fi
+
+# whole disks with no partitions or filesystem in attach order (the
+# primary disk is partitioned), these are matched to the data disks
+# that are not formatted yet
+readarray -t unused < <(lsblk -dnpo NAME,TYPE | awk '$2 == "disk" {print $1}' | sort -V | while read -r device; do
+  if [[ -z "$(blkid -o value -s TYPE "${device}")" ]] && [[ $(lsblk -nro NAME "${device}" | wc -l) -eq 1 ]]; then
+    echo "${device}"
+  fi
+done)
+
+next_unused=0
+
+for disk in "${disks[@]}"; do
+  name=$(echo "$disk" | yq e '.name' -)
+  mount_point=$(echo "$disk" | yq e '.mount_point // ""' -)
+  raw=$(echo "$disk" | yq e '.raw // false' -)
+
+  # disks with a serial (libvirt) can be found by name
+  device="/dev/disk/by-id/virtio-${name}"
+
+  if [[ -e "/dev/disk/by-label/${name}" ]]; then
+    device="/dev/disk/by-label/${name}"
+  elif [[ ! -e "${device}" ]]; then
+    if [[ ${next_unused} -ge ${#unused[@]} ]]; then
+      echo "No unused device found for disk ${name}"
+      exit 1
+    fi
+    device=${unused[$next_unused]}
+    next_unused=$((next_unused + 1))
+  fi
+
+  if [[ "${raw}" == "true" ]]; then
+    echo "Leaving disk ${name} (${device}) as a raw block device"
+    continue
+  fi
+
+  if [[ -z "$(blkid -o value -s TYPE "${device}")" ]]; then
+    echo "Formatting disk ${name} (${device})"
+    mkfs.ext4 -q -L "${name}" "${device}"
+    udevadm settle
+  fi
+
+  mkdir -p "${mount_point}"
+
+  if ! grep -q "^LABEL=${name} " /etc/fstab; then
+    echo "LABEL=${name} ${mount_point} ext4 defaults,nofail 0 2" >> /etc/fstab
+  fi
+
+  if ! mountpoint -q "${mount_point}"; then
+    echo "Mounting disk ${name} at ${mount_point}"
+    mount "${mount_point}"
+  fi
+done
+
+exit 0
@@ -0,0 +1,24 @@
+#!/usr/bin/env bash
+
+# stages k3s from the offline bundle in the locations the k3s
+# airgap install uses, the install script is run with
+# INSTALL_K3S_SKIP_DOWNLOAD so nothing is downloaded
+K3S_RELEASE=$1
+
+BUNDLE_DIR="/bundle"
+K3S_DIR="${BUNDLE_DIR}/k3s/${K3S_RELEASE}"
+
+if [[ ! -d "${K3S_DIR}" ]]; then
+  echo "k3s ${K3S_RELEASE} is not in the offline bundle"
+  exit 1
+fi
+
+echo "Staging k3s ${K3S_RELEASE} from the offline bundle"
+sudo install -m 0755 "${K3S_DIR}/k3s" /usr/local/bin/k3s
+sudo install -m 0755 "${K3S_DIR}/install.sh" /usr/local/bin/k3s-install.sh
+
+echo "Staging k3s airgap images"
+sudo mkdir -p /var/lib/rancher/k3s/agent/images
+sudo cp "${K3S_DIR}"/k3s-airgap-images-*.tar.zst /var/lib/rancher/k3s/agent/images/
+
+exit 0
@@ -0,0 +1,71 @@
+#!/usr/bin/env bash
+
+# install the cluster ca certificates and set up the proxy for apt,
+# pip, containerd and k3s. This runs before yq is installed so the
+# flat settings are read with sed
+settings_file=/vagrant/settings/settings.yaml
+
+get_setting() {
+  sed -n "s/^$1: //p" "${settings_file}" | tr -d "\"'"
+}
+
+## ca certificates ##
+ca_certificates=$(get_setting ca-certificates)
+if [[ -n "${ca_certificates}" ]]; then
+  echo "Installing ca certificates"
+  sudo mkdir -p /usr/local/share/ca-certificates/local-kube
+  sudo rm -f /usr/local/share/ca-certificates/local-kube/*.crt
+
+  for certificate in ${ca_certificates}; do
+    sudo install -m 0644 "/vagrant/settings/ca-certificates/${certificate}" \
+      "/usr/local/share/ca-certificates/local-kube/${certificate}"
+  done
+  sudo update-ca-certificates
+fi
+
+## proxy ##
+http_proxy=$(get_setting http-proxy)
+https_proxy=$(get_setting https-proxy)
+no_proxy=$(get_setting no-proxy)
+
+if [[ -z "${http_proxy}" && -z "${https_proxy}" ]]; then
+  echo "No proxy settings for the cluster"
+  exit 0
+fi
+
+echo "Setting up the proxy"
+
+# login shells, sudo and the k3s install script read /etc/environment
+sudo sed -i '/^\(http_proxy\|https_proxy\|no_proxy\|HTTP_PROXY\|HTTPS_PROXY\|NO_PROXY\)=/d' /etc/environment
+sudo tee -a /etc/environment > /dev/null << EOF
+http_proxy="${http_proxy}"
+https_proxy="${https_proxy}"
+no_proxy="${no_proxy}"
+HTTP_PROXY="${http_proxy}"
+HTTPS_PROXY="${https_proxy}"
+NO_PROXY="${no_proxy}"
+EOF
+
+# apt
+sudo tee /etc/apt/apt.conf.d/95local-kube-proxy > /dev/null << EOF
+Acquire::http::Proxy "${http_proxy}";
+Acquire::https::Proxy "${https_proxy}";
+EOF
+
+# pip, pip has its own ca bundle so it is pointed at the system bundle
+sudo tee /etc/pip.conf > /dev/null << EOF
+[global]
+proxy = ${https_proxy}
+cert = /etc/ssl/certs/ca-certificates.crt
+EOF
+
+# every systemd service, this covers containerd and the k3s or rke2
+# services without knowing their names
+sudo mkdir -p /etc/systemd/system.conf.d
+sudo tee /etc/systemd/system.conf.d/local-kube-proxy.conf > /dev/null << EOF
+[Manager]
+DefaultEnvironment="HTTP_PROXY=${http_proxy}" "HTTPS_PROXY=${https_proxy}" "NO_PROXY=${no_proxy}"
+EOF
+sudo systemctl daemon-reexec
+
+exit 0
@@ -0,0 +1,24 @@
+#!/usr/bin/env bash
+
+# install the registries file and registry certs if the cluster
+# has registry settings, k3s and rke2 read these on start from
+# their config directory
+
+# Args
+config_dir=${1:-/etc/rancher/k3s}
+
+if [[ ! -f /vagrant/settings/registries.yaml ]]; then
+  echo "No registry settings for the cluster"
+  exit 0
+fi
+
+sudo mkdir -p "${config_dir}"
+sudo install -m 0600 /vagrant/settings/registries.yaml "${config_dir}/registries.yaml"
+
+if [[ -d /vagrant/settings/registry-certs ]]; then
+  sudo rm -rf "${config_dir}/registry-certs"
+  sudo cp -r /vagrant/settings/registry-certs "${config_dir}/registry-certs"
+  sudo chmod -R go-rwx "${config_dir}/registry-certs"
+fi
+
+exit 0
@@ -2,7 +2,11 @@
 exec > /vagrant/logs/provision.txt 2>&1
 
 # Args
+# the kubeconfig and manifests paths come from the cluster distribution
 output_type=$1
+kubeconfig_path=${2:-/etc/rancher/k3s/k3s.yaml}
+manifests_dir=${3:-/var/lib/rancher/k3s/server/manifests}
+kubeconfig_file=$(basename "${kubeconfig_path}")
 
 ## copy ansible hosts file ##
 echo "Copying ansible hosts file"
@@ -18,7 +22,7 @@ else
   /usr/local/bin/ansible-playbook /etc/ansible/playbook/lead-node-playbook.yml
 fi
 
-echo "sleeping to let k3s start fully before provisioning other nodes"
+echo "sleeping to let the lead node start fully before provisioning other nodes"
 sleep 30
 
 if [[ "${output_type}" == "debug" ]]; then
@@ -37,6 +41,14 @@ else
   /usr/local/bin/ansible-playbook /etc/ansible/playbook/worker-playbook.yml
 fi
 
+## Install addons ##
+# k3s and rke2 install any HelmChart manifests in their manifests directory
+if compgen -G "/vagrant/addons/*.yaml" > /dev/null; then
+  echo "Installing addon manifests"
+  sudo mkdir -p "${manifests_dir}"
+  sudo cp /vagrant/addons/*.yaml "${manifests_dir}/"
+fi
+
 ## Copy Kubeconfig ##
-cp /etc/rancher/k3s/k3s.yaml /vagrant/kubeconfig/k3s.yaml
-chmod 777 /vagrant/kubeconfig/k3s.yaml
+sudo cp "${kubeconfig_path}" "/vagrant/kubeconfig/${kubeconfig_file}"
+sudo chmod 777 "/vagrant/kubeconfig/${kubeconfig_file}"
@@ -28,6 +28,10 @@ func TestListProvisionScripts(t *testing.T) {
   assert.Contains(t, fileNames, "install-yq.sh")
   assert.Contains(t, fileNames, "disk-expand.sh")
   assert.Contains(t, fileNames, "setup-hostsfile.sh")
+  assert.Contains(t, fileNames, "setup-registries.sh")
+  assert.Contains(t, fileNames, "setup-offline.sh")
+  assert.Contains(t, fileNames, "setup-disks.sh")
+  assert.Contains(t, fileNames, "setup-proxy.sh")
 }
 
 /*
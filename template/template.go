@@ -3,7 +3,10 @@ package template
 import (
 	"bytes"
 	"embed"
+	"fmt"
 	"io/fs"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/dgutierrez1287/local-kube/logger"
@@ -15,6 +18,58 @@ var provisionTemplatesFS  embed.FS
 //go:embed vagrantfiles/*
 var vagrantfileTemplatesFS embed.FS
 
+/*
+  Functions that can be used in vagrantfile templates
+*/
+var vagrantfileFuncs = template.FuncMap{
+  "diskSizeGb": diskSizeGb,
+  "rubyList": rubyList,
+  "rubyString": rubyString,
+}
+
+/*
+renders a string as a ruby double quoted string, # is
+escaped so #{}, #$ and #@ are not interpolated by ruby
+*/
+func rubyString(value string) string {
+  return strings.ReplaceAll(strconv.Quote(value), "#", `\#`)
+}
+
+/*
+renders a list of strings as a ruby array of
+double quoted strings ex [".git/", "tmp/"]
+*/
+func rubyList(values []string) string {
+  quoted := []string{}
+
+  for _, value := range values {
+    quoted = append(quoted, rubyString(value))
+  }
+  return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+/*
+converts a disk size like 50GB to a whole number of gigabytes,
+sizes in MB are rounded up. Some providers (libvirt) only take
+a number of gigabytes
+*/
+func diskSizeGb(diskSize string) (int, error) {
+  units := map[string]int{"MB": 1, "GB": 1024, "TB": 1024 * 1024}
+
+  for unit, megabytes := range units {
+    if !strings.HasSuffix(diskSize, unit) {
+      continue
+    }
+
+    size, err := strconv.Atoi(strings.TrimSuffix(diskSize, unit))
+    if err != nil {
+      return 0, fmt.Errorf("malformed disk size %q", diskSize)
+    }
+    return (size * megabytes + 1023) / 1024, nil
+  }
+  return 0, fmt.Errorf("malformed disk size %q", diskSize)
+}
+
 func GetProvisionFS() fs.FS {
   return provisionTemplatesFS
 }
@@ -47,8 +102,13 @@ func RenderProvisionTemplate(name string, data interface{}) (string, error) {
   return result.String(), nil
 }
 
-func RenderVagrantfileTemplate(providerType string, clusterType string, data interface{}) (string, error) {
-  templateName := "vagrantfiles/" + providerType + "-" + clusterType + ".tmpl"
+/*
+Renders a vagrantfile template, the name is the template file
+name without the extension ex libvirt-ha. Provider types map
+to their templates in the settings provider registry
+*/
+func RenderVagrantfileTemplate(name string, data interface{}) (string, error) {
+  templateName := "vagrantfiles/" + name + ".tmpl"
 
   content, err := vagrantfileTemplatesFS.ReadFile(templateName)
   if err != nil {
@@ -56,7 +116,7 @@ func RenderVagrantfileTemplate(providerType string, clusterType string, data int
     return "", err
   }
 
-  tmpl, err := template.New(templateName).Parse(string(content))
+  tmpl, err := template.New(templateName).Funcs(vagrantfileFuncs).Parse(string(content))
   if err != nil {
     logger.LogError("Error parsing vagrantfile template", "template", templateName)
     return "", err
@@ -1 +1,405 @@
 package template
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dgutierrez1287/local-kube/logger"
+	"github.com/dgutierrez1287/local-kube/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain is executed before running any tests
+func TestMain(m *testing.M) {
+	// Initialize the logger before running any tests
+	logger.InitLogging(false, true, false)
+	os.Exit(m.Run())
+}
+
+// regenerate the golden files with go test ./template -update
+var update = flag.Bool("update", false, "update the golden files")
+
+func getTestProviders() map[string]settings.Provider {
+  return map[string]settings.Provider{
+    "vmware-desktop": {
+      ProviderType: "vmware-desktop",
+      BoxName: "bento/ubuntu-24.04",
+      VmNet: "vmnet2",
+    },
+    "libvirt": {
+      ProviderType: "libvirt",
+      BoxName: "generic/ubuntu2404",
+      StoragePool: "kube",
+      NetworkName: "local-kube",
+      ManagementNetworkName: "local-kube-mgmt",
+      ManagementNetworkAddress: "192.168.121.0/24",
+      CpuMode: "host-passthrough",
+      NestedVirtualization: true,
+    },
+    "virtualbox": {
+      ProviderType: "virtualbox",
+      BoxName: "bento/ubuntu-24.04",
+      HostOnlyNetwork: "vboxnet0",
+      LinkedClone: true,
+      ParavirtProvider: "kvm",
+    },
+  }
+}
+
+// builds the template data the same way cluster.RenderVagrantFile does
+func getTestTemplateData(provider settings.Provider, clusterType string) map[string]interface{} {
+  data := make(map[string]interface{})
+  data["Provider"] = provider
+  data["Distribution"], _ = settings.GetDistributionDefinition("k3s")
+
+  if clusterType == "ha" {
+    data["LeadControlNode"] = []settings.Machine{
+      {Name: "leader01", IpAddress: "192.168.10.10", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+    }
+    data["ControlNodes"] = []settings.Machine{
+      {Name: "leader02", IpAddress: "192.168.10.11", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+      {Name: "leader03", IpAddress: "192.168.10.12", Memory: 4096, Cpu: 2, DiskSize: "50GB"},
+    }
+    data["WorkerNodes"] = []settings.Machine{
+      {Name: "worker01", IpAddress: "192.168.10.20", Memory: 8192, Cpu: 4, DiskSize: "100GB"},
+    }
+  } else {
+    data["Node"] = settings.Machine{Name: "dev", IpAddress: "192.168.10.10", Memory: 4096, Cpu: 2, DiskSize: "50GB"}
+  }
+  return data
+}
+
+/*
+      Tests for RenderVagrantfileTemplate
+*/
+func TestRenderVagrantfileTemplateGolden(t *testing.T) {
+  providers := getTestProviders()
+
+  // every template in the provider registry should have a golden file
+  for _, definition := range settings.GetProviderDefinitions() {
+    provider, exists := providers[definition.ProviderType]
+    assert.True(t, exists, definition.ProviderType)
+
+    for _, clusterType := range definition.GetClusterTypes() {
+      name, err := definition.GetTemplateName(clusterType)
+      assert.NoError(t, err)
+
+      t.Run(name, func(t *testing.T) {
+        rendered, err := RenderVagrantfileTemplate(name, getTestTemplateData(provider, clusterType))
+        assert.NoError(t, err)
+
+        goldenFile := filepath.Join("testdata", name + ".golden")
+        if *update {
+          assert.NoError(t, os.WriteFile(goldenFile, []byte(rendered), 0644))
+        }
+
+        golden, err := os.ReadFile(goldenFile)
+        assert.NoError(t, err)
+        assert.Equal(t, string(golden), rendered)
+      })
+    }
+  }
+}
+
+func TestRenderVagrantfileTemplateLibvirtMinimal(t *testing.T) {
+  provider := settings.Provider{ProviderType: "libvirt", BoxName: "generic/ubuntu2404"}
+
+  rendered, err := RenderVagrantfileTemplate("libvirt-single", getTestTemplateData(provider, "single"))
+  assert.NoError(t, err)
+
+  assert.Contains(t, rendered, `config.vm.network "private_network", ip: "192.168.10.10"` + "\n")
+  assert.Contains(t, rendered, "lv.machine_virtual_size = 50")
+  assert.NotContains(t, rendered, "lv.cpu_mode")
+  assert.NotContains(t, rendered, "lv.nested")
+  assert.NotContains(t, rendered, "lv.storage_pool_name")
+}
+
+func TestRenderVagrantfileTemplateVirtualboxMinimal(t *testing.T) {
+  provider := settings.Provider{ProviderType: "virtualbox", BoxName: "bento/ubuntu-24.04"}
+
+  rendered, err := RenderVagrantfileTemplate("virtualbox-ha", getTestTemplateData(provider, "ha"))
+  assert.NoError(t, err)
+
+  assert.Contains(t, rendered, `wn.vm.network "private_network", ip: "192.168.10.20"` + "\n")
+  assert.NotContains(t, rendered, "vb.linked_clone")
+  assert.NotContains(t, rendered, "--paravirtprovider")
+}
+
+func TestRenderVagrantfileTemplateVmwareLinkedClone(t *testing.T) {
+  provider := getTestProviders()["vmware-desktop"]
+  provider.LinkedClone = true
+
+  rendered, err := RenderVagrantfileTemplate("vmware-desktop-single", getTestTemplateData(provider, "single"))
+  assert.NoError(t, err)
+  assert.Contains(t, rendered, "v.linked_clone = true\n")
+
+  rendered, err = RenderVagrantfileTemplate("vmware-desktop-ha", getTestTemplateData(provider, "ha"))
+  assert.NoError(t, err)
+  assert.Equal(t, 4, strings.Count(rendered, "v.linked_clone = true\n"))
+}
+
+func TestRenderVagrantfileTemplateOffline(t *testing.T) {
+  providers := getTestProviders()
+
+  for _, definition := range settings.GetProviderDefinitions() {
+    for _, clusterType := range definition.GetClusterTypes() {
+      name, err := definition.GetTemplateName(clusterType)
+      assert.NoError(t, err)
+
+      data := getTestTemplateData(providers[definition.ProviderType], clusterType)
+      data["Offline"] = map[string]interface{}{
+        "BundleDir": "/home/user/.local-kube/cache/bundle",
+        "K3sRelease": "v1.31.4+k3s1",
+      }
+
+      rendered, err := RenderVagrantfileTemplate(name, data)
+      assert.NoError(t, err)
+
+      // every machine gets the bundle and stages k3s from it
+      machines := 1
+      if clusterType == "ha" {
+        machines = 4
+      }
+      assert.Equal(t, machines, strings.Count(rendered, `synced_folder "/home/user/.local-kube/cache/bundle", "/bundle"`), name)
+      assert.Equal(t, machines, strings.Count(rendered, "bash /provision/setup-offline.sh v1.31.4+k3s1"), name)
+      assert.Contains(t, rendered, "config.vm.box_check_update = false", name)
+      assert.NotContains(t, rendered, "apt update", name)
+    }
+  }
+}
+
+func TestRenderVagrantfileTemplateDisks(t *testing.T) {
+  providers := getTestProviders()
+  disks := []settings.Disk{
+    {Name: "longhorn", Size: "200GB", MountPoint: "/var/lib/longhorn"},
+    {Name: "block", Size: "512MB", Raw: true},
+  }
+
+  expected := map[string][]string{
+    "vmware-desktop": {`.vm.disk :disk, name: "longhorn", size: "200GB"` + "\n", `.vm.disk :disk, name: "block", size: "512MB"` + "\n"},
+    "virtualbox": {`.vm.disk :disk, name: "longhorn", size: "200GB"` + "\n", `.vm.disk :disk, name: "block", size: "512MB"` + "\n"},
+    "libvirt": {`lv.storage :file, size: "200G", serial: "longhorn"` + "\n", `lv.storage :file, size: "1G", serial: "block"` + "\n"},
+  }
+
+  for _, definition := range settings.GetProviderDefinitions() {
+    for _, clusterType := range definition.GetClusterTypes() {
+      name, err := definition.GetTemplateName(clusterType)
+      assert.NoError(t, err)
+
+      data := getTestTemplateData(providers[definition.ProviderType], clusterType)
+      if clusterType == "ha" {
+        data["WorkerNodes"].([]settings.Machine)[0].Disks = disks
+      } else {
+        node := data["Node"].(settings.Machine)
+        node.Disks = disks
+        data["Node"] = node
+      }
+
+      rendered, err := RenderVagrantfileTemplate(name, data)
+      assert.NoError(t, err)
+
+      // only the machine with disks gets them attached
+      for _, attach := range expected[definition.ProviderType] {
+        assert.Equal(t, 1, strings.Count(rendered, attach), name)
+      }
+    }
+  }
+}
+
+func TestRenderVagrantfileTemplateNetworks(t *testing.T) {
+  providers := getTestProviders()
+  networks := []settings.Network{
+    {Name: "storage", IpAddress: "192.168.20.20", Netmask: "255.255.0.0", ProviderNetwork: "storage-net"},
+    {Name: "cluster"},
+    {Name: "lan", Type: "bridged", Bridge: "en0"},
+  }
+
+  expected := map[string][]string{
+    "vmware-desktop": {
+      `.vm.network "private_network", ip: "192.168.20.20", netmask: "255.255.0.0", vmware_desktop__vmnet: "storage-net"` + "\n",
+      `.vm.network "private_network", type: "dhcp"` + "\n",
+      `.vm.network "public_network", bridge: "en0"` + "\n",
+    },
+    "virtualbox": {
+      `.vm.network "private_network", ip: "192.168.20.20", netmask: "255.255.0.0", name: "storage-net"` + "\n",
+      `.vm.network "private_network", type: "dhcp"` + "\n",
+      `.vm.network "public_network", bridge: "en0"` + "\n",
+    },
+    "libvirt": {
+      `.vm.network "private_network", ip: "192.168.20.20", netmask: "255.255.0.0", libvirt__network_name: "storage-net"` + "\n",
+      `.vm.network "private_network", type: "dhcp"` + "\n",
+      `.vm.network "public_network", dev: "en0", mode: "bridge", type: "bridge"` + "\n",
+    },
+  }
+
+  for _, definition := range settings.GetProviderDefinitions() {
+    for _, clusterType := range definition.GetClusterTypes() {
+      name, err := definition.GetTemplateName(clusterType)
+      assert.NoError(t, err)
+
+      data := getTestTemplateData(providers[definition.ProviderType], clusterType)
+      if clusterType == "ha" {
+        data["WorkerNodes"].([]settings.Machine)[0].Networks = networks
+      } else {
+        node := data["Node"].(settings.Machine)
+        node.Networks = networks
+        data["Node"] = node
+      }
+
+      rendered, err := RenderVagrantfileTemplate(name, data)
+      assert.NoError(t, err)
+
+      // only the machine with networks gets them
+      for _, network := range expected[definition.ProviderType] {
+        assert.Equal(t, 1, strings.Count(rendered, network), name)
+      }
+    }
+  }
+}
+
+func TestRenderVagrantfileTemplateForwardedPorts(t *testing.T) {
+  providers := getTestProviders()
+  ports := []settings.ForwardedPort{
+    {Guest: 6443, Host: 16443},
+    {Guest: 53, Host: 5353, Protocol: "udp", HostIp: "0.0.0.0"},
+  }
+
+  for _, definition := range settings.GetProviderDefinitions() {
+    for _, clusterType := range definition.GetClusterTypes() {
+      name, err := definition.GetTemplateName(clusterType)
+      assert.NoError(t, err)
+
+      data := getTestTemplateData(providers[definition.ProviderType], clusterType)
+      if clusterType == "ha" {
+        data["LeadControlNode"].([]settings.Machine)[0].ForwardedPorts = ports
+      } else {
+        node := data["Node"].(settings.Machine)
+        node.ForwardedPorts = ports
+        data["Node"] = node
+      }
+
+      rendered, err := RenderVagrantfileTemplate(name, data)
+      assert.NoError(t, err)
+
+      assert.Equal(t, 1, strings.Count(rendered,
+        `.vm.network "forwarded_port", guest: 6443, host: 16443, host_ip: "127.0.0.1", protocol: "tcp"` + "\n"), name)
+      assert.Equal(t, 1, strings.Count(rendered,
+        `.vm.network "forwarded_port", guest: 53, host: 5353, host_ip: "0.0.0.0", protocol: "udp"` + "\n"), name)
+    }
+  }
+}
+
+func TestRenderVagrantfileTemplateSyncedFolders(t *testing.T) {
+  providers := getTestProviders()
+  folders := []settings.SyncedFolder{
+    {HostPath: "/home/user/src/app", GuestPath: "/src/app"},
+    {HostPath: "/home/user/src/api", GuestPath: "/src/api", Type: "rsync", RsyncExcludes: []string{".git/", "node_modules/"}},
+    {HostPath: "/home/user/src/web", GuestPath: "/src/web", Type: "nfs", NfsVersion: 3, MountOptions: []string{"actimeo=1"}},
+  }
+
+  // the default folder type is the provider default, nfs for libvirt
+  defaultOptions := map[string]string{
+    "vmware-desktop": "disabled: false\n",
+    "virtualbox": "disabled: false\n",
+    "libvirt": `type: "nfs", nfs_version: 4, nfs_udp: false` + "\n",
+  }
+
+  for _, definition := range settings.GetProviderDefinitions() {
+    for _, clusterType := range definition.GetClusterTypes() {
+      name, err := definition.GetTemplateName(clusterType)
+      assert.NoError(t, err)
+
+      data := getTestTemplateData(providers[definition.ProviderType], clusterType)
+      data["SyncedFolders"] = folders
+
+      rendered, err := RenderVagrantfileTemplate(name, data)
+      assert.NoError(t, err)
+
+      // every machine gets every folder
+      machines := 1
+      if clusterType == "ha" {
+        machines = 4
+      }
+
+      folderLines := []string{
+        `.vm.synced_folder "/home/user/src/app", "/src/app",` + "\n",
+        `.vm.synced_folder "/home/user/src/api", "/src/api",` + "\n",
+        `type: "rsync", rsync__exclude: [".git/", "node_modules/"]` + "\n",
+        `.vm.synced_folder "/home/user/src/web", "/src/web",` + "\n",
+        `type: "nfs", nfs_version: 3, nfs_udp: false, mount_options: ["actimeo=1"]` + "\n",
+      }
+
+      for _, folderLine := range folderLines {
+        assert.Equal(t, machines, strings.Count(rendered, folderLine), name)
+      }
+      assert.Regexp(t, `"/src/app",\n\s+` + regexp.QuoteMeta(defaultOptions[definition.ProviderType]), rendered, name)
+    }
+  }
+}
+
+func TestRenderVagrantfileTemplateSyncedFolderQuoting(t *testing.T) {
+  provider := getTestProviders()["virtualbox"]
+  data := getTestTemplateData(provider, "single")
+  data["SyncedFolders"] = []settings.SyncedFolder{
+    {HostPath: `/home/user/src/"app"`, GuestPath: "/src/#{app}"},
+  }
+
+  rendered, err := RenderVagrantfileTemplate("virtualbox-single", data)
+  assert.NoError(t, err)
+  assert.Contains(t, rendered, `config.vm.synced_folder "/home/user/src/\"app\"", "/src/\#{app}",` + "\n")
+}
+
+func TestRenderVagrantfileTemplateUnknownTemplate(t *testing.T) {
+  _, err := RenderVagrantfileTemplate("hyperv-single", getTestTemplateData(settings.Provider{}, "single"))
+  assert.Error(t, err)
+}
+
+/*
+      Tests for diskSizeGb
+*/
+func TestDiskSizeGb(t *testing.T) {
+  sizes := map[string]int{
+    "50GB": 50,
+    "1TB": 1024,
+    "512MB": 1,
+    "2048MB": 2,
+  }
+
+  for diskSize, expected := range sizes {
+    size, err := diskSizeGb(diskSize)
+    assert.NoError(t, err)
+    assert.Equal(t, expected, size, diskSize)
+  }
+}
+
+func TestDiskSizeGbMalformed(t *testing.T) {
+  _, err := diskSizeGb("fifty")
+  assert.Error(t, err)
+
+  _, err = diskSizeGb("xGB")
+  assert.Error(t, err)
+}
+
+/*
+      Tests for rubyList
+*/
+func TestRubyList(t *testing.T) {
+  assert.Equal(t, `[".git/", "tmp/"]`, rubyList([]string{".git/", "tmp/"}))
+  assert.Equal(t, `["say \"hi\""]`, rubyList([]string{`say "hi"`}))
+  assert.Equal(t, `[]`, rubyList([]string{}))
+  assert.Equal(t, `["\#{system(\"id\")}"]`, rubyList([]string{`#{system("id")}`}))
+}
+
+/*
+      Tests for rubyString
+*/
+func TestRubyString(t *testing.T) {
+  assert.Equal(t, `"/src/app"`, rubyString("/src/app"))
+  assert.Equal(t, `"/src/my \"app\""`, rubyString(`/src/my "app"`))
+  assert.Equal(t, `"/src/\#{ENV[\"HOME\"]}/\#$0/\#@app"`, rubyString(`/src/#{ENV["HOME"]}/#$0/#@app`))
+  assert.Equal(t, `"C:\\src"`, rubyString(`C:\src`))
+}
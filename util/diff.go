@@ -0,0 +1,59 @@
+package util
+
+import (
+  "strings"
+)
+
+/*
+  Gets a line by line diff of two strings, lines that were
+  removed start with -, lines that were added start with +
+  and unchanged lines start with a space
+*/
+func LineDiff(before string, after string) string {
+  beforeLines := strings.Split(strings.TrimSuffix(before, "\n"), "\n")
+  afterLines := strings.Split(strings.TrimSuffix(after, "\n"), "\n")
+
+  // longest common subsequence lengths
+  lcs := make([][]int, len(beforeLines)+1)
+  for i := range lcs {
+    lcs[i] = make([]int, len(afterLines)+1)
+  }
+
+  for i := len(beforeLines) - 1; i >= 0; i-- {
+    for j := len(afterLines) - 1; j >= 0; j-- {
+      if beforeLines[i] == afterLines[j] {
+        lcs[i][j] = lcs[i+1][j+1] + 1
+      } else if lcs[i+1][j] >= lcs[i][j+1] {
+        lcs[i][j] = lcs[i+1][j]
+      } else {
+        lcs[i][j] = lcs[i][j+1]
+      }
+    }
+  }
+
+  var diff []string
+  i, j := 0, 0
+
+  for i < len(beforeLines) && j < len(afterLines) {
+    if beforeLines[i] == afterLines[j] {
+      diff = append(diff, " " + beforeLines[i])
+      i++
+      j++
+    } else if lcs[i+1][j] >= lcs[i][j+1] {
+      diff = append(diff, "-" + beforeLines[i])
+      i++
+    } else {
+      diff = append(diff, "+" + afterLines[j])
+      j++
+    }
+  }
+
+  for ; i < len(beforeLines); i++ {
+    diff = append(diff, "-" + beforeLines[i])
+  }
+
+  for ; j < len(afterLines); j++ {
+    diff = append(diff, "+" + afterLines[j])
+  }
+  return strings.Join(diff, "\n")
+}